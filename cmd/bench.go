@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/bench"
+	"github.com/znscli/zns/internal/query"
+	"github.com/znscli/zns/internal/view"
+)
+
+// newBenchCommand creates the bench command, which compares the performance of DNS servers.
+func newBenchCommand() *cobra.Command {
+	var (
+		servers []string
+		domains string
+		qtypes  string
+		count   int
+		qps     int
+		output  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark and compare DNS servers",
+		Long:  "Benchmark DNS servers, by sending each of them the same queries for the names of a file, at a target number of queries per second. For each server, zns reports the percentiles of the round-trip times, the number of timeouts and errors, the distribution of response codes, and an estimate of how many repeated queries were answered from the cache of the server.",
+		Example: `
+  # Compare two public resolvers
+  zns bench --server 1.1.1.1 --server 8.8.8.8 --domains domains.txt
+
+  # Send 1000 queries for A and AAAA records at 200 queries per second
+  zns bench --server 1.1.1.1 --domains domains.txt -q A,AAAA --count 1000 --qps 200
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			vt, err := arguments.ParseViewType(output)
+			if err != nil {
+				return err
+			}
+
+			var types []uint16
+			for _, t := range strings.Split(qtypes, ",") {
+				qtype, ok := query.Type(strings.TrimSpace(t))
+				if !ok {
+					return fmt.Errorf("error: invalid query type: %s", t)
+				}
+				types = append(types, qtype)
+			}
+			if count <= 0 {
+				return fmt.Errorf("error: invalid number of queries: %d", count)
+			}
+			if qps <= 0 {
+				return fmt.Errorf("error: invalid number of queries per second: %d", qps)
+			}
+			if len(servers) == 0 {
+				return fmt.Errorf("error: the --server flag is required")
+			}
+
+			if domains == "" {
+				return fmt.Errorf("error: the --domains flag is required")
+			}
+			f, err := os.Open(domains)
+			if err != nil {
+				return fmt.Errorf("error: failed to read domains: %v", err)
+			}
+			names, err := bench.ReadDomains(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("error: failed to read domains: %v", err)
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("error: no domains found in %s", domains)
+			}
+
+			var questions []query.Question
+			for _, name := range names {
+				questions = append(questions, query.Questions(name, types)...)
+			}
+
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			w := view.NewTabWriter(out, debug)
+			v, err := view.NewBenchRenderer(vt, view.NewView(w))
+			if err != nil {
+				return err
+			}
+
+			logger := newLogger(logOut, logColor, false, "")
+			logger.Debug("Flags", "server", servers, "domains", domains, "qtype", qtypes, "count", count, "qps", qps, "debug", debug)
+
+			report := &bench.Report{}
+			for _, s := range servers {
+				querier := newServerQuerier(logger, s)
+				logger.Info("Benchmarking DNS server", "server", querier.Server, "count", count, "qps", qps)
+
+				result := bench.Run(querier, querier.Server, bench.Options{Questions: questions, Count: count, QPS: qps})
+				report.Results = append(report.Results, result)
+			}
+
+			if err := v.RenderBench(report); err != nil {
+				return fmt.Errorf("error: failed to write output: %v", err)
+			}
+			return w.Flush()
+		},
+	}
+
+	// The --server flag of the bench command may be repeated, and shadows the one of the root command.
+	cmd.Flags().StringArrayVarP(&servers, "server", "s", nil, "DNS server to benchmark, may be repeated")
+	cmd.Flags().StringVar(&domains, "domains", "", "File with the domain names to query, one per line")
+	cmd.Flags().StringVarP(&qtypes, "query-type", "q", "A", "DNS query types to ask for each name, comma-separated")
+	cmd.Flags().IntVar(&count, "count", 100, "Number of queries to send to each server")
+	cmd.Flags().IntVar(&qps, "qps", 50, "Number of queries to send per second")
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json)")
+
+	return cmd
+}
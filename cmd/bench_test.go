@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cmd_Bench(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	domains := writeWordlist(t, "example.net\nmail.wildcard.example.net\n")
+	address := fmt.Sprintf("127.0.0.1:%d", DNSServerPort)
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"bench", "--server", address, "--server", address, "--domains", domains, "-q", "A,MX", "--count", "8", "--qps", "1000", "--output-file", file.Name()})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Latencies vary from run to run, so only the counts are compared.
+	assert.Regexp(t, `^SERVER\s+SENT\s+ANSWERED\s+TIMEOUTS\s+ERRORS\s+QPS\s+MIN\s+P50\s+P90\s+P99\s+MAX\s+CACHE HITS\s+RCODES\n`, string(logFile))
+	row := regexp.MustCompile(regexp.QuoteMeta(address) + `\s+8\s+8\s+0\s+0\s+[\d.]+(\s+[\d.]+ms){5}\s+\d+% \(\d/4\)\s+NOERROR 8\n`)
+	assert.Len(t, row.FindAllString(string(logFile), -1), 2)
+}
+
+func Test_Cmd_Bench_Errors(t *testing.T) {
+	domains := writeWordlist(t, "example.net\n")
+	empty := writeWordlist(t, "# no domains\n")
+
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"no server":          {[]string{"bench", "--domains", domains}, "error: the --server flag is required"},
+		"no domains":         {[]string{"bench", "--server", "127.0.0.1"}, "error: the --domains flag is required"},
+		"empty file":         {[]string{"bench", "--server", "127.0.0.1", "--domains", empty}, "error: no domains found in " + empty},
+		"invalid type":       {[]string{"bench", "--server", "127.0.0.1", "--domains", domains, "-q", "FOO"}, "error: invalid query type: FOO"},
+		"invalid qps":        {[]string{"bench", "--server", "127.0.0.1", "--domains", domains, "--qps", "0"}, "error: invalid number of queries per second: 0"},
+		"invalid count":      {[]string{"bench", "--server", "127.0.0.1", "--domains", domains, "--count", "-1"}, "error: invalid number of queries: -1"},
+		"unsupported output": {[]string{"bench", "--server", "127.0.0.1", "--domains", domains, "-o", "yaml"}, "error: the yaml output is not supported by the bench command"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rootCmd := NewRootCommand()
+			rootCmd.SetArgs(tt.args)
+
+			err := rootCmd.Execute()
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func Test_Cmd_Bench_Config(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	// The servers given on the command line replace the server of the config file and of its profiles.
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server: 192.0.2.1\nprofile: test\nprofiles:\n  test:\n    server: 192.0.2.2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	domains := writeWordlist(t, "example.net\n")
+	address := fmt.Sprintf("127.0.0.1:%d", DNSServerPort)
+
+	for _, args := range [][]string{
+		{"--server", address},
+		{"--server", address, "--profile", "test"},
+	} {
+		file, err := os.CreateTemp(t.TempDir(), "zns")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rootCmd := NewRootCommand()
+		rootCmd.SetArgs(append([]string{"bench", "--config", path, "--domains", domains, "--count", "2", "--qps", "1000", "--output-file", file.Name()}, args...))
+
+		err = rootCmd.Execute()
+		assert.NoError(t, err)
+
+		logFile, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, string(logFile), address)
+		assert.NotContains(t, string(logFile), "192.0.2.")
+	}
+
+	// Without --server, the server of the selected profile is benchmarked alone. Nothing answers it.
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"bench", "--config", path, "--domains", domains, "--count", "1", "--timeout", "1ms", "--output-file", file.Name()})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(logFile), "192.0.2.2:53")
+	assert.NotContains(t, string(logFile), "192.0.2.1")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/caa"
+	"github.com/znscli/zns/internal/view"
+)
+
+// newCAACommand creates the caa command, which evaluates the CAA records relevant to a domain.
+func newCAACommand() *cobra.Command {
+	var (
+		ca     string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "caa <domain>",
+		Short: "Evaluate which certificate authorities may issue certificates for a domain",
+		Long:  "Evaluate the CAA records of a domain. Like certificate authorities, zns climbs the tree from the domain towards the root until it finds a name with CAA records, as described in RFC 8659. It then parses the issue, issuewild and iodef properties and reports which CAs may issue certificates for the domain and for wildcards below it, or, with --ca, whether that CA may.",
+		Example: `
+  # List the CAs that may issue certificates for www.example.com
+  zns caa www.example.com
+
+  # Check whether Let's Encrypt may issue certificates for example.com and *.example.com
+  zns caa example.com --ca letsencrypt.org
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			vt, err := arguments.ParseViewType(output)
+			if err != nil {
+				return err
+			}
+
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			w := view.NewTabWriter(out, debug)
+			v, err := view.NewCAARenderer(vt, view.NewView(w))
+			if err != nil {
+				return err
+			}
+
+			logger := newLogger(logOut, logColor, false, args[0])
+			logger.Debug("Flags", "server", server, "ca", ca, "debug", debug)
+
+			querier, err := newQuerier(logger)
+			if err != nil {
+				return err
+			}
+
+			report, err := caa.Evaluate(querier, args[0], ca)
+			if err != nil {
+				return fmt.Errorf("error: failed to look up the CAA records of %s: %v", args[0], err)
+			}
+
+			if err := v.RenderCAA(report); err != nil {
+				return fmt.Errorf("error: failed to write output: %v", err)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&ca, "ca", "", "Check whether the CA with this issuer domain, e.g. letsencrypt.org, may issue")
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json)")
+
+	return cmd
+}
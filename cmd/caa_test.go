@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cmd_CAA(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"caa", "www.example.net", "--ca", "letsencrypt.org", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "www.example.net.   -\n" +
+		"example.net.       0 issue \"letsencrypt.org\"\n" +
+		"example.net.       0 issuewild \";\"\n" +
+		"\n" +
+		"letsencrypt.org may issue for www.example.net. (issue properties)\n" +
+		"letsencrypt.org may not issue for *.www.example.net. (issuewild properties)\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_CAA_JSON(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"caa", "example.net", "-o", "json", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), "\"owner\": \"example.net.\"")
+	assert.Contains(t, string(logFile), "\"issuers\": [\n      \"letsencrypt.org\"\n    ]")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/delegation"
+	"github.com/znscli/zns/internal/view"
+)
+
+// newCheckCommand creates the check command, which checks the delegation of a zone and the health of its nameservers.
+func newCheckCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "check <zone>",
+		Short: "Check the delegation of a zone and the health of its nameservers",
+		Long:  "Check the delegation of a zone. zns asks the servers of the parent zone for the NS records and glue of the zone, then queries each nameserver directly. It reports lame delegations, missing glue, nameservers that are aliases or do not answer authoritatively, NS records that differ between the parent and the nameservers, inconsistent SOA serials, and nameservers that are all in the same network or autonomous system. Nameservers are queried on port 53 over UDP, and over TCP when an answer is truncated, whatever the port and transport of the configured server.",
+		Example: `
+  # Check the delegation of example.com
+  zns check example.com
+
+  # JSON output
+  zns check example.com --output json | jq
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			vt, err := arguments.ParseViewType(output)
+			if err != nil {
+				return err
+			}
+
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			w := view.NewTabWriter(out, debug)
+			v, err := view.NewCheckRenderer(vt, view.NewView(w))
+			if err != nil {
+				return err
+			}
+
+			logger := newLogger(logOut, logColor, false, args[0])
+			logger.Debug("Flags", "server", server, "debug", debug)
+
+			querier, err := newQuerier(logger)
+			if err != nil {
+				return err
+			}
+
+			report, err := delegation.Check(querier, args[0])
+			if err != nil {
+				return fmt.Errorf("error: failed to check the delegation of %s: %v", args[0], err)
+			}
+
+			if err := v.RenderCheck(report); err != nil {
+				return fmt.Errorf("error: failed to write output: %v", err)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json)")
+
+	return cmd
+}
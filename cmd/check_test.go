@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cmd_Check(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"check", "example.net", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "pass   delegation    net. delegates example.net. to ns1.example.org., ns2.example.org.\n" +
+		"pass   nameservers   2 nameservers\n" +
+		"pass   glue          the parent has glue for the nameservers that need it\n" +
+		"pass   cname         no nameserver is an alias\n" +
+		"pass   authority     all nameservers answer authoritatively\n" +
+		"pass   consistency   the NS records of the nameservers match those of the parent\n" +
+		"pass   serial        all nameservers have serial 2024010101\n" +
+		"warn   network       all IPv4 addresses of the nameservers are in 127.0.0.0/24\n" +
+		"warn   asn           the autonomous system of 2 addresses is unknown\n" +
+		"\n" +
+		"7 passed, 2 warnings, 0 failed\n"
+	assert.Equal(t, want, string(logFile))
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/znscli/zns/internal/config"
+)
+
+// exclusiveFlags holds the groups of flags that cannot be combined. A setting from the environment or the
+// config file is ignored if another flag of its group was already set, so that the command line always wins.
+var exclusiveFlags = [][]string{
+	{"json", "output", "format", "format-file"},
+}
+
+// applySettings sets the flags that were not given on the command line from, in order of precedence,
+// ZNS_* environment variables, the selected profile and the defaults of the config file.
+// It returns the config file that was read, which is empty if there is none.
+func applySettings(cmd *cobra.Command) (*config.Config, error) {
+	flags := cmd.Flags()
+
+	path := configFile
+	optional := false
+	if !flags.Changed("config") {
+		if path = os.Getenv(config.EnvName("config")); path == "" {
+			var err error
+			if path, err = config.Path(); err != nil {
+				return nil, fmt.Errorf("error: failed to locate config file: %v", err)
+			}
+			optional = true
+		}
+	}
+
+	conf, err := config.Load(path, optional)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Name == "help" || f.Name == "version" || !applies(cmd, f.Name) {
+			return
+		}
+		if value, ok := os.LookupEnv(config.EnvName(f.Name)); ok {
+			env[f.Name] = value
+		}
+	})
+
+	name := profile
+	if !flags.Changed("profile") {
+		if name = env["profile"]; name == "" {
+			name = conf.Defaults["profile"]
+		}
+	}
+	var profileSettings map[string]string
+	if name != "" {
+		if profileSettings, err = conf.Profile(name); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, source := range []struct {
+		name     string
+		settings map[string]string
+	}{
+		{"environment", env},
+		{fmt.Sprintf("profile %q", name), profileSettings},
+		{path, conf.Defaults},
+	} {
+		// Settings are applied in a fixed order, so that conflicting settings of the same source resolve consistently.
+		keys := make([]string, 0, len(source.settings))
+		for key := range source.settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if key == "config" || key == "profile" {
+				continue
+			}
+			if !known(cmd, key) || key == "help" || key == "version" {
+				return nil, fmt.Errorf("error: unknown setting %q in %s", key, source.name)
+			}
+			if !applies(cmd, key) || flags.Changed(key) || exclusiveChanged(flags, key) {
+				continue
+			}
+			if err := setFlag(flags, key, source.settings[key]); err != nil {
+				return nil, fmt.Errorf("error: invalid value %q for setting %q in %s: %v", source.settings[key], key, source.name, err)
+			}
+		}
+	}
+
+	return conf, nil
+}
+
+// setFlag sets a flag from a setting. A setting replaces the value of a flag that may be repeated, such as the
+// --server flag of the bench command, rather than being appended to it.
+func setFlag(flags *pflag.FlagSet, name, value string) error {
+	if sv, ok := flags.Lookup(name).Value.(pflag.SliceValue); ok {
+		if err := sv.Replace([]string{value}); err != nil {
+			return err
+		}
+		flags.Lookup(name).Changed = true
+		return nil
+	}
+	return flags.Set(name, value)
+}
+
+// known reports whether a setting names a flag of the command or of the root command, whose settings
+// can be shared with subcommands in the config file.
+func known(cmd *cobra.Command, name string) bool {
+	root := cmd.Root()
+	return cmd.Flags().Lookup(name) != nil || root.Flags().Lookup(name) != nil || root.PersistentFlags().Lookup(name) != nil
+}
+
+// applies reports whether a setting applies to a command. The local flags of the root command only apply
+// to the root command, even if a subcommand has a flag of the same name, such as --output.
+func applies(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) == nil {
+		return false
+	}
+	return !cmd.HasParent() || cmd.Root().LocalNonPersistentFlags().Lookup(name) == nil
+}
+
+// exclusiveChanged reports whether a flag that cannot be combined with the given flag was set.
+func exclusiveChanged(flags *pflag.FlagSet, name string) bool {
+	for _, group := range exclusiveFlags {
+		if !slices.Contains(group, name) {
+			continue
+		}
+		for _, other := range group {
+			if other != name && flags.Changed(other) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/enum"
+	"github.com/znscli/zns/internal/query"
+	"github.com/znscli/zns/internal/view"
+	"github.com/znscli/zns/internal/wordlist"
+)
+
+// newEnumCommand creates the enum command, which discovers the names of a zone from a wordlist.
+func newEnumCommand() *cobra.Command {
+	var (
+		wordlistF string
+		qtypes    string
+		output    string
+		workers   int
+		rate      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "enum <zone>",
+		Short: "Discover the names of a zone by querying the labels of a wordlist",
+		Long:  "Discover the names of a zone by querying <word>.<zone> for each word of a wordlist, as done to take inventory of the names a zone exposes. The names are queried by a bounded number of workers, optionally at a limited rate. A random name is queried first, to detect a wildcard: answers the names share with it were synthesized from the wildcard, and are left out. The records of the names that were found are rendered like those of a regular query.",
+		Example: `
+  # Discover the names of example.com
+  zns enum example.com --wordlist words.txt
+
+  # Query A and MX records, with 20 workers at no more than 100 queries per second
+  zns enum example.com --wordlist words.txt -q A,MX --workers 20 --rate 100
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			vt, err := arguments.ParseViewType(output)
+			if err != nil {
+				return err
+			}
+
+			var types []uint16
+			for _, t := range strings.Split(qtypes, ",") {
+				qtype, ok := query.Type(strings.TrimSpace(t))
+				if !ok {
+					return fmt.Errorf("error: invalid query type: %s", t)
+				}
+				types = append(types, qtype)
+			}
+			if rate < 0 {
+				return fmt.Errorf("error: invalid rate: %d", rate)
+			}
+
+			if wordlistF == "" {
+				return fmt.Errorf("error: the --wordlist flag is required")
+			}
+			f, err := os.Open(wordlistF)
+			if err != nil {
+				return fmt.Errorf("error: failed to read wordlist: %v", err)
+			}
+			words, err := wordlist.Read(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("error: failed to read wordlist: %v", err)
+			}
+
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			// Only the human view is aligned into a table, as for regular queries.
+			var w interface {
+				io.Writer
+				Flush() error
+			}
+			if vt == arguments.ViewHuman {
+				w = view.NewTabWriter(out, debug)
+			} else {
+				w = bufio.NewWriter(out)
+			}
+			v := view.NewRenderer(vt, view.NewView(w))
+
+			logger := newLogger(logOut, logColor, false, args[0])
+			logger.Debug("Flags", "server", server, "qtype", qtypes, "wordlist", wordlistF, "workers", workers, "rate", rate, "debug", debug)
+
+			querier, err := newQuerier(logger)
+			if err != nil {
+				return err
+			}
+
+			// Each found name is rendered as the domain of its own responses, while views that describe
+			// the whole run are about the zone.
+			if dr, ok := v.(view.DomainRenderer); ok {
+				dr.SetDomain(args[0])
+			}
+
+			opts := enum.Options{Types: types, Workers: workers, Rate: rate}
+			stats, err := enum.Enumerate(querier, args[0], words, opts, func(result enum.Result) {
+				if result.Err != nil {
+					logger.Warn("Query failed", "name", result.Name, "error", result.Err)
+				}
+				if !result.Found() {
+					return
+				}
+				for _, resp := range result.Responses {
+					v.Render(result.Name, resp)
+				}
+				w.Flush()
+			})
+			if err != nil {
+				return fmt.Errorf("error: failed to enumerate %s: %v", args[0], err)
+			}
+			logger.Info("Enumeration finished", "queried", stats.Queried, "found", stats.Found, "wildcard", stats.Wildcard, "failed", stats.Failed)
+
+			if f, ok := v.(view.Flusher); ok {
+				if err := f.Flush(); err != nil {
+					return fmt.Errorf("error: failed to write output: %v", err)
+				}
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&wordlistF, "wordlist", "", "File with the labels to query, one per line")
+	cmd.Flags().StringVarP(&qtypes, "query-type", "q", "A,AAAA", "DNS query types to ask for each name, comma-separated")
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json, ndjson, zone, csv, tsv, yaml, dig, markdown, html)")
+	cmd.Flags().IntVar(&workers, "workers", 10, "Number of names queried concurrently")
+	cmd.Flags().IntVar(&rate, "rate", 0, "Maximum number of queries per second (0 for no limit)")
+
+	return cmd
+}
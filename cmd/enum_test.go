@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeWordlist writes a wordlist to a temporary file and returns its name.
+func writeWordlist(t *testing.T, words string) string {
+	name := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(name, []byte(words), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func Test_Cmd_Enum(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	// www and ftp are answered from the wildcard of wildcard.example.net, mail has a record of its own.
+	wordlist := writeWordlist(t, "www\nmail\n# comment\nftp\n")
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"enum", "wildcard.example.net", "--wordlist", wordlist, "-q", "A", "--workers", "1", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "A   mail.wildcard.example.net.   05m00s   192.0.2.25\n", string(logFile))
+}
+
+func Test_Cmd_Enum_JSON(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	wordlist := writeWordlist(t, "mx1\nns1\n")
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"enum", "example.net", "--wordlist", wordlist, "-q", "A", "--rate", "50", "--output", "ndjson", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(logFile), `"name":"mx1.example.net.","type":"A","class":"IN","ttl":300,"value":"198.51.100.25"`)
+	assert.NotContains(t, string(logFile), "ns1.example.net.")
+}
+
+func Test_Cmd_Enum_Domain(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	wordlist := writeWordlist(t, "mx1\n")
+
+	for output, want := range map[string]string{
+		// Each found name is rendered as the domain it was queried for.
+		"dig": "; <<>> zns dev <<>> mx1.example.net. A\n",
+		// Views that describe the whole run are about the zone.
+		"json": `"domain": "example.net"`,
+	} {
+		file, err := os.CreateTemp(t.TempDir(), "zns")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rootCmd := NewRootCommand()
+		rootCmd.SetArgs([]string{"enum", "example.net", "--wordlist", wordlist, "-q", "A", "--output", output, "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+		err = rootCmd.Execute()
+		assert.NoError(t, err)
+
+		logFile, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, string(logFile), want)
+	}
+}
+
+func Test_Cmd_Enum_Errors(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	for want, args := range map[string][]string{
+		"error: the --wordlist flag is required": {"enum", "example.net"},
+		"error: invalid query type: BOGUS":       {"enum", "example.net", "--wordlist", "words.txt", "-q", "A,BOGUS"},
+		"error: invalid rate: -1":                {"enum", "example.net", "--wordlist", "words.txt", "--rate", "-1"},
+	} {
+		rootCmd := NewRootCommand()
+		rootCmd.SetArgs(append(args, "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)))
+
+		err := rootCmd.Execute()
+		assert.EqualError(t, err, want)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"enum", "example.net", "--wordlist", filepath.Join(t.TempDir(), "missing.txt"), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+	assert.ErrorContains(t, err, "error: failed to read wordlist: ")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/mail"
+	"github.com/znscli/zns/internal/view"
+)
+
+// newMailCommand creates the mail command, which audits the email records of a domain.
+func newMailCommand() *cobra.Command {
+	var (
+		selectors []string
+		output    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mail <domain>",
+		Short: "Audit the email records of a domain: MX, SPF, DMARC, DKIM, MTA-STS, TLS-RPT and BIMI",
+		Long:  "Audit the email records of a domain. zns looks up the MX records, the SPF record, the DMARC, MTA-STS, TLS-RPT and BIMI records and the DKIM keys of the given selectors, then parses each policy and reports problems such as multiple SPF records, too many DNS lookups in SPF or a DMARC policy that does not protect the domain.",
+		Example: `
+  # Audit the email records of example.com
+  zns mail example.com
+
+  # Also check the DKIM keys of two selectors
+  zns mail example.com --selector google --selector s1
+
+  # JSON output
+  zns mail example.com --output json | jq
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			vt, err := arguments.ParseViewType(output)
+			if err != nil {
+				return err
+			}
+
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			w := view.NewTabWriter(out, debug)
+			v, err := view.NewMailRenderer(vt, view.NewView(w))
+			if err != nil {
+				return err
+			}
+
+			logger := newLogger(logOut, logColor, false, args[0])
+			logger.Debug("Flags", "server", server, "selectors", selectors, "debug", debug)
+
+			querier, err := newQuerier(logger)
+			if err != nil {
+				return err
+			}
+
+			questions := mail.Questions(args[0], selectors)
+			responses, err := querier.MultiQuestion(questions)
+			if err != nil {
+				return err
+			}
+
+			answers := make(mail.Answers)
+			for i, r := range responses {
+				// A failed query says nothing about the records, so it must not be reported as a missing record.
+				if err := r.Err(); err != nil {
+					return fmt.Errorf("error: failed to audit %s: %v", args[0], err)
+				}
+				answers[questions[i]] = r.Answer
+			}
+
+			if err := v.RenderMail(mail.Audit(args[0], selectors, answers)); err != nil {
+				return fmt.Errorf("error: failed to write output: %v", err)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&selectors, "selector", nil, "DKIM selector to check the key of, e.g. google (can be repeated or comma-separated)")
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json)")
+
+	return cmd
+}
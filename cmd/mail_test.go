@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	encjson "encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cmd_Mail(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"mail", "example.net", "--selector", "google,s1", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), "MX              example.net.                     10 mx1.example.net.\n")
+	assert.Contains(t, string(logFile), "SPF             example.net.                     v=spf1 include:_spf.example.org ~all\n")
+	assert.Contains(t, string(logFile), "DMARC           _dmarc.example.net.              v=DMARC1; p=none\n")
+	assert.Contains(t, string(logFile), "warning: p=none only monitors, email failing authentication is still delivered\n")
+	assert.Contains(t, string(logFile), "DKIM (google)   google._domainkey.example.net.   v=DKIM1; k=rsa; p=MIGf")
+	assert.Contains(t, string(logFile), "error: no DKIM key for selector s1\n")
+	assert.Contains(t, string(logFile), "\n1 error, 2 warnings\n")
+	assert.NotContains(t, string(logFile), "google-site-verification")
+}
+
+func Test_Cmd_Mail_Rcode(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"mail", "broken.example.net", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+	assert.EqualError(t, err, "error: failed to audit broken.example.net: SERVFAIL looking up TXT _dmarc.broken.example.net.")
+}
+
+func Test_Cmd_Mail_JSON(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"mail", "example.net", "--output", "json", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Domain string `json:"domain"`
+		Checks []struct {
+			Name    string   `json:"name"`
+			Records []string `json:"records"`
+		} `json:"checks"`
+	}
+	assert.NoError(t, encjson.Unmarshal(logFile, &report))
+	assert.Equal(t, "example.net.", report.Domain)
+	assert.Equal(t, "MX", report.Checks[0].Name)
+	assert.Equal(t, []string{"10 mx1.example.net."}, report.Checks[0].Records)
+}
+
+func Test_Cmd_Mail_Output_Invalid(t *testing.T) {
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"mail", "example.net", "--output", "csv", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: the csv output is not supported by the mail command", err.Error())
+}
+
+func Test_Cmd_Mail_Config(t *testing.T) {
+	// The output setting belongs to the query command, and does not apply to the --output flag of the mail command.
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := fmt.Sprintf("server: 127.0.0.1:%d\noutput: csv\nsort: type\n", DNSServerPort)
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZNS_OUTPUT", "zone")
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"mail", "example.net", "--config", path, "--output-file", file.Name()})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), "10 mx1.example.net.")
+}
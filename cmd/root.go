@@ -1,20 +1,25 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
-	"runtime"
+	"os/signal"
 	"sort"
 	"strings"
+	"time"
 
-	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-multierror"
 	"github.com/miekg/dns"
 	"github.com/spf13/cobra"
 	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/filter"
 	"github.com/znscli/zns/internal/query"
 	"github.com/znscli/zns/internal/view"
+	"github.com/znscli/zns/internal/watch"
 )
 
 const (
@@ -25,23 +30,46 @@ var (
 	version = "dev"
 	debug   bool
 	json    bool
+	output  string
+	outputF string
+	format  string
+	formatF string
 	noColor bool
 	server  string
 	qtype   string
+	watchIn time.Duration
+	execCmd string
+	ttl     string
+	sortBy  string
+	groupBy string
+	filterE string
+
+	configFile string
+	profile    string
+	transport  string
+	timeout    time.Duration
+	colorMode  string
+	wildcard   bool
+	stats      bool
 )
 
 // EnsureDNSAddress formats the DNS server address properly.
 func EnsureDNSAddress(server string) string {
+	return EnsureDNSAddressPort(server, "53")
+}
+
+// EnsureDNSAddressPort formats the DNS server address properly, using the given port if the address does not include one.
+func EnsureDNSAddressPort(server string, port string) string {
 	if strings.Contains(server, "]") || strings.Contains(server, ":") && net.ParseIP(server) == nil {
 		return server
 	}
 
 	ip := net.ParseIP(server)
 	if ip != nil && ip.To4() == nil { // It's IPv6 (and not IPv4)
-		return "[" + server + "]:53"
+		return "[" + server + "]:" + port
 	}
 	// Otherwise, assume IPv4 or hostname, so append port normally.
-	return server + ":53"
+	return server + ":" + port
 }
 
 func NewRootCommand() *cobra.Command {
@@ -56,154 +84,384 @@ func NewRootCommand() *cobra.Command {
   # Query a specific record type
   zns example.com -q NS
 
+  # Query several record types, or a preset of related records
+  zns example.com -q A,AAAA
+  zns example.com -q mail
+
   # Use a specific DNS server
   zns example.com -q NS --server 1.1.1.1
 
+  # Use the settings of a profile from the config file
+  zns example.com --profile internal
+
   # JSON output
-  zns example.com --json | jq
+  zns example.com --output json | jq
+
+  # Custom output through a Go template
+  zns example.com -q MX --format '{{.Rdata.preference}} {{.Rdata.exchange}}'
+
+  # Only show low-preference mail servers, sorted by preference
+  zns example.com -q MX --filter 'preference<20' --sort preference
+
+  # Mark records that are synthesized from a wildcard
+  zns www.example.com -q A --wildcard
+
+  # Show the server, round-trip time and size of the response of each record
+  zns example.com --stats
+
+  # Audit the email records of a domain
+  zns mail example.com --selector google
+
+  # Expand an SPF record, and check whether a server may send email
+  zns spf example.com --ip 192.0.2.1
+
+  # Check whether a CA may issue certificates for a domain
+  zns caa example.com --ca letsencrypt.org
+
+  # Check TLSA records against a certificate chain
+  zns tlsa _25._tcp.mail.example.com --cert chain.pem
+
+  # Check the delegation of a zone and the health of its nameservers
+  zns check example.com
 
-  # Writing to a file
+  # Discover the names of a zone from a wordlist
+  zns enum example.com --wordlist words.txt
+
+  # Enumerate the names of a DNSSEC-signed zone
+  zns walk example.com
+
+  # Compare the latency of two DNS resolvers
+  zns bench --server 1.1.1.1 --server 8.8.8.8 --domains domains.txt
+
+  # Watch for changes, re-querying every 10 seconds
+  zns example.com --watch=10s --exec 'notify-send "DNS changed"'
+
+  # Writing results to a file, and debug logs to another
   export ZNS_LOG_FILE=/tmp/zns.log
-  zns example.com
+  zns example.com --debug --output-file /tmp/zns.txt
 `,
 		Version:       version,
 		SilenceErrors: true, // We handle errors ourselves.
 		SilenceUsage:  true, // Prevents the automatic rendering of the usage message when an error occurs.
+		// Any argument that is not a subcommand is a domain name.
+		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return fmt.Errorf("error: domain name is required")
 			}
 
-			var color hclog.ColorOption
-			if os.Getenv("NO_COLOR") != "" {
-				noColor = true
-				color = hclog.ColorOff
-			} else {
-				color = hclog.AutoColor
+			conf, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			if execCmd != "" && !cmd.Flags().Changed("watch") {
+				return fmt.Errorf("error: the --exec flag requires --watch")
 			}
 
-			logLevel := os.Getenv("ZNS_LOG_LEVEL")
-			if debug {
-				logLevel = "DEBUG"
+			if formatF != "" {
+				b, err := os.ReadFile(formatF)
+				if err != nil {
+					return fmt.Errorf("error: failed to read format file: %v", err)
+				}
+				format = string(b)
 			}
 
 			var vt arguments.ViewType
 			if json {
 				vt = arguments.ViewJSON
+			} else if format != "" {
+				vt = arguments.ViewTemplate
 			} else {
-				vt = arguments.ViewHuman
+				var err error
+				if vt, err = arguments.ParseViewType(output); err != nil {
+					return err
+				}
 			}
 
-			var w = view.NewTabWriter(os.Stdout, debug)
-			logFile := os.Getenv("ZNS_LOG_FILE")
-			if logFile != "" {
-				f, err := os.Create(logFile)
-				if err != nil {
-					return fmt.Errorf("error: failed to create log file: %v", err)
+			tf, err := arguments.ParseTTLFormat(ttl)
+			if err != nil {
+				return err
+			}
+
+			sk, err := query.ParseSortKey(sortBy)
+			if err != nil {
+				return err
+			}
+			gb, err := query.ParseGroupBy(groupBy)
+			if err != nil {
+				return err
+			}
+			// dig renders every response as a complete message, which cannot be split up by sorting or grouping.
+			// The document and report views lay out the records themselves, by query or by owner name.
+			switch vt {
+			case arguments.ViewDig, arguments.ViewJSONDocument, arguments.ViewYAML, arguments.ViewMarkdown, arguments.ViewHTML:
+				if sk != query.SortNone || gb != query.GroupNone {
+					return fmt.Errorf("error: the --sort and --group flags are not supported by the %s view", vt)
+				}
+			}
+
+			var expr filter.Expr
+			if filterE != "" {
+				if expr, err = filter.Parse(filterE); err != nil {
+					return err
 				}
-				defer f.Close()
-				w = view.NewTabWriter(f, debug)
 			}
 
-			v := view.NewRenderer(vt, &view.View{
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			// Only the human view is aligned into a table. Other views are written as is,
+			// as padding would corrupt formats that use tabs themselves, such as TSV.
+			var w interface {
+				io.Writer
+				Flush() error
+			}
+			if vt == arguments.ViewHuman {
+				w = view.NewTabWriter(out, debug)
+			} else {
+				w = bufio.NewWriter(out)
+			}
+
+			vw := &view.View{
 				Stream: &view.Stream{
 					Writer: w,
 				},
-			})
+				TTL:   tf,
+				Stats: stats,
+			}
 
-			logger := hclog.New(&hclog.LoggerOptions{
-				Name:                 "zns",
-				Output:               w,
-				Level:                hclog.LevelFromString(logLevel),
-				Color:                color,
-				ColorHeaderAndFields: !noColor,
-				DisableTime:          false,
-				JSONFormat:           json,
-			}).With("@domain", args[0])
+			var v view.Renderer
+			if vt == arguments.ViewTemplate {
+				if v, err = view.NewTemplateRenderer(vw, format); err != nil {
+					return err
+				}
+			} else {
+				v = view.NewRenderer(vt, vw)
+			}
 
-			logger.Debug("Debug logging enabled", "debug", debug)
-			logger.Debug("Log level", "level", logger.GetLevel())
+			logger := newLogger(logOut, logColor, json, args[0])
 
 			logger.Debug("Args", "args", args)
 			logger.Debug("Flags", "server", server, "qtype", qtype, "debug", debug)
 
-			// Resolve the DNS nameserver from the host.
-			// Supported only on Unix-like systems.
-			// On Windows, dynamic DNS resolution is not supported;
-			// a DNS nameserver must be explicitly specified using the --server flag.
-			if server == "" {
-				switch runtime.GOOS {
-				case "windows":
-					return fmt.Errorf("error: host DNS nameserver resolution is not supported on Windows; please specify a DNS server using the --server flag")
-				default:
-					logger.Debug(fmt.Sprintf("Resolving DNS nameserver from \"%s\"", resolveConfPath), "path", resolveConfPath)
-
-					// Attempt to retrieve the DNS nameserver from `/etc/resolv.conf`.
-					conf, err := dns.ClientConfigFromFile(resolveConfPath)
-					if err != nil {
-						return fmt.Errorf("error: failed to read %s: %v", resolveConfPath, err)
-					}
+			querier, err := newQuerier(logger)
+			if err != nil {
+				return err
+			}
 
-					if len(conf.Servers) == 0 {
-						return fmt.Errorf("error: no DNS nameservers found in %s", resolveConfPath)
-					}
+			logger.Debug("Creating querier", "server", server, "qtype", qtype, "domain", args[0])
 
-					server = conf.Servers[0] // Use the first available DNS nameserver.
-					logger.Debug(fmt.Sprintf("Using DNS nameserver %s", server), "server", server, "path", resolveConfPath)
-				}
+			// Query every supported type, unless types or presets were specified.
+			questions, err := query.ParseQuestions(args[0], qtype, conf.Presets)
+			if err != nil {
+				return err
 			}
 
-			server = EnsureDNSAddress(server)
+			// resolve queries all requested types and follows CNAME chains. The responses are
+			// sorted by query type alphabetically, so the output is consistent, and the responses to
+			// the queries that chased a CNAME chain follow the response they were chased for.
+			resolve := func() ([]*query.Response, *query.Chain, error) {
+				responses, err := querier.MultiQuestion(questions)
+				if err != nil {
+					return nil, nil, err
+				}
 
-			querier := query.NewQueryClient(server, new(dns.Client), logger)
+				sort.SliceStable(responses, func(i, j int) bool {
+					return dns.TypeToString[responses[i].Question[0].Qtype] < dns.TypeToString[responses[j].Question[0].Qtype]
+				})
+
+				var errors *multierror.Error
+				var resolved []*query.Response
+				for _, r := range responses {
+					chain, err := querier.FollowCNAME(r.Msg)
+					errors = multierror.Append(errors, err)
+					resolved = append(resolved, r)
+					resolved = append(resolved, chain.Chased...)
+				}
+				if err := errors.ErrorOrNil(); err != nil {
+					return nil, nil, err
+				}
+				responses = resolved
 
-			logger.Debug("Creating querier", "server", server, "qtype", qtype, "domain", args[0])
+				var sections []dns.RR
+				for _, r := range responses {
+					sections = append(sections, r.Answer...)
+					sections = append(sections, r.Ns...)
+				}
+				if wildcard {
+					if err := querier.DetectWildcards(responses); err != nil {
+						logger.Warn("Wildcard detection failed", "error", err)
+					}
+				}
+
+				// The chain is built before filtering, so it is complete even if its CNAMEs are filtered out.
+				chain := query.BuildChain(args[0], sections)
+				if expr != nil {
+					for _, r := range responses {
+						r.Answer = filter.Filter(expr, r.Answer)
+					}
+				}
 
-			// Create a slice of supported query types to query.
-			qtypes := make([]uint16, 0, len(query.QueryTypes))
-			for _, qtype := range query.QueryTypes {
-				qtypes = append(qtypes, qtype)
+				return responses, chain, nil
 			}
 
-			// Filter down to the specified query type, if provided.
-			if qtype != "" {
-				qtypeInt, ok := query.QueryTypes[strings.ToUpper(qtype)]
-				if !ok {
-					return fmt.Errorf("error: invalid query type: %s", qtype)
+			// answers returns the answers of all responses, for comparison between watch mode iterations.
+			// Every query for an alias returns the same CNAME records, so only the first of each is kept.
+			answers := func(responses []*query.Response) []dns.RR {
+				var records []dns.RR
+				seen := make(map[string]bool)
+				for _, r := range responses {
+					for _, record := range r.Answer {
+						if !seen[record.String()] {
+							seen[record.String()] = true
+							records = append(records, record)
+						}
+					}
 				}
-				qtypes = []uint16{qtypeInt}
+				return records
 			}
-
-			messages, err := querier.MultiQuery(args[0], qtypes)
-			if err != nil {
-				if merr, ok := err.(*multierror.Error); ok {
-					return merr
-				} else {
-					return err
+			fetch := func() ([]dns.RR, error) {
+				responses, _, err := resolve()
+				if err != nil {
+					return nil, err
 				}
+				return answers(responses), nil
 			}
 
-			// Sort the messages by query type alphabetically, so the output is consistent.
-			sort.SliceStable(messages, func(i, j int) bool {
-				return dns.TypeToString[messages[i].Question[0].Qtype] < dns.TypeToString[messages[j].Question[0].Qtype]
-			})
+			cr, ok := v.(view.ChangeRenderer)
+			if cmd.Flags().Changed("watch") && !ok {
+				return fmt.Errorf("error: watch mode is not supported by the %s view", vt)
+			}
 
-			for _, m := range messages {
-				for _, record := range m.Answer {
-					v.Render(args[0], record)
+			var responses []*query.Response
+			var chain *query.Chain
+			if er, ok := v.(view.ErrorRenderer); ok && sk == query.SortNone && gb == query.GroupNone {
+				// Renderers that report errors inline stream each response as soon as its query completes,
+				// instead of waiting for all queries to finish. Sorting and grouping need all responses, so
+				// they turn streaming off.
+				var sections []dns.RR
+				querier.StreamQuestion(questions, func(q query.Question, r *query.Response, c *query.Chain, err error) {
+					if err != nil {
+						er.RenderError(q.Name, q.Qtype, err)
+					} else {
+						for _, r := range append([]*query.Response{r}, c.Chased...) {
+							sections = append(sections, r.Answer...)
+							sections = append(sections, r.Ns...)
+							if wildcard {
+								if err := querier.DetectWildcards([]*query.Response{r}); err != nil {
+									logger.Warn("Wildcard detection failed", "error", err)
+								}
+							}
+							if expr != nil {
+								r.Answer = filter.Filter(expr, r.Answer)
+							}
+							v.Render(args[0], r)
+						}
+					}
+					w.Flush()
+				})
+				chain = query.BuildChain(args[0], sections)
+			} else {
+				var err error
+				responses, chain, err = resolve()
+				if err != nil {
+					if merr, ok := err.(*multierror.Error); ok {
+						return merr
+					} else {
+						return err
+					}
+				}
+
+				for _, g := range query.Arrange(responses, sk, gb) {
+					if gr, ok := v.(view.GroupRenderer); ok && g.Key != "" {
+						gr.RenderGroup(args[0], g.Key)
+					}
+					for _, r := range g.Responses {
+						v.Render(args[0], r)
+					}
+				}
+			}
+			if chr, ok := v.(view.ChainRenderer); ok && (chain.IsAlias() || len(chain.Apex) > 0) {
+				chr.RenderChain(args[0], chain)
+			}
+			if f, ok := v.(view.Flusher); ok {
+				if err := f.Flush(); errors.Is(err, view.ErrTemplateExecution) {
+					return fmt.Errorf("error: %v", err)
+				} else if err != nil {
+					return fmt.Errorf("error: failed to write output: %v", err)
 				}
 			}
 			w.Flush() // we need to flush the buffer to ensure all data is written to the underlying stream.
 
-			return nil
+			if !cmd.Flags().Changed("watch") {
+				return nil
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			logger.Debug("Watching for changes", "interval", watchIn, "exec", execCmd)
+
+			onChange := func(changes []watch.Change) {
+				now := time.Now()
+				for _, c := range changes {
+					cr.RenderChange(args[0], c, now)
+				}
+				w.Flush()
+
+				if execCmd != "" {
+					if err := watch.Exec(ctx, execCmd, args[0], changes, os.Stderr, os.Stderr); err != nil {
+						logger.Error(err.Error())
+					}
+				}
+			}
+			onError := func(err error) {
+				logger.Error("Query failed, keeping previous results", "error", err)
+			}
+
+			return watch.Run(ctx, watchIn, answers(responses), fetch, onChange, onError)
 		},
 	}
 
 	cmd.CompletionOptions.DisableDefaultCmd = true
-	cmd.Flags().StringVarP(&server, "server", "s", "", "DNS server to query")
-	cmd.Flags().StringVarP(&qtype, "query-type", "q", "", "DNS query type")
-	cmd.Flags().BoolVar(&debug, "debug", false, "Enable debug output")
-	cmd.Flags().BoolVar(&json, "json", false, "Output in JSON format")
+	cmd.PersistentFlags().StringVarP(&server, "server", "s", "", "DNS server to query")
+	cmd.PersistentFlags().StringVar(&transport, "transport", "udp", "Transport to query the DNS server over (udp, tcp, tls)")
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 2*time.Second, "Timeout of each DNS query")
+	cmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "When to use colors (auto, always, never); auto disables colors if NO_COLOR is set")
+	cmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file to read defaults from (default $XDG_CONFIG_HOME/zns/config.yaml)")
+	cmd.PersistentFlags().StringVar(&profile, "profile", "", "Profile of the config file to use")
+	cmd.Flags().StringVarP(&qtype, "query-type", "q", "", "DNS query types, comma-separated, or a preset (mail, web)")
+	cmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug output")
+	cmd.Flags().BoolVar(&json, "json", false, "Output in JSON log format, one log message per record")
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json, ndjson, zone, csv, tsv, yaml, dig, markdown, html)")
+	cmd.PersistentFlags().StringVar(&outputF, "output-file", "", "Write the results to a file instead of stdout")
+	cmd.Flags().StringVar(&format, "format", "", "Render each record through a Go text/template, e.g. '{{.Type}} {{.Name}} {{.TTL}} {{.Value}}'")
+	cmd.Flags().StringVar(&formatF, "format-file", "", "Read the --format template from a file")
+	for _, group := range exclusiveFlags {
+		cmd.MarkFlagsMutuallyExclusive(group...)
+	}
+	cmd.Flags().StringVar(&sortBy, "sort", "", "Sort records by type, name, ttl, value or preference")
+	cmd.Flags().StringVar(&groupBy, "group", "", "Group records by type or domain")
+	cmd.Flags().StringVar(&filterE, "filter", "", "Only show records matching an expression, e.g. 'type=MX && preference<20' or 'value~\"^v=spf1\"'")
+	cmd.Flags().BoolVar(&wildcard, "wildcard", false, "Probe a random sibling name to detect records synthesized from a wildcard, and mark them")
+	cmd.Flags().BoolVar(&stats, "stats", false, "Show the server, transport, round-trip time, size, response code and flags of the response of each record")
+	cmd.Flags().StringVar(&ttl, "ttl", "human", "TTL display: human (1d02h00m00s), raw (seconds) or expiry (time the cached record expires)")
+	cmd.Flags().DurationVar(&watchIn, "watch", 0, "Re-run the queries periodically and report changes (defaults to the lowest TTL seen)")
+	cmd.Flags().Lookup("watch").NoOptDefVal = "0s"
+	cmd.Flags().StringVar(&execCmd, "exec", "", "Command to run through the shell when changes are detected in watch mode")
+
+	cmd.AddCommand(newMailCommand())
+	cmd.AddCommand(newSPFCommand())
+	cmd.AddCommand(newCAACommand())
+	cmd.AddCommand(newTLSACommand())
+	cmd.AddCommand(newCheckCommand())
+	cmd.AddCommand(newEnumCommand())
+	cmd.AddCommand(newWalkCommand())
+	cmd.AddCommand(newBenchCommand())
 
 	return cmd
 }
@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"context"
+	encjson "encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/view"
 )
 
 const (
@@ -16,21 +23,73 @@ const (
 )
 
 func TestMain(m *testing.M) {
-	go startDNSServer()
+	// Keep the config file of the user running the tests from changing the results.
+	dir, err := os.MkdirTemp("", "zns")
+	if err != nil {
+		log.Fatalf("Failed to create config directory: %v", err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+
+	startDNSServer()
+	// Nameservers are queried directly on the port of the test server, rather than on port 53.
+	authPort = strconv.Itoa(DNSServerPort)
 
 	code := m.Run()
+	os.RemoveAll(dir)
 	os.Exit(code)
 }
 
+// startDNSServer starts the test DNS server and waits until it is ready to accept queries.
 func startDNSServer() {
 	dns.HandleFunc(".", dnsHandler)
 
+	started := make(chan struct{})
+	srv := &dns.Server{
+		Addr:              fmt.Sprintf(":%d", DNSServerPort),
+		Net:               "udp",
+		NotifyStartedFunc: func() { close(started) },
+	}
+
 	go func() {
-		err := dns.ListenAndServe(fmt.Sprintf(":%d", DNSServerPort), "udp", nil)
+		err := srv.ListenAndServe()
 		if err != nil {
 			log.Fatalf("Failed to start DNS server: %v", err)
 		}
 	}()
+
+	<-started
+}
+
+// zone holds further records served by the test DNS server, in zone file format.
+var zone = []string{
+	"example.net. 300 IN MX 10 mx1.example.net.",
+	`example.net. 300 IN TXT "v=spf1 include:_spf.example.org ~all"`,
+	`example.net. 300 IN TXT "google-site-verification=abc"`,
+	`_dmarc.example.net. 300 IN TXT "v=DMARC1; p=none"`,
+	`_spf.example.org. 300 IN TXT "v=spf1 ip4:192.0.2.0/24 mx -all"`,
+	"_spf.example.org. 300 IN MX 10 mx1.example.net.",
+	"mx1.example.net. 300 IN A 198.51.100.25",
+	`google._domainkey.example.net. 300 IN TXT "v=DKIM1; k=rsa; " "p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"`,
+	`example.net. 300 IN CAA 0 issue "letsencrypt.org"`,
+	`example.net. 300 IN CAA 0 issuewild ";"`,
+	"_25._tcp.mail.example.net. 300 IN TLSA 3 1 1 fd110d301d2f077de1414b8f99f441b1403fab207b2052fbd2c065e4ee8e7dc2",
+	"_25._tcp.mail.example.net. 300 IN TLSA 2 0 1 0000000000000000000000000000000000000000000000000000000000000000",
+	"net. 300 IN SOA a.gtld.example. hostmaster.example. 1 1800 900 604800 86400",
+	"net. 300 IN NS a.gtld.example.",
+	"a.gtld.example. 300 IN A 127.0.0.1",
+	"example.net. 300 IN NS ns1.example.org.",
+	"example.net. 300 IN NS ns2.example.org.",
+	"example.net. 300 IN SOA ns1.example.org. hostmaster.example.net. 2024010101 7200 3600 1209600 300",
+	"ns1.example.org. 300 IN A 127.0.0.1",
+	"ns2.example.org. 300 IN A 127.0.0.1",
+	"mail.wildcard.example.net. 300 IN A 192.0.2.25",
+	"signed.example.net. 300 IN NSEC mail.signed.example.net. A NS SOA RRSIG NSEC DNSKEY",
+	"mail.signed.example.net. 300 IN NSEC www.signed.example.net. MX RRSIG NSEC",
+	"www.signed.example.net. 300 IN NSEC signed.example.net. A RRSIG NSEC",
+	"hashed.example.net. 300 IN NSEC3PARAM 1 0 0 -",
+	"1rntj122pvib99becukn83tm6agq5les.hashed.example.net. 300 IN NSEC3 1 0 0 - F7RC53CTANOG6VA6C8KUQDO61PTLU31C A NS SOA RRSIG DNSKEY NSEC3PARAM",
+	"f7rc53ctanog6va6c8kuqdo61ptlu31c.hashed.example.net. 300 IN NSEC3 1 0 0 - IEEVK01CEQHD7QIFUCCSUU7BBN0U6FQE A RRSIG",
+	"ieevk01ceqhd7qifuccsuu7bbn0u6fqe.hashed.example.net. 300 IN NSEC3 1 0 0 - 1RNTJ122PVIB99BECUKN83TM6AGQ5LES MX RRSIG",
 }
 
 func dnsHandler(w dns.ResponseWriter, r *dns.Msg) {
@@ -67,6 +126,70 @@ func dnsHandler(w dns.ResponseWriter, r *dns.Msg) {
 			}
 			msg.Answer = append(msg.Answer, cname)
 		}
+		// Simulate an alias whose target is not included in the response, so zns has to chase it
+		if q.Name == "www.example.com." {
+			cname := &dns.CNAME{
+				Hdr: dns.RR_Header{
+					Name:   "www.example.com.",
+					Rrtype: dns.TypeCNAME,
+					Class:  dns.ClassINET,
+					Ttl:    60,
+				},
+				Target: "cdn.example.net.",
+			}
+			msg.Answer = append(msg.Answer, cname)
+		}
+		if q.Name == "cdn.example.net." && q.Qtype == dns.TypeA {
+			a := &dns.A{
+				Hdr: dns.RR_Header{
+					Name:   "cdn.example.net.",
+					Rrtype: dns.TypeA,
+					Class:  dns.ClassINET,
+					Ttl:    60,
+				},
+				A: net.ParseIP("192.0.2.1"),
+			}
+			msg.Answer = append(msg.Answer, a)
+		}
+		for _, record := range zone {
+			rr, err := dns.NewRR(record)
+			if err != nil {
+				log.Fatalf("Invalid test record %q: %v", record, err)
+			}
+			if strings.EqualFold(rr.Header().Name, q.Name) && rr.Header().Rrtype == q.Qtype {
+				msg.Answer = append(msg.Answer, rr)
+			}
+		}
+		// *.wildcard.example.net is a wildcard, which answers the names below it that have no records of their own.
+		if len(msg.Answer) == 0 && dns.IsSubDomain("wildcard.example.net.", q.Name) && q.Name != "wildcard.example.net." && q.Qtype == dns.TypeA {
+			a := &dns.A{
+				Hdr: dns.RR_Header{
+					Name:   q.Name,
+					Rrtype: dns.TypeA,
+					Class:  dns.ClassINET,
+					Ttl:    300,
+				},
+				A: net.ParseIP("192.0.2.80"),
+			}
+			msg.Answer = append(msg.Answer, a)
+		}
+		// Names that do not exist are denied with the NSEC3 records covering their hash, if the DO bit is set.
+		if opt := r.IsEdns0(); opt != nil && opt.Do() && len(msg.Answer) == 0 {
+			for _, record := range zone {
+				rr, _ := dns.NewRR(record)
+				if nsec3, ok := rr.(*dns.NSEC3); ok && nsec3.Cover(q.Name) {
+					msg.Ns = append(msg.Ns, nsec3)
+					msg.Rcode = dns.RcodeNameError
+				}
+			}
+		}
+		// The DMARC record of broken.example.net cannot be resolved, like a zone whose nameservers fail.
+		if q.Name == "_dmarc.broken.example.net." {
+			msg.Answer = nil
+			msg.Rcode = dns.RcodeServerFailure
+		}
+		// Non-recursive queries are answered authoritatively, like the nameservers of a zone do.
+		msg.Authoritative = !r.RecursionDesired
 	}
 
 	_ = w.WriteMsg(&msg)
@@ -106,29 +229,126 @@ func Test_Cmd_JSON(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func Test_Cmd_QueryType(t *testing.T) {
+func Test_Cmd_Output_JSON(t *testing.T) {
 	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
 
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
 	rootCmd := NewRootCommand()
-	rootCmd.SetArgs([]string{"example.com", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--output", "json", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
 
-	err := rootCmd.Execute()
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document view.Document
+	if err := encjson.Unmarshal(logFile, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, view.DocumentSchemaVersion, document.SchemaVersion)
+	assert.Equal(t, "example.com", document.Domain)
+	assert.Len(t, document.Queries, 1)
+	assert.Equal(t, "NOERROR", document.Queries[0].Rcode)
+	assert.Equal(t, uint32(60), document.Queries[0].Answers[0].TTL)
+	assert.Equal(t, "93.184.216.34", document.Queries[0].Answers[0].Value)
+}
+
+func Test_Cmd_Output_NDJSON(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--output", "ndjson", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
 
+	err = rootCmd.Execute()
 	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(logFile), "\n"), "\n")
+	assert.Len(t, lines, 3) // The A record, the CNAME record and the CNAME chain.
+
+	var kinds []string
+	for _, line := range lines {
+		var obj map[string]any
+		if err := encjson.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatal(err)
+		}
+		kinds = append(kinds, obj["kind"].(string))
+	}
+	assert.ElementsMatch(t, []string{"record", "record", "cname_chain"}, kinds)
 }
 
-func Test_Cmd_Debug(t *testing.T) {
+func Test_Cmd_Output_NDJSON_Error(t *testing.T) {
 	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
 
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	// Nothing listens on the discard port, so every query fails.
 	rootCmd := NewRootCommand()
-	rootCmd.SetArgs([]string{"example.com", "--debug", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--output", "ndjson", "--server", "127.0.0.1:9", "--query-type", "A"})
 
-	err := rootCmd.Execute()
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var obj map[string]any
+	if err := encjson.Unmarshal(logFile, &obj); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "error", obj["kind"])
+	assert.Equal(t, "A", obj["qtype"])
+}
+
+func Test_Cmd_Output_Zone(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--output", "zone", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
 
+	err = rootCmd.Execute()
 	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "$ORIGIN example.com.\nexample.com.\t60\tIN\tA\t93.184.216.34\n", string(logFile))
 }
 
-func Test_Cmd_LogFile(t *testing.T) {
+func Test_Cmd_Output_CSV(t *testing.T) {
 	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
 
 	file, err := os.CreateTemp(t.TempDir(), "zns")
@@ -137,26 +357,77 @@ func Test_Cmd_LogFile(t *testing.T) {
 	}
 	defer os.Remove(file.Name())
 
-	t.Setenv("ZNS_LOG_FILE", file.Name())
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--output", "csv", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "domain,name,type,ttl,value,server,rcode\n" +
+		"example.com,example.com.,A,60,93.184.216.34,127.0.0.1:53535,NOERROR\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_Output_Dig(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
 
 	rootCmd := NewRootCommand()
-	rootCmd.SetArgs([]string{"example.com", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--output", "dig", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
 
 	err = rootCmd.Execute()
 	assert.NoError(t, err)
 
-	assert.FileExists(t, file.Name())
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), ";; flags: qr rd; QUERY: 1, ANSWER: 1, AUTHORITY: 0, ADDITIONAL: 0\n")
+	assert.Contains(t, string(logFile), ";; ANSWER SECTION:\nexample.com.\t\t60\tIN\tA\t93.184.216.34\n")
+	assert.Contains(t, string(logFile), fmt.Sprintf(";; SERVER: 127.0.0.1#%d(127.0.0.1) (UDP)\n", DNSServerPort))
+	assert.Contains(t, string(logFile), ";; MSG SIZE  rcvd: 45\n")
+}
+
+func Test_Cmd_Output_Dig_CNAME(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"www.example.com", "--output-file", file.Name(), "--output", "dig", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
 
 	logFile, err := os.ReadFile(file.Name())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assert.Contains(t, string(logFile), "A       example.com.   01m00s   93.184.216.34")
-	assert.Contains(t, string(logFile), "CNAME   example.com.   01m00s   example.org.")
+	// The server only answered with the CNAME. The records of its target are in a message of their own,
+	// as they were received in answer to a second query.
+	assert.Equal(t, 2, strings.Count(string(logFile), ";; flags: qr rd; QUERY: 1, ANSWER: 1, AUTHORITY: 0, ADDITIONAL: 0\n"))
+	assert.Contains(t, string(logFile), ";; ANSWER SECTION:\nwww.example.com.\t60\tIN\tCNAME\tcdn.example.net.\n\n")
+	assert.Contains(t, string(logFile), ";; QUESTION SECTION:\n;cdn.example.net.\t\tIN\tA\n")
+	assert.Contains(t, string(logFile), ";; ANSWER SECTION:\ncdn.example.net.\t60\tIN\tA\t192.0.2.1\n")
 }
 
-func Test_Cmd_LogFile_Debug(t *testing.T) {
+func Test_Cmd_Output_Markdown(t *testing.T) {
 	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
 
 	file, err := os.CreateTemp(t.TempDir(), "zns")
@@ -165,27 +436,685 @@ func Test_Cmd_LogFile_Debug(t *testing.T) {
 	}
 	defer os.Remove(file.Name())
 
-	t.Setenv("ZNS_LOG_FILE", file.Name())
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"www.example.com", "--output-file", file.Name(), "--output", "markdown", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), "# DNS report for www.example.com\n")
+	assert.Contains(t, string(logFile), fmt.Sprintf("- **Server:** 127.0.0.1:%d\n", DNSServerPort))
+	assert.Contains(t, string(logFile), "- **CNAME chain:** www.example.com. → cdn.example.net.\n")
+	assert.Contains(t, string(logFile), "## www.example.com.\n\n| Type | TTL | Value |\n|------|-----|-------|\n| CNAME |")
+	assert.Contains(t, string(logFile), "## cdn.example.net.\n\n| Type | TTL | Value |\n|------|-----|-------|\n| A |")
+}
+
+func Test_Cmd_TTL_Raw(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
 
 	rootCmd := NewRootCommand()
-	rootCmd.SetArgs([]string{"example.com", "--debug", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--ttl", "raw", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
 
 	err = rootCmd.Execute()
 	assert.NoError(t, err)
 
-	assert.FileExists(t, file.Name())
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), "A   example.com.   60   93.184.216.34")
+}
+
+func Test_Cmd_TTL_Invalid(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--ttl", "seconds", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: invalid TTL format: seconds", err.Error())
+}
+
+func Test_Cmd_Filter(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--filter", "type=CNAME", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
 
 	logFile, err := os.ReadFile(file.Name())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assert.Contains(t, string(logFile), "Querying DNS server: @domain=example.com server=127.0.0.1:53535 domain=example.com qtype=A")
-	assert.Contains(t, string(logFile), "Querying DNS server: @domain=example.com server=127.0.0.1:53535 domain=example.com qtype=CNAME")
-	assert.Contains(t, string(logFile), "Received DNS response: @domain=example.com server=127.0.0.1:53535 domain=example.com qtype=A rcode=NOERROR")
-	assert.Contains(t, string(logFile), "Received DNS response: @domain=example.com server=127.0.0.1:53535 domain=example.com qtype=CNAME rcode=NOERROR")
-	assert.Contains(t, string(logFile), "A       |example.com.   |01m00s   |93.184.216.34")
-	assert.Contains(t, string(logFile), "CNAME   |example.com.   |01m00s   |example.org.")
+	// The chain is still reported, as it is built before the records are filtered.
+	want := "CNAME   example.com.   01m00s   example.org.\n" +
+		"CNAME chain: example.com. → example.org.\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_Wildcard(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	for domain, want := range map[string]string{
+		"www.wildcard.example.net":  "A   www.wildcard.example.net.   05m00s   192.0.2.80 *\n",
+		"mail.wildcard.example.net": "A   mail.wildcard.example.net.   05m00s   192.0.2.25\n",
+	} {
+		file, err := os.CreateTemp(t.TempDir(), "zns")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rootCmd := NewRootCommand()
+		rootCmd.SetArgs([]string{domain, "--output-file", file.Name(), "--query-type", "A", "--wildcard", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+		err = rootCmd.Execute()
+		assert.NoError(t, err)
+
+		logFile, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, want, string(logFile))
+	}
+}
+
+func Test_Cmd_Wildcard_JSON(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"www.wildcard.example.net", "--output-file", file.Name(), "--query-type", "A", "--wildcard", "--json", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(logFile), `"@wildcard":true`)
+}
+
+func Test_Cmd_Stats(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"mail.wildcard.example.net", "--output-file", file.Name(), "--query-type", "A", "--stats", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The round-trip time varies from run to run.
+	want := fmt.Sprintf(`^A   mail\.wildcard\.example\.net\.   05m00s   192\.0\.2\.25   127\.0\.0\.1:%d/UDP [\d.]+ms \d+B NOERROR qr[a-z ]*\n$`, DNSServerPort)
+	assert.Regexp(t, want, string(logFile))
+}
+
+func Test_Cmd_Stats_JSON(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"mail.wildcard.example.net", "--output-file", file.Name(), "--query-type", "A", "--json", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The metadata of the response is included without --stats.
+	assert.Contains(t, string(logFile), fmt.Sprintf(`"@server":"127.0.0.1:%d"`, DNSServerPort))
+	assert.Contains(t, string(logFile), `"@transport":"UDP"`)
+	assert.Contains(t, string(logFile), `"@rcode":"NOERROR"`)
+	assert.Contains(t, string(logFile), `"@rtt_ms":`)
+	assert.Contains(t, string(logFile), `"@size":`)
+	assert.Contains(t, string(logFile), `"@flags":["qr"`)
+}
+
+func Test_Cmd_Filter_Invalid(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--filter", "color=red", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, `error: invalid filter: unknown field "color"`, err.Error())
+}
+
+func Test_Cmd_Sort_Group(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--sort", "value", "--group", "type", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "A\n" +
+		"A   example.com.   01m00s   93.184.216.34\n" +
+		"\n" +
+		"CNAME\n" +
+		"CNAME   example.com.   01m00s   example.org.\n" +
+		"CNAME chain: example.com. → example.org.\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_Sort_Dig(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output", "dig", "--sort", "ttl", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: the --sort and --group flags are not supported by the dig view", err.Error())
+}
+
+func Test_Cmd_Sort_Report(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	for _, output := range []string{"json", "yaml", "markdown", "html"} {
+		rootCmd := NewRootCommand()
+		rootCmd.SetArgs([]string{"example.com", "--output", output, "--group", "type", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+		err := rootCmd.Execute()
+
+		assert.Error(t, err)
+		assert.Equal(t, fmt.Sprintf("error: the --sort and --group flags are not supported by the %s view", output), err.Error())
+	}
+}
+
+func Test_Cmd_Format(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--format", "{{.Type}} {{.Rdata.address}} {{.TTL}}", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "A 93.184.216.34 60\n", string(logFile))
+}
+
+func Test_Cmd_FormatFile(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	formatFile := filepath.Join(t.TempDir(), "format.tmpl")
+	if err := os.WriteFile(formatFile, []byte("{{.Name}}={{.Value}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--format-file", formatFile, "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "example.com.=93.184.216.34\n", string(logFile))
+}
+
+func Test_Cmd_Format_Invalid(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--format", "{{.Type", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error: invalid format template")
+}
+
+func Test_Cmd_Format_Execution_Error(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "-q", "A", "--format", "{{.Preference}}", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "error: failed to execute format template: template: format:1:2: executing"), err.Error())
+	assert.Contains(t, err.Error(), "can't evaluate field Preference")
+	assert.NotContains(t, err.Error(), "failed to write output")
+	assert.Equal(t, 1, strings.Count(err.Error(), "error:"))
+}
+
+func Test_Cmd_Output_Invalid(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output", "xml", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: invalid output format: xml", err.Error())
+}
+
+func Test_Cmd_QueryType(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err := rootCmd.Execute()
+
+	assert.NoError(t, err)
+}
+
+func Test_Cmd_Debug(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--debug", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err := rootCmd.Execute()
+
+	assert.NoError(t, err)
+}
+
+func Test_Cmd_OutputFile(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	assert.FileExists(t, file.Name())
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), "A       example.com.   01m00s   93.184.216.34")
+	assert.Contains(t, string(logFile), "CNAME   example.com.   01m00s   example.org.")
+}
+
+func Test_Cmd_LogFile_Debug(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	outputFile := filepath.Join(t.TempDir(), "output")
+
+	t.Setenv("ZNS_LOG_FILE", file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--debug", "--output-file", outputFile, "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	assert.FileExists(t, file.Name())
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), "Querying DNS server: @domain=example.com server=127.0.0.1:53535 domain=example.com qtype=A")
+	assert.Contains(t, string(logFile), "Querying DNS server: @domain=example.com server=127.0.0.1:53535 domain=example.com qtype=CNAME")
+	assert.Contains(t, string(logFile), "Received DNS response: @domain=example.com server=127.0.0.1:53535 domain=example.com qtype=A rcode=NOERROR")
+	assert.Contains(t, string(logFile), "Received DNS response: @domain=example.com server=127.0.0.1:53535 domain=example.com qtype=CNAME rcode=NOERROR")
+	assert.NotContains(t, string(logFile), "93.184.216.34")
+
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The results are written to the output file only, and are not mixed with the debug logs.
+	assert.Contains(t, string(output), "A       |example.com.   |01m00s   |93.184.216.34")
+	assert.Contains(t, string(output), "CNAME   |example.com.   |01m00s   |example.org.")
+	assert.NotContains(t, string(output), "Querying DNS server")
+}
+
+// Test_Cmd_JSON_Debug tests that debug logs do not end up in the JSON output, so it can be piped to other tools.
+func Test_Cmd_JSON_Debug(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("ZNS_LOG_FILE", filepath.Join(t.TempDir(), "zns.log"))
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--debug", "--output", "json", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	output, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]any
+	assert.NoError(t, encjson.Unmarshal(output, &doc))
+}
+
+func Test_Cmd_CNAMEChain(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"www.example.com", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, strings.Count(string(logFile), "CNAME   www.example.com.   01m00s   cdn.example.net."))
+	assert.Contains(t, string(logFile), "A       cdn.example.net.   01m00s   192.0.2.1")
+	assert.Contains(t, string(logFile), "CNAME chain: www.example.com. → cdn.example.net.")
+}
+
+func Test_Cmd_Watch(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--watch=50ms", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A"})
+
+	err := rootCmd.ExecuteContext(ctx)
+
+	assert.NoError(t, err)
+}
+
+func Test_Cmd_Exec_Without_Watch(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--exec", "true", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: the --exec flag requires --watch", err.Error())
+}
+
+func Test_Cmd_Config(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	config := fmt.Sprintf("server: 127.0.0.1:%d\nquery-type: CNAME\nttl: raw\n", DNSServerPort)
+	if err := os.MkdirAll(filepath.Join(dir, "zns"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "zns", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--output-file", file.Name()})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), "CNAME   example.com.   60   example.org.")
+}
+
+func Test_Cmd_QueryType_List(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A,SRV"})
+
+	err := rootCmd.Execute()
+
+	assert.NoError(t, err)
+}
+
+func Test_Cmd_QueryType_Invalid(t *testing.T) {
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort), "--query-type", "A,BOGUS"})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: invalid query type: BOGUS", err.Error())
+}
+
+func Test_Cmd_QueryType_Preset(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("presets:\n  apex: [A, CNAME]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--config", path, "-q", "apex", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "A       example.com.   01m00s   93.184.216.34\n" +
+		"CNAME   example.com.   01m00s   example.org.\n" +
+		"CNAME chain: example.com. → example.org.\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_Config_Profile(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	config := fmt.Sprintf("server: 192.0.2.1\noutput: csv\nprofiles:\n  test:\n    server: 127.0.0.1:%d\n    query-type: A\n", DNSServerPort)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	// The --json flag wins over the output setting of the config file, and the environment over the profile.
+	t.Setenv("ZNS_TTL", "raw")
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--config", path, "--profile", "test", "--json", "--output-file", file.Name()})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(logFile), `"@ttl":60`)
+	assert.Contains(t, string(logFile), `"@view":"json"`)
+	assert.NotContains(t, string(logFile), "CNAME")
+}
+
+func Test_Cmd_Config_UnknownProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("profiles:\n  internal:\n    server: 10.0.0.53\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--config", path, "--profile", "external"})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, `error: unknown profile "external" (available profiles: internal)`, err.Error())
+}
+
+func Test_Cmd_Config_UnknownSetting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("colour: never\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--config", path})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, fmt.Sprintf(`error: unknown setting "colour" in %s`, path), err.Error())
+}
+
+func Test_Cmd_Env_InvalidValue(t *testing.T) {
+	t.Setenv("ZNS_TIMEOUT", "soon")
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com"})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `error: invalid value "soon" for setting "timeout" in environment`)
+}
+
+func Test_Cmd_Transport_Invalid(t *testing.T) {
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"example.com", "--transport", "quic"})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: invalid transport: quic", err.Error())
+}
+
+func TestEnsureDNSAddressPort(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:853", EnsureDNSAddressPort("127.0.0.1", "853"))
+	assert.Equal(t, "[2001:558:feed::1]:853", EnsureDNSAddressPort("2001:558:feed::1", "853"))
+	assert.Equal(t, "127.0.0.1:53", EnsureDNSAddressPort("127.0.0.1:53", "853"))
 }
 
 func TestEnsureDNSAddress(t *testing.T) {
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/dns"
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/config"
+	"github.com/znscli/zns/internal/query"
+)
+
+// autoNoColor holds whether colors are disabled for the terminal zns runs in, as detected on startup.
+var autoNoColor = color.NoColor
+
+// authPort is the port authoritative servers are queried on, whatever the port and transport of --server.
+// Tests point it at their own DNS server.
+var authPort = "53"
+
+// transports maps the values of the --transport flag to the network of the DNS client, and the port used
+// when the server address does not include one.
+var transports = map[string]struct {
+	net  string
+	port string
+}{
+	"udp": {"udp", "53"},
+	"tcp": {"tcp", "53"},
+	"tls": {"tcp-tls", "853"},
+}
+
+// configure applies the settings of the config file and the environment to the flags that were not given,
+// and validates the flags shared by all commands. It returns the config file that was read, and the color
+// option for the logger.
+func configure(cmd *cobra.Command) (*config.Config, hclog.ColorOption, error) {
+	conf, err := applySettings(cmd)
+	if err != nil {
+		return nil, hclog.ColorOff, err
+	}
+
+	var logColor hclog.ColorOption
+	switch colorMode {
+	case "always":
+		noColor = false
+		logColor = hclog.ForceColor
+	case "never":
+		noColor = true
+		logColor = hclog.ColorOff
+	case "auto":
+		noColor = os.Getenv("NO_COLOR") != ""
+		logColor = hclog.AutoColor
+		if noColor {
+			logColor = hclog.ColorOff
+		}
+	default:
+		return nil, hclog.ColorOff, fmt.Errorf("error: invalid color mode: %s", colorMode)
+	}
+	color.NoColor = noColor || colorMode == "auto" && autoNoColor
+
+	if _, ok := transports[transport]; !ok {
+		return nil, hclog.ColorOff, fmt.Errorf("error: invalid transport: %s", transport)
+	}
+
+	return conf, logColor, nil
+}
+
+// openOutputs opens the streams results and logs are written to. Results are written to stdout, or to the
+// file named by --output-file. Logs are written to stderr, or to the file named by ZNS_LOG_FILE, so they
+// never end up in the results. The returned function closes the files that were opened.
+func openOutputs() (out io.Writer, logOut io.Writer, closeOutputs func(), err error) {
+	var files []*os.File
+	closeOutputs = func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	out = os.Stdout
+	if outputF != "" {
+		f, err := os.Create(outputF)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error: failed to create output file: %v", err)
+		}
+		files = append(files, f)
+		out = f
+	}
+
+	logOut = os.Stderr
+	if logFile := os.Getenv("ZNS_LOG_FILE"); logFile != "" {
+		f, err := os.Create(logFile)
+		if err != nil {
+			closeOutputs()
+			return nil, nil, nil, fmt.Errorf("error: failed to create log file: %v", err)
+		}
+		files = append(files, f)
+		logOut = f
+	}
+
+	return out, logOut, closeOutputs, nil
+}
+
+// newLogger creates the logger of a command about a domain. The log level is DEBUG with --debug,
+// and is otherwise taken from ZNS_LOG_LEVEL.
+func newLogger(logOut io.Writer, logColor hclog.ColorOption, jsonFormat bool, domain string) hclog.Logger {
+	logLevel := os.Getenv("ZNS_LOG_LEVEL")
+	if debug {
+		logLevel = "DEBUG"
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:                 "zns",
+		Output:               logOut,
+		Level:                hclog.LevelFromString(logLevel),
+		Color:                logColor,
+		ColorHeaderAndFields: !noColor,
+		DisableTime:          false,
+		JSONFormat:           jsonFormat,
+	}).With("@domain", domain)
+
+	logger.Debug("Debug logging enabled", "debug", debug)
+	logger.Debug("Log level", "level", logger.GetLevel())
+
+	return logger
+}
+
+// newQuerier creates a client for the DNS server given by --server, over the transport given by --transport.
+// Without --server, the first nameserver of the host is used.
+func newQuerier(logger hclog.Logger) (*query.QueryClient, error) {
+	// Resolve the DNS nameserver from the host.
+	// Supported only on Unix-like systems.
+	// On Windows, dynamic DNS resolution is not supported;
+	// a DNS nameserver must be explicitly specified using the --server flag.
+	if server == "" {
+		switch runtime.GOOS {
+		case "windows":
+			return nil, fmt.Errorf("error: host DNS nameserver resolution is not supported on Windows; please specify a DNS server using the --server flag")
+		default:
+			logger.Debug(fmt.Sprintf("Resolving DNS nameserver from \"%s\"", resolveConfPath), "path", resolveConfPath)
+
+			// Attempt to retrieve the DNS nameserver from `/etc/resolv.conf`.
+			conf, err := dns.ClientConfigFromFile(resolveConfPath)
+			if err != nil {
+				return nil, fmt.Errorf("error: failed to read %s: %v", resolveConfPath, err)
+			}
+
+			if len(conf.Servers) == 0 {
+				return nil, fmt.Errorf("error: no DNS nameservers found in %s", resolveConfPath)
+			}
+
+			server = conf.Servers[0] // Use the first available DNS nameserver.
+			logger.Debug(fmt.Sprintf("Using DNS nameserver %s", server), "server", server, "path", resolveConfPath)
+		}
+	}
+
+	querier := newServerQuerier(logger, server)
+	server = querier.Server
+
+	return querier, nil
+}
+
+// newServerQuerier creates a client for a DNS server, over the transport given by --transport.
+func newServerQuerier(logger hclog.Logger, address string) *query.QueryClient {
+	tp := transports[transport]
+	address = EnsureDNSAddressPort(address, tp.port)
+
+	querier := query.NewQueryClient(address, &dns.Client{Net: tp.net, Timeout: timeout}, logger)
+	querier.AuthPort = authPort
+
+	return querier
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/mail"
+	"github.com/znscli/zns/internal/view"
+)
+
+// newSPFCommand creates the spf command, which expands the SPF record of a domain.
+func newSPFCommand() *cobra.Command {
+	var (
+		ip     string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "spf <domain>",
+		Short: "Expand the SPF record of a domain and count its DNS lookups",
+		Long:  "Expand the SPF record of a domain. zns recursively looks up the include:, redirect=, a, mx and exists terms, renders the records as a tree and counts the terms that cause DNS lookups against the limit of 10 set by RFC 7208. With --ip, it also evaluates whether a server with that address may send email for the domain.",
+		Example: `
+  # Expand the SPF record of example.com
+  zns spf example.com
+
+  # Check whether a server may send email for example.com
+  zns spf example.com --ip 192.0.2.1
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			vt, err := arguments.ParseViewType(output)
+			if err != nil {
+				return err
+			}
+
+			var sender net.IP
+			if ip != "" {
+				if sender = net.ParseIP(ip); sender == nil {
+					return fmt.Errorf("error: invalid IP address: %s", ip)
+				}
+			}
+
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			w := bufio.NewWriter(out)
+			v, err := view.NewSPFRenderer(vt, view.NewView(w))
+			if err != nil {
+				return err
+			}
+
+			logger := newLogger(logOut, logColor, false, args[0])
+			logger.Debug("Flags", "server", server, "ip", ip, "debug", debug)
+
+			querier, err := newQuerier(logger)
+			if err != nil {
+				return err
+			}
+
+			record := mail.ExpandSPF(querier, args[0])
+			report := &mail.SPFReport{
+				Domain:      record.Domain,
+				Record:      record,
+				Lookups:     record.Lookups(),
+				LookupLimit: mail.SPFLookupLimit,
+			}
+			if sender != nil {
+				evaluation := record.Evaluate(sender)
+				report.Evaluation = &evaluation
+			}
+
+			if err := v.RenderSPF(report); err != nil {
+				return fmt.Errorf("error: failed to write output: %v", err)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&ip, "ip", "", "Evaluate the record for a sender with this IP address")
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json)")
+
+	return cmd
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cmd_SPF(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"spf", "example.net", "--ip", "198.51.100.25", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "example.net. v=spf1 include:_spf.example.org ~all\n" +
+		"├── include:_spf.example.org\n" +
+		"│   └── _spf.example.org. v=spf1 ip4:192.0.2.0/24 mx -all\n" +
+		"│       ├── ip4:192.0.2.0/24\n" +
+		"│       ├── mx → 198.51.100.25\n" +
+		"│       └── -all\n" +
+		"└── ~all\n" +
+		"\n" +
+		"2 DNS lookups, the limit is 10\n" +
+		"198.51.100.25: pass (mx in _spf.example.org.)\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_SPF_InvalidIP(t *testing.T) {
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"spf", "example.net", "--ip", "192.0.2", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: invalid IP address: 192.0.2", err.Error())
+}
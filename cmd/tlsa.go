@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/dane"
+	"github.com/znscli/zns/internal/view"
+)
+
+// newTLSACommand creates the tlsa command, which checks the TLSA records of a service against a certificate chain.
+func newTLSACommand() *cobra.Command {
+	var (
+		certFile string
+		output   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tlsa <name>",
+		Short: "Check the TLSA records of a service against a certificate chain",
+		Long:  "Check the TLSA records of a service, such as _25._tcp.mail.example.com, against a certificate chain in PEM format. zns reports which certificate of the chain each record matches, given its usage, selector and matching type, so DANE records can be validated before they are published. The chain is read from a local file, starting with the certificate of the server.",
+		Example: `
+  # Check the TLSA records of a mail server against its certificate chain
+  zns tlsa _25._tcp.mail.example.com --cert chain.pem
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			vt, err := arguments.ParseViewType(output)
+			if err != nil {
+				return err
+			}
+
+			if certFile == "" {
+				return fmt.Errorf("error: the --cert flag is required")
+			}
+			data, err := os.ReadFile(certFile)
+			if err != nil {
+				return fmt.Errorf("error: failed to read certificate file: %v", err)
+			}
+			chain, err := dane.ParseCertificates(data)
+			if err != nil {
+				return fmt.Errorf("error: failed to parse %s: %v", certFile, err)
+			}
+
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			w := view.NewTabWriter(out, debug)
+			v, err := view.NewTLSARenderer(vt, view.NewView(w))
+			if err != nil {
+				return err
+			}
+
+			logger := newLogger(logOut, logColor, false, args[0])
+			logger.Debug("Flags", "server", server, "cert", certFile, "debug", debug)
+
+			querier, err := newQuerier(logger)
+			if err != nil {
+				return err
+			}
+
+			report, err := dane.Check(querier, args[0], chain)
+			if err != nil {
+				return fmt.Errorf("error: failed to look up the TLSA records of %s: %v", args[0], err)
+			}
+
+			if err := v.RenderTLSA(report); err != nil {
+				return fmt.Errorf("error: failed to write output: %v", err)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&certFile, "cert", "", "File with the certificate chain in PEM format, starting with the certificate of the server")
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json)")
+
+	return cmd
+}
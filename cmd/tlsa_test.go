@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCertificate writes a self-signed certificate for mail.example.net to a PEM file. Its key is always the same,
+// so the TLSA record of the test server matches its SPKI.
+func writeCertificate(t *testing.T) string {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mail.example.net"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(nil, cert, cert, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "chain.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_Cmd_TLSA(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"tlsa", "_25._tcp.mail.example.net", "--cert", writeCertificate(t), "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "_25._tcp.mail.example.net.   3 1 1 fd110d301d2f077de1414b8f99f441b1403fab207b2052fbd2c065e4ee8e7dc2   DANE-EE SPKI SHA2-256   matches certificate 0 (CN=mail.example.net)\n" +
+		"_25._tcp.mail.example.net.   2 0 1 0000000000000000000000000000000000000000000000000000000000000000   DANE-TA Cert SHA2-256   no match\n" +
+		"\n" +
+		"1 of 2 records match the certificate chain\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_TLSA_MissingCert(t *testing.T) {
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"tlsa", "_25._tcp.mail.example.net", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "error: the --cert flag is required", err.Error())
+}
+
+func Test_Cmd_TLSA_InvalidCert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"tlsa", "_25._tcp.mail.example.net", "--cert", path, "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, fmt.Sprintf("error: failed to parse %s: no certificates found", path), err.Error())
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/view"
+	"github.com/znscli/zns/internal/walk"
+	"github.com/znscli/zns/internal/wordlist"
+)
+
+// newWalkCommand creates the walk command, which enumerates the names of a DNSSEC-signed zone.
+func newWalkCommand() *cobra.Command {
+	var (
+		wordlistF  string
+		output     string
+		maxQueries int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "walk <zone>",
+		Short: "Enumerate the names of a DNSSEC-signed zone from its NSEC or NSEC3 records",
+		Long:  "Enumerate the names of a DNSSEC-signed zone, to verify what it exposes. In a zone signed with NSEC, zns follows the chain of NSEC records from the apex, which lists every name of the zone and the types it owns. In a zone signed with NSEC3, the chain links hashes of the names instead: zns collects the hashes by querying names that do not exist, and with --wordlist cracks them offline by hashing <word>.<zone> for every word.",
+		Example: `
+  # Walk the NSEC chain of example.com
+  zns walk example.com
+
+  # Collect the NSEC3 hashes of example.com, and crack them against a wordlist
+  zns walk example.com --wordlist words.txt
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, logColor, err := configure(cmd)
+			if err != nil {
+				return err
+			}
+
+			vt, err := arguments.ParseViewType(output)
+			if err != nil {
+				return err
+			}
+			if maxQueries < 0 {
+				return fmt.Errorf("error: invalid maximum number of queries: %d", maxQueries)
+			}
+
+			var words []string
+			if wordlistF != "" {
+				f, err := os.Open(wordlistF)
+				if err != nil {
+					return fmt.Errorf("error: failed to read wordlist: %v", err)
+				}
+				words, err = wordlist.Read(f)
+				f.Close()
+				if err != nil {
+					return fmt.Errorf("error: failed to read wordlist: %v", err)
+				}
+			}
+
+			out, logOut, closeOutputs, err := openOutputs()
+			if err != nil {
+				return err
+			}
+			defer closeOutputs()
+
+			w := view.NewTabWriter(out, debug)
+			v, err := view.NewWalkRenderer(vt, view.NewView(w))
+			if err != nil {
+				return err
+			}
+
+			logger := newLogger(logOut, logColor, false, args[0])
+			logger.Debug("Flags", "server", server, "wordlist", wordlistF, "max-queries", maxQueries, "debug", debug)
+
+			querier, err := newQuerier(logger)
+			if err != nil {
+				return err
+			}
+
+			report, err := walk.Walk(querier, args[0], walk.Options{MaxQueries: maxQueries})
+			if err != nil {
+				return fmt.Errorf("error: failed to walk %s: %v", args[0], err)
+			}
+			if report.Method == walk.MethodNSEC3 {
+				report.Crack(words)
+			}
+
+			if err := v.RenderWalk(report); err != nil {
+				return fmt.Errorf("error: failed to write output: %v", err)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&wordlistF, "wordlist", "", "File with labels to crack NSEC3 hashes with, one per line")
+	cmd.Flags().StringVarP(&output, "output", "o", "human", "Output format (human, json)")
+	cmd.Flags().IntVar(&maxQueries, "max-queries", 10000, "Maximum number of queries to send (0 for no limit)")
+
+	return cmd
+}
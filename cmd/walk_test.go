@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cmd_Walk_NSEC(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"walk", "signed.example.net", "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "signed.example.net.        A NS SOA RRSIG NSEC DNSKEY\n" +
+		"mail.signed.example.net.   MX RRSIG NSEC\n" +
+		"www.signed.example.net.    A RRSIG NSEC\n" +
+		"\n" +
+		"3 names found by walking the NSEC chain\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_Walk_NSEC3(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	file, err := os.CreateTemp(t.TempDir(), "zns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	wordlist := writeWordlist(t, "www\nftp\n")
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"walk", "hashed.example.net", "--wordlist", wordlist, "--output-file", file.Name(), "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+
+	logFile, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "1RNTJ122PVIB99BECUKN83TM6AGQ5LES   A NS SOA RRSIG DNSKEY NSEC3PARAM   hashed.example.net.\n" +
+		"F7RC53CTANOG6VA6C8KUQDO61PTLU31C   A RRSIG                            www.hashed.example.net.\n" +
+		"IEEVK01CEQHD7QIFUCCSUU7BBN0U6FQE   MX RRSIG                           -\n" +
+		"\n" +
+		"3 hashes (SHA-1, 0 iterations, salt -), 2 cracked\n"
+	assert.Equal(t, want, string(logFile))
+}
+
+func Test_Cmd_Walk_Unsigned(t *testing.T) {
+	t.Setenv("NO_COLOR", "1") // Disable color codes for easier testing
+
+	rootCmd := NewRootCommand()
+	rootCmd.SetArgs([]string{"walk", "example.net", "--server", fmt.Sprintf("127.0.0.1:%d", DNSServerPort)})
+
+	err := rootCmd.Execute()
+	assert.EqualError(t, err, "error: failed to walk example.net: example.net. is not signed with NSEC or NSEC3")
+}
@@ -1,12 +1,24 @@
 package arguments
 
+import "fmt"
+
 // ViewType represents which view layer to use.
 type ViewType rune
 
 const (
-	ViewNone  ViewType = 0
-	ViewHuman ViewType = 'H'
-	ViewJSON  ViewType = 'J'
+	ViewNone         ViewType = 0
+	ViewHuman        ViewType = 'H'
+	ViewJSON         ViewType = 'J'
+	ViewJSONDocument ViewType = 'D'
+	ViewNDJSON       ViewType = 'N'
+	ViewZone         ViewType = 'Z'
+	ViewCSV          ViewType = 'C'
+	ViewTSV          ViewType = 'T'
+	ViewYAML         ViewType = 'Y'
+	ViewTemplate     ViewType = 'G'
+	ViewDig          ViewType = 'd'
+	ViewMarkdown     ViewType = 'M'
+	ViewHTML         ViewType = 'W'
 )
 
 func (vt ViewType) String() string {
@@ -16,8 +28,95 @@ func (vt ViewType) String() string {
 	case ViewHuman:
 		return "human"
 	case ViewJSON:
+		return "json-log"
+	case ViewJSONDocument:
 		return "json"
+	case ViewNDJSON:
+		return "ndjson"
+	case ViewZone:
+		return "zone"
+	case ViewCSV:
+		return "csv"
+	case ViewTSV:
+		return "tsv"
+	case ViewYAML:
+		return "yaml"
+	case ViewTemplate:
+		return "template"
+	case ViewDig:
+		return "dig"
+	case ViewMarkdown:
+		return "markdown"
+	case ViewHTML:
+		return "html"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseViewType returns the view type selected by the --output flag.
+func ParseViewType(s string) (ViewType, error) {
+	switch s {
+	case "human":
+		return ViewHuman, nil
+	case "json":
+		return ViewJSONDocument, nil
+	case "ndjson":
+		return ViewNDJSON, nil
+	case "zone":
+		return ViewZone, nil
+	case "csv":
+		return ViewCSV, nil
+	case "tsv":
+		return ViewTSV, nil
+	case "yaml":
+		return ViewYAML, nil
+	case "dig":
+		return ViewDig, nil
+	case "markdown":
+		return ViewMarkdown, nil
+	case "html":
+		return ViewHTML, nil
+	default:
+		return ViewNone, fmt.Errorf("error: invalid output format: %s", s)
+	}
+}
+
+// TTLFormat represents how TTLs are displayed.
+type TTLFormat rune
+
+const (
+	TTLNone   TTLFormat = 0
+	TTLHuman  TTLFormat = 'H'
+	TTLRaw    TTLFormat = 'R'
+	TTLExpiry TTLFormat = 'E'
+)
+
+func (tf TTLFormat) String() string {
+	switch tf {
+	case TTLNone:
+		return "none"
+	case TTLHuman:
+		return "human"
+	case TTLRaw:
+		return "raw"
+	case TTLExpiry:
+		return "expiry"
 	default:
 		return "unknown"
 	}
 }
+
+// ParseTTLFormat returns the TTL format selected by the --ttl flag.
+func ParseTTLFormat(s string) (TTLFormat, error) {
+	switch s {
+	case "human":
+		return TTLHuman, nil
+	case "raw":
+		return TTLRaw, nil
+	case "expiry":
+		return TTLExpiry, nil
+	default:
+		return TTLNone, fmt.Errorf("error: invalid TTL format: %s", s)
+	}
+}
@@ -0,0 +1,234 @@
+// Package bench benchmarks DNS servers, by sending them queries at a target rate and aggregating the round-trip
+// times, timeouts and response codes of the responses.
+package bench
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// Querier sends single queries to a DNS server, as the query client does.
+type Querier interface {
+	Query(domain string, qtype uint16) (*query.Response, error)
+}
+
+// Options configures a benchmark.
+type Options struct {
+	// Questions are the questions asked, in turn, until Count queries were sent.
+	Questions []query.Question
+
+	// Count is the number of queries sent to each server.
+	Count int
+
+	// QPS is the target number of queries sent per second. Queries are sent at this rate whether or not earlier
+	// queries were answered, so slow servers do not lower the load they are put under. A rate above one query
+	// per nanosecond is too high for the ticker that paces the queries, and they are sent at once.
+	QPS int
+}
+
+// Latency summarizes the round-trip times of the answered queries, in milliseconds.
+type Latency struct {
+	Min  float64 `json:"min_ms"`
+	Mean float64 `json:"mean_ms"`
+	P50  float64 `json:"p50_ms"`
+	P90  float64 `json:"p90_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+}
+
+// Result is the outcome of benchmarking one server.
+type Result struct {
+	Server string `json:"server"`
+
+	Sent     int `json:"sent"`
+	Answered int `json:"answered"`
+	Timeouts int `json:"timeouts"`
+	Errors   int `json:"errors"`
+
+	// Rcodes counts the answered queries by response code.
+	Rcodes map[string]int `json:"rcodes"`
+
+	Latency Latency `json:"latency"`
+
+	// QPS is the rate the queries were actually sent at.
+	QPS float64 `json:"qps"`
+
+	// Repeated is the number of answered queries for a question that was asked before, which the server may
+	// answer from its cache. CacheHits is the number of them that were estimated to be, see Run.
+	Repeated  int `json:"repeated"`
+	CacheHits int `json:"cache_hits"`
+}
+
+// CacheHitRate returns the share of the repeated queries that were estimated to be answered from the cache.
+func (r Result) CacheHitRate() float64 {
+	if r.Repeated == 0 {
+		return 0
+	}
+	return float64(r.CacheHits) / float64(r.Repeated)
+}
+
+// Report holds the results of a benchmark, one per server.
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// sample is the outcome of a single query.
+type sample struct {
+	question query.Question
+	resp     *query.Response
+	err      error
+}
+
+// ReadDomains reads the domains to ask about, one per line. Blank lines and lines starting with # are skipped.
+// Domains are kept as written, and a domain listed twice is asked about twice as often.
+func ReadDomains(r io.Reader) ([]string, error) {
+	var domains []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		domain := strings.TrimSpace(scanner.Text())
+		if domain == "" || strings.HasPrefix(domain, "#") {
+			continue
+		}
+		domains = append(domains, domain)
+	}
+	return domains, scanner.Err()
+}
+
+// Run benchmarks a server, by sending it Count queries at the target rate, asking each question in turn.
+//
+// Whether a query was answered from the cache of the server is estimated from the first answer to its question:
+// a later answer is counted as a cache hit if its TTL is lower, as records age in a cache, or if it took less than
+// half the time, as a cache is faster than asking the authoritative servers.
+func Run(q Querier, server string, opts Options) Result {
+	samples := make([]sample, opts.Count)
+
+	var wg sync.WaitGroup
+	var limit <-chan time.Time
+	if interval := time.Second / time.Duration(max(opts.QPS, 1)); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		limit = ticker.C
+	}
+
+	start := time.Now()
+	for i := range opts.Count {
+		if i > 0 && limit != nil {
+			<-limit
+		}
+		question := opts.Questions[i%len(opts.Questions)]
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := q.Query(question.Name, question.Qtype)
+			samples[i] = sample{question: question, resp: resp, err: err}
+		}(i)
+	}
+	elapsed := time.Since(start)
+	wg.Wait()
+
+	return summarize(server, samples, elapsed)
+}
+
+// summarize aggregates the samples of a server, in the order their queries were sent.
+func summarize(server string, samples []sample, elapsed time.Duration) Result {
+	result := Result{Server: server, Sent: len(samples), Rcodes: make(map[string]int)}
+	if len(samples) > 1 && elapsed > 0 {
+		// The rate is measured between the first and the last query.
+		result.QPS = float64(len(samples)-1) / elapsed.Seconds()
+	}
+
+	var rtts []time.Duration
+	first := make(map[query.Question]*query.Response)
+	for _, s := range samples {
+		var netErr net.Error
+		switch {
+		case errors.As(s.err, &netErr) && netErr.Timeout():
+			result.Timeouts++
+			continue
+		case s.err != nil:
+			result.Errors++
+			continue
+		}
+
+		result.Answered++
+		result.Rcodes[dns.RcodeToString[s.resp.Rcode]]++
+		rtts = append(rtts, s.resp.RTT)
+
+		baseline, ok := first[s.question]
+		if !ok {
+			first[s.question] = s.resp
+			continue
+		}
+		result.Repeated++
+		if cacheHit(baseline, s.resp) {
+			result.CacheHits++
+		}
+	}
+	result.Latency = latency(rtts)
+
+	return result
+}
+
+// cacheHit estimates whether a response was answered from the cache, given the first response to its question.
+func cacheHit(baseline, resp *query.Response) bool {
+	if ttl, ok := minTTL(resp); ok {
+		if baselineTTL, ok := minTTL(baseline); ok && ttl < baselineTTL {
+			return true
+		}
+	}
+	return resp.RTT < baseline.RTT/2
+}
+
+// minTTL returns the lowest TTL of the answers of a response, and whether it has any.
+func minTTL(resp *query.Response) (uint32, bool) {
+	if len(resp.Answer) == 0 {
+		return 0, false
+	}
+	ttl := uint32(math.MaxUint32)
+	for _, rr := range resp.Answer {
+		ttl = min(ttl, rr.Header().Ttl)
+	}
+	return ttl, true
+}
+
+// latency summarizes round-trip times. Percentiles use the nearest-rank method.
+func latency(rtts []time.Duration) Latency {
+	if len(rtts) == 0 {
+		return Latency{}
+	}
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+
+	var total time.Duration
+	for _, rtt := range rtts {
+		total += rtt
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(rtts))))
+		return milliseconds(rtts[max(rank, 1)-1])
+	}
+
+	return Latency{
+		Min:  milliseconds(rtts[0]),
+		Mean: milliseconds(total / time.Duration(len(rtts))),
+		P50:  percentile(50),
+		P90:  percentile(90),
+		P99:  percentile(99),
+		Max:  milliseconds(rtts[len(rtts)-1]),
+	}
+}
+
+// milliseconds converts a duration to milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
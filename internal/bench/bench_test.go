@@ -0,0 +1,152 @@
+package bench
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/query"
+)
+
+// timeoutError is a network error that timed out, as returned by the DNS client.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+// fakeQuerier answers every query for example.com with an A record, and every other query with NXDOMAIN.
+// The nth query takes rtts[n] and has an answer with TTL ttls[n], if set. Queries for timeout.example.com
+// time out, and queries for error.example.com fail.
+type fakeQuerier struct {
+	rtts []time.Duration
+	ttls []uint32
+
+	mu sync.Mutex
+	n  int
+}
+
+func (q *fakeQuerier) Query(domain string, qtype uint16) (*query.Response, error) {
+	q.mu.Lock()
+	n := q.n
+	q.n++
+	q.mu.Unlock()
+
+	switch domain {
+	case "timeout.example.com":
+		return nil, timeoutError{}
+	case "error.example.com":
+		return nil, errors.New("connection refused")
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(domain), qtype)
+	msg.Response = true
+	if domain == "example.com" {
+		ttl := uint32(300)
+		if n < len(q.ttls) {
+			ttl = q.ttls[n]
+		}
+		msg.Answer = append(msg.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+			A:   net.IPv4(192, 0, 2, 1),
+		})
+	} else {
+		msg.Rcode = dns.RcodeNameError
+	}
+
+	rtt := time.Millisecond
+	if n < len(q.rtts) {
+		rtt = q.rtts[n]
+	}
+	return &query.Response{Msg: msg, RTT: rtt}, nil
+}
+
+func TestReadDomains(t *testing.T) {
+	domains, err := ReadDomains(strings.NewReader("example.com\n\n# comment\n  Example.ORG \nexample.com\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "Example.ORG", "example.com"}, domains)
+}
+
+func TestRun(t *testing.T) {
+	q := &fakeQuerier{}
+	opts := Options{
+		Questions: []query.Question{
+			{Name: "example.com", Qtype: dns.TypeA},
+			{Name: "missing.example.com", Qtype: dns.TypeA},
+			{Name: "timeout.example.com", Qtype: dns.TypeA},
+			{Name: "error.example.com", Qtype: dns.TypeA},
+		},
+		Count: 10,
+		QPS:   1000,
+	}
+
+	result := Run(q, "192.0.2.53:53", opts)
+
+	assert.Equal(t, "192.0.2.53:53", result.Server)
+	assert.Equal(t, 10, result.Sent)
+	assert.Equal(t, 6, result.Answered)
+	assert.Equal(t, 2, result.Timeouts)
+	assert.Equal(t, 2, result.Errors)
+	assert.Equal(t, map[string]int{"NOERROR": 3, "NXDOMAIN": 3}, result.Rcodes)
+	assert.Equal(t, Latency{Min: 1, Mean: 1, P50: 1, P90: 1, P99: 1, Max: 1}, result.Latency)
+	// The questions that were answered were each asked three times.
+	assert.Equal(t, 4, result.Repeated)
+	assert.Greater(t, result.QPS, 0.0)
+}
+
+func TestRun_Rate(t *testing.T) {
+	start := time.Now()
+	result := Run(&fakeQuerier{}, "192.0.2.53:53", Options{Questions: []query.Question{{Name: "example.com", Qtype: dns.TypeA}}, Count: 5, QPS: 100})
+
+	// Five queries at 100 per second are sent over at least 40ms.
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	assert.LessOrEqual(t, result.QPS, 110.0)
+}
+
+func TestRun_RateUnlimited(t *testing.T) {
+	// A rate above one query per nanosecond cannot be paced, and must not panic.
+	result := Run(&fakeQuerier{}, "192.0.2.53:53", Options{Questions: []query.Question{{Name: "example.com", Qtype: dns.TypeA}}, Count: 5, QPS: 2_000_000_000})
+
+	assert.Equal(t, 5, result.Sent)
+}
+
+func TestSummarize_CacheHits(t *testing.T) {
+	q := &fakeQuerier{
+		rtts: []time.Duration{20 * time.Millisecond, 2 * time.Millisecond, 15 * time.Millisecond, 18 * time.Millisecond},
+		ttls: []uint32{300, 300, 299, 300},
+	}
+	question := query.Question{Name: "example.com", Qtype: dns.TypeA}
+
+	var samples []sample
+	for range 4 {
+		resp, err := q.Query(question.Name, question.Qtype)
+		samples = append(samples, sample{question: question, resp: resp, err: err})
+	}
+	result := summarize("192.0.2.53:53", samples, time.Second)
+
+	// The second answer is fast, the third has a lower TTL, and the fourth is neither.
+	assert.Equal(t, 3, result.Repeated)
+	assert.Equal(t, 2, result.CacheHits)
+	assert.InDelta(t, 2.0/3, result.CacheHitRate(), 0.001)
+	assert.Equal(t, 3.0, result.QPS)
+}
+
+func TestLatency(t *testing.T) {
+	var rtts []time.Duration
+	for i := 100; i >= 1; i-- {
+		rtts = append(rtts, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, Latency{Min: 1, Mean: 50.5, P50: 50, P90: 90, P99: 99, Max: 100}, latency(rtts))
+	assert.Equal(t, Latency{}, latency(nil))
+	assert.Equal(t, 0.0, Result{}.CacheHitRate())
+}
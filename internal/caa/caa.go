@@ -0,0 +1,188 @@
+// Package caa evaluates the CAA records of a domain, which restrict the certificate authorities
+// that may issue certificates for it, see RFC 8659.
+package caa
+
+import (
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Resolver looks up records, as needed to find the CAA records of a domain.
+type Resolver interface {
+	// Lookup returns the records of a type owned by a name. A name that does not exist has no records, and is not an error.
+	Lookup(name string, qtype uint16) ([]dns.RR, error)
+}
+
+// Tags defined for CAA properties. Issuance is forbidden by critical properties with any other tag.
+const (
+	TagIssue        = "issue"
+	TagIssueWild    = "issuewild"
+	TagIodef        = "iodef"
+	TagIssueMail    = "issuemail"
+	TagContactEmail = "contactemail"
+	TagContactPhone = "contactphone"
+)
+
+// knownTags holds the tags zns understands, the ones from RFC 8659 and those registered since.
+var knownTags = map[string]bool{
+	TagIssue: true, TagIssueWild: true, TagIodef: true, TagIssueMail: true, TagContactEmail: true, TagContactPhone: true,
+}
+
+// Property is a single CAA record.
+type Property struct {
+	Owner string `json:"owner"`
+	Flag  uint8  `json:"flag"`
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// Critical reports whether the issuer critical flag is set. CAs must not issue if they do not understand a critical property.
+func (p Property) Critical() bool {
+	return p.Flag&128 != 0
+}
+
+// Issuer returns the domain of the CA an issue or issuewild property authorizes, without the parameters that may follow it.
+// It is empty for properties such as `0 issue ";"`, which authorize no CA.
+func (p Property) Issuer() string {
+	issuer, _, _ := strings.Cut(p.Value, ";")
+	return normalize(issuer)
+}
+
+// normalize returns a domain in lower case, without spaces and the trailing dot, for comparing issuers.
+func normalize(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// Decision describes which CAs may issue certificates for a name.
+type Decision struct {
+	Name string `json:"name"`
+
+	// Any is set if any CA may issue.
+	Any bool `json:"any"`
+
+	// Issuers holds the CAs that may issue. It is empty if Any is set, or if no CA may issue.
+	Issuers []string `json:"issuers"`
+
+	// Allowed reports whether the CA given to Evaluate may issue. It is nil if no CA was given.
+	Allowed *bool `json:"allowed,omitempty"`
+
+	// Reason describes what decided, e.g. the issuewild properties, or the absence of CAA records.
+	Reason string `json:"reason"`
+}
+
+// Report is the result of evaluating the CAA records relevant to a domain.
+type Report struct {
+	Domain string `json:"domain"`
+	CA     string `json:"ca,omitempty"`
+
+	// Checked holds the names looked up while climbing the tree, ending with the one that has CAA records, if any.
+	Checked []string `json:"checked"`
+
+	// Owner is the name whose CAA records are relevant to the domain. It is empty if no name has any.
+	Owner string `json:"owner,omitempty"`
+
+	Properties []Property `json:"properties"`
+
+	// Iodef holds the URLs CAs may report refused certificate requests to.
+	Iodef []string `json:"iodef"`
+
+	// Issue and Wildcard decide the issuance of certificates for the domain and for wildcards below it.
+	Issue    Decision `json:"issue"`
+	Wildcard Decision `json:"wildcard"`
+}
+
+// Evaluate finds the CAA records relevant to a domain, climbing the tree from the domain up to, but not including,
+// the root until a name has CAA records, as CAs do. It then decides which CAs may issue certificates for the domain,
+// and for wildcards below it. If ca is not empty, it also decides whether that CA may issue.
+// Lookup errors are returned, as CAs must not issue if the CAA records cannot be looked up.
+func Evaluate(r Resolver, domain, ca string) (*Report, error) {
+	domain = dns.Fqdn(strings.TrimPrefix(domain, "*."))
+	report := &Report{Domain: domain, CA: normalize(ca), Checked: []string{}, Properties: []Property{}, Iodef: []string{}}
+
+	for name := domain; name != "."; {
+		report.Checked = append(report.Checked, name)
+
+		rrs, err := r.Lookup(name, dns.TypeCAA)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range rrs {
+			caa := rr.(*dns.CAA)
+			report.Properties = append(report.Properties, Property{Owner: name, Flag: caa.Flag, Tag: strings.ToLower(caa.Tag), Value: caa.Value})
+		}
+		if len(report.Properties) > 0 {
+			report.Owner = name
+			break
+		}
+
+		_, parent, _ := strings.Cut(name, ".")
+		name = dns.Fqdn(parent)
+	}
+
+	for _, p := range report.Properties {
+		if p.Tag == TagIodef {
+			report.Iodef = append(report.Iodef, p.Value)
+		}
+	}
+
+	report.Issue = decide(domain, report.Properties, report.CA, false)
+	report.Wildcard = decide("*."+domain, report.Properties, report.CA, true)
+	return report, nil
+}
+
+// decide decides which CAs may issue certificates for a name, given the relevant CAA properties, see RFC 8659, section 4.
+// Wildcard certificates are governed by the issuewild properties if there are any, and by the issue properties otherwise.
+func decide(name string, properties []Property, ca string, wildcard bool) Decision {
+	d := Decision{Name: name, Issuers: []string{}}
+
+	var issue, issuewild []Property
+	for _, p := range properties {
+		switch {
+		case p.Tag == TagIssue:
+			issue = append(issue, p)
+		case p.Tag == TagIssueWild:
+			issuewild = append(issuewild, p)
+		case p.Critical() && !knownTags[p.Tag]:
+			d.Reason = "unknown critical property " + p.Tag
+		}
+	}
+
+	relevant, tag := issue, TagIssue
+	if wildcard && len(issuewild) > 0 {
+		relevant, tag = issuewild, TagIssueWild
+	}
+
+	switch {
+	case d.Reason != "":
+		// An unknown critical property forbids issuance by any CA.
+	case len(properties) == 0:
+		d.Any, d.Reason = true, "no CAA records"
+	case len(relevant) == 0:
+		d.Any, d.Reason = true, "no issue properties"
+	default:
+		d.Reason = tag + " properties"
+		seen := make(map[string]bool)
+		for _, p := range relevant {
+			if issuer := p.Issuer(); issuer != "" && !seen[issuer] {
+				seen[issuer] = true
+				d.Issuers = append(d.Issuers, issuer)
+			}
+		}
+	}
+
+	if ca != "" {
+		allowed := d.Any || contains(d.Issuers, ca)
+		d.Allowed = &allowed
+	}
+	return d
+}
+
+func contains(issuers []string, ca string) bool {
+	for _, issuer := range issuers {
+		if issuer == ca {
+			return true
+		}
+	}
+	return false
+}
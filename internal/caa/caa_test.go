@@ -0,0 +1,102 @@
+package caa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/dnstest"
+)
+
+func TestProperty_Issuer(t *testing.T) {
+	assert.Equal(t, "letsencrypt.org", Property{Tag: TagIssue, Value: "letsencrypt.org"}.Issuer())
+	assert.Equal(t, "letsencrypt.org", Property{Tag: TagIssue, Value: " LetsEncrypt.org. ; validationmethods=dns-01"}.Issuer())
+	assert.Equal(t, "", Property{Tag: TagIssue, Value: ";"}.Issuer())
+}
+
+func TestEvaluate(t *testing.T) {
+	r := dnstest.NewZone(t,
+		`example.com. 300 IN CAA 0 issue "letsencrypt.org"`,
+		`example.com. 300 IN CAA 0 issue "pki.goog; cansignhttpexchanges=yes"`,
+		`example.com. 300 IN CAA 0 issuewild ";"`,
+		`example.com. 300 IN CAA 0 iodef "mailto:security@example.com"`,
+	)
+
+	report, err := Evaluate(r, "www.example.com", "LetsEncrypt.org")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"www.example.com.", "example.com."}, report.Checked)
+	assert.Equal(t, []string{"www.example.com.", "example.com."}, r.Lookups())
+	assert.Equal(t, "example.com.", report.Owner)
+	assert.Len(t, report.Properties, 4)
+	assert.Equal(t, []string{"mailto:security@example.com"}, report.Iodef)
+
+	allowed, denied := true, false
+	assert.Equal(t, Decision{Name: "www.example.com.", Issuers: []string{"letsencrypt.org", "pki.goog"}, Allowed: &allowed, Reason: "issue properties"}, report.Issue)
+	assert.Equal(t, Decision{Name: "*.www.example.com.", Issuers: []string{}, Allowed: &denied, Reason: "issuewild properties"}, report.Wildcard)
+}
+
+func TestEvaluate_ClosestName(t *testing.T) {
+	r := dnstest.NewZone(t,
+		`www.example.com. 300 IN CAA 0 issue "digicert.com"`,
+		`example.com. 300 IN CAA 0 issue "letsencrypt.org"`,
+	)
+
+	report, err := Evaluate(r, "www.example.com", "letsencrypt.org")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "www.example.com.", report.Owner)
+	assert.Equal(t, []string{"digicert.com"}, report.Issue.Issuers)
+	assert.False(t, *report.Issue.Allowed)
+	// Without issuewild properties, wildcards are governed by the issue properties.
+	assert.Equal(t, "issue properties", report.Wildcard.Reason)
+	assert.False(t, *report.Wildcard.Allowed)
+}
+
+func TestEvaluate_NoRecords(t *testing.T) {
+	r := dnstest.NewZone(t)
+
+	report, err := Evaluate(r, "*.www.example.com", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "www.example.com.", report.Domain)
+	assert.Equal(t, []string{"www.example.com.", "example.com.", "com."}, report.Checked)
+	assert.Empty(t, report.Owner)
+	assert.Equal(t, Decision{Name: "www.example.com.", Any: true, Issuers: []string{}, Reason: "no CAA records"}, report.Issue)
+	assert.True(t, report.Wildcard.Any)
+}
+
+func TestEvaluate_IodefOnly(t *testing.T) {
+	r := dnstest.NewZone(t, `example.com. 300 IN CAA 0 iodef "mailto:security@example.com"`)
+
+	report, err := Evaluate(r, "example.com", "letsencrypt.org")
+
+	assert.NoError(t, err)
+	assert.True(t, report.Issue.Any)
+	assert.Equal(t, "no issue properties", report.Issue.Reason)
+	assert.True(t, *report.Issue.Allowed)
+}
+
+func TestEvaluate_UnknownCritical(t *testing.T) {
+	r := dnstest.NewZone(t,
+		`example.com. 300 IN CAA 0 issue "letsencrypt.org"`,
+		`example.com. 300 IN CAA 128 tbs "unknown"`,
+		`example.com. 300 IN CAA 0 future "ignored"`,
+	)
+
+	report, err := Evaluate(r, "example.com", "letsencrypt.org")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "unknown critical property tbs", report.Issue.Reason)
+	assert.Empty(t, report.Issue.Issuers)
+	assert.False(t, *report.Issue.Allowed)
+	assert.False(t, *report.Wildcard.Allowed)
+}
+
+func TestEvaluate_Error(t *testing.T) {
+	r := dnstest.NewZone(t)
+	r.Errs["example.com."] = true
+
+	_, err := Evaluate(r, "www.example.com", "letsencrypt.org")
+
+	assert.EqualError(t, err, "SERVFAIL looking up CAA example.com.")
+}
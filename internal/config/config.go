@@ -0,0 +1,163 @@
+// Package config reads the zns configuration file, which holds default values for command-line flags
+// along with named profiles that override them.
+//
+// The file is YAML, keyed by flag name:
+//
+//	server: 1.1.1.1
+//	output: human
+//	presets:
+//	  infra: [NS, SOA, _dmarc TXT]
+//	profiles:
+//	  internal:
+//	    server: 10.0.0.53
+//	    transport: tcp
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfilesKey is the key of the file's profiles section.
+const ProfilesKey = "profiles"
+
+// PresetsKey is the key of the file's query type presets section.
+const PresetsKey = "presets"
+
+// Config holds the settings read from a configuration file.
+type Config struct {
+	// Defaults holds the settings that apply to every run, keyed by flag name.
+	Defaults map[string]string
+
+	// Profiles holds the named profiles, each holding settings that override the defaults.
+	Profiles map[string]map[string]string
+
+	// Presets holds the user-defined query type presets, each a list of entries accepted by the -q flag.
+	Presets map[string][]string
+}
+
+// Path returns the default location of the configuration file, $XDG_CONFIG_HOME/zns/config.yaml.
+// If XDG_CONFIG_HOME is not set, it defaults to ~/.config.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "zns", "config.yaml"), nil
+}
+
+// Load reads a configuration file. A missing file yields an empty configuration if optional is set.
+func Load(path string, optional bool) (*Config, error) {
+	c := &Config{
+		Defaults: make(map[string]string),
+		Profiles: make(map[string]map[string]string),
+		Presets:  make(map[string][]string),
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && optional {
+		return c, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error: failed to read config file: %v", err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("error: failed to parse config file %s: %v", path, err)
+	}
+
+	for key, value := range raw {
+		if key == PresetsKey {
+			presets, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("error: invalid setting %q in %s: expected a map of presets", key, path)
+			}
+			for name, entries := range presets {
+				s, err := setting(entries)
+				if err != nil {
+					return nil, fmt.Errorf("error: invalid preset %q in %s: %v", name, path, err)
+				}
+				for _, entry := range strings.Split(s, ",") {
+					c.Presets[strings.ToLower(name)] = append(c.Presets[strings.ToLower(name)], strings.TrimSpace(entry))
+				}
+			}
+			continue
+		}
+		if key != ProfilesKey {
+			if c.Defaults[key], err = setting(value); err != nil {
+				return nil, fmt.Errorf("error: invalid setting %q in %s: %v", key, path, err)
+			}
+			continue
+		}
+
+		profiles, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("error: invalid setting %q in %s: expected a map of profiles", key, path)
+		}
+		for name, settings := range profiles {
+			m, ok := settings.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("error: invalid profile %q in %s: expected a map of settings", name, path)
+			}
+			c.Profiles[name] = make(map[string]string)
+			for k, v := range m {
+				if c.Profiles[name][k], err = setting(v); err != nil {
+					return nil, fmt.Errorf("error: invalid setting %q of profile %q in %s: %v", k, name, path, err)
+				}
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// Profile returns the settings of a profile, or an error listing the available profiles if it does not exist.
+func (c *Config) Profile(name string) (map[string]string, error) {
+	settings, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("error: unknown profile %q (available profiles: %s)", name, strings.Join(names, ", "))
+	}
+	return settings, nil
+}
+
+// setting converts a YAML value into its flag value. Lists are joined with commas.
+func setting(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			s, err := setting(item)
+			if err != nil {
+				return "", err
+			}
+			values[i] = s
+		}
+		return strings.Join(values, ","), nil
+	case map[string]any:
+		return "", fmt.Errorf("expected a value or a list of values")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// EnvName returns the name of the environment variable that sets a flag, e.g. ZNS_QUERY_TYPE for --query-type.
+func EnvName(flag string) string {
+	return "ZNS_" + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
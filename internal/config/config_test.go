@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `server: 1.1.1.1
+timeout: 5s
+debug: true
+query-type: [A, MX]
+presets:
+  Infra: [NS, SOA, _dmarc TXT]
+  apex: A, AAAA
+profiles:
+  internal:
+    server: 10.0.0.53
+    transport: tcp
+`)
+
+	c, err := Load(path, false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"server": "1.1.1.1", "timeout": "5s", "debug": "true", "query-type": "A,MX"}, c.Defaults)
+
+	assert.Equal(t, map[string][]string{"infra": {"NS", "SOA", "_dmarc TXT"}, "apex": {"A", "AAAA"}}, c.Presets)
+
+	settings, err := c.Profile("internal")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"server": "10.0.0.53", "transport": "tcp"}, settings)
+}
+
+func TestLoad_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	c, err := Load(path, true)
+	assert.NoError(t, err)
+	assert.Empty(t, c.Defaults)
+	assert.Empty(t, c.Profiles)
+
+	_, err = Load(path, false)
+	assert.Error(t, err)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(writeConfig(t, "server: [1.1.1.1\n"), false)
+	assert.ErrorContains(t, err, "error: failed to parse config file")
+
+	path := writeConfig(t, "server:\n  primary: 1.1.1.1\n")
+	_, err = Load(path, false)
+	assert.EqualError(t, err, `error: invalid setting "server" in `+path+`: expected a value or a list of values`)
+
+	path = writeConfig(t, "profiles:\n  internal: 10.0.0.53\n")
+	_, err = Load(path, false)
+	assert.EqualError(t, err, `error: invalid profile "internal" in `+path+`: expected a map of settings`)
+}
+
+func TestProfile_Unknown(t *testing.T) {
+	c := &Config{Profiles: map[string]map[string]string{"internal": {}, "external": {}}}
+
+	_, err := c.Profile("staging")
+	assert.EqualError(t, err, `error: unknown profile "staging" (available profiles: external, internal)`)
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/etc/xdg")
+
+	path, err := Path()
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/xdg/zns/config.yaml", path)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "ZNS_SERVER", EnvName("server"))
+	assert.Equal(t, "ZNS_QUERY_TYPE", EnvName("query-type"))
+	assert.Equal(t, "ZNS_FORMAT_FILE", EnvName("format-file"))
+}
@@ -0,0 +1,161 @@
+// Package dane checks TLSA records against a certificate chain, see RFC 6698 and RFC 7671.
+package dane
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Resolver looks up records, as needed to find the TLSA records of a service.
+type Resolver interface {
+	// Lookup returns the records of a type owned by a name. A name that does not exist has no records, and is not an error.
+	Lookup(name string, qtype uint16) ([]dns.RR, error)
+}
+
+// Certificate usages of TLSA records.
+const (
+	UsagePKIXTA uint8 = 0
+	UsagePKIXEE uint8 = 1
+	UsageDANETA uint8 = 2
+	UsageDANEEE uint8 = 3
+)
+
+// The acronyms of the usages, selectors and matching types of TLSA records, see RFC 7218.
+var (
+	usages        = []string{"PKIX-TA", "PKIX-EE", "DANE-TA", "DANE-EE"}
+	selectors     = []string{"Cert", "SPKI"}
+	matchingTypes = []string{"Full", "SHA2-256", "SHA2-512"}
+)
+
+// acronym returns the acronym of a TLSA field, or the number itself if it has none.
+func acronym(names []string, value uint8) string {
+	if int(value) < len(names) {
+		return names[value]
+	}
+	return fmt.Sprint(value)
+}
+
+// ParseCertificates parses the certificates of a chain in PEM format. The first certificate is the one of the
+// server, and is followed by the certificates of the CAs that issued it. Blocks other than certificates are skipped.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %v", len(chain), err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+	return chain, nil
+}
+
+// Certificate summarizes a certificate of the chain.
+type Certificate struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"not_after"`
+}
+
+// Result is a TLSA record, along with the certificate of the chain it matches.
+type Result struct {
+	Usage        uint8  `json:"usage"`
+	Selector     uint8  `json:"selector"`
+	MatchingType uint8  `json:"matching_type"`
+	Data         string `json:"data"`
+
+	// Certificate is the index in the chain of the certificate the record matches. It is nil if the record matches none.
+	Certificate *int `json:"certificate,omitempty"`
+
+	// Error describes why the record cannot match any certificate, e.g. because its usage is unknown.
+	Error string `json:"error,omitempty"`
+}
+
+// Description returns the acronyms of the usage, selector and matching type of the record, e.g. DANE-EE SPKI SHA2-256.
+func (r Result) Description() string {
+	return strings.Join([]string{acronym(usages, r.Usage), acronym(selectors, r.Selector), acronym(matchingTypes, r.MatchingType)}, " ")
+}
+
+// Report holds the TLSA records of a service, checked against a certificate chain.
+type Report struct {
+	Name         string        `json:"name"`
+	Certificates []Certificate `json:"certificates"`
+	Records      []Result      `json:"records"`
+
+	// Matches is the number of records that match a certificate of the chain.
+	Matches int `json:"matches"`
+}
+
+// Check looks up the TLSA records of a service, e.g. _443._tcp.example.com, and checks which certificate of the chain
+// each record matches. Records with the PKIX-EE and DANE-EE usages are checked against the server certificate, the first
+// of the chain, and those with the PKIX-TA and DANE-TA usages against the certificates of the CAs that follow it.
+// The validation of the chain itself, which the PKIX usages also require, is left to the TLS client.
+func Check(r Resolver, name string, chain []*x509.Certificate) (*Report, error) {
+	rrs, err := r.Lookup(name, dns.TypeTLSA)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Name: dns.Fqdn(name), Certificates: []Certificate{}, Records: []Result{}}
+	for _, cert := range chain {
+		report.Certificates = append(report.Certificates, Certificate{Subject: cert.Subject.String(), Issuer: cert.Issuer.String(), NotAfter: cert.NotAfter})
+	}
+
+	for _, rr := range rrs {
+		result := match(rr.(*dns.TLSA), chain)
+		if result.Certificate != nil {
+			report.Matches++
+		}
+		report.Records = append(report.Records, result)
+	}
+	return report, nil
+}
+
+// match checks a TLSA record against the certificates of the chain its usage applies to.
+func match(tlsa *dns.TLSA, chain []*x509.Certificate) Result {
+	result := Result{Usage: tlsa.Usage, Selector: tlsa.Selector, MatchingType: tlsa.MatchingType, Data: strings.ToLower(tlsa.Certificate)}
+
+	var first, last int
+	switch tlsa.Usage {
+	case UsagePKIXEE, UsageDANEEE:
+		first, last = 0, 1
+	case UsagePKIXTA, UsageDANETA:
+		first, last = 1, len(chain)
+	default:
+		result.Error = fmt.Sprintf("unknown usage %d", tlsa.Usage)
+		return result
+	}
+	if int(tlsa.Selector) >= len(selectors) {
+		result.Error = fmt.Sprintf("unknown selector %d", tlsa.Selector)
+		return result
+	}
+	if int(tlsa.MatchingType) >= len(matchingTypes) {
+		result.Error = fmt.Sprintf("unknown matching type %d", tlsa.MatchingType)
+		return result
+	}
+
+	for i := first; i < last && i < len(chain); i++ {
+		// The selector and matching type are known, so CertificateToDANE cannot fail.
+		data, _ := dns.CertificateToDANE(tlsa.Selector, tlsa.MatchingType, chain[i])
+		if data == result.Data {
+			result.Certificate = &i
+			return result
+		}
+	}
+	return result
+}
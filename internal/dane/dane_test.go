@@ -0,0 +1,134 @@
+package dane
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+// newChain creates a certificate for mail.example.com, issued by a CA, and returns them in PEM format.
+func newChain(t *testing.T) []byte {
+	caPub, caKey, _ := ed25519.GenerateKey(rand.Reader)
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Example CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "mail.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, caPub, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, ca, pub, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("skipped")})...)
+	return append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})...)
+}
+
+// fakeResolver serves TLSA records, and fails lookups if err is set.
+type fakeResolver struct {
+	records []dns.RR
+	err     error
+}
+
+func (r *fakeResolver) Lookup(name string, qtype uint16) ([]dns.RR, error) {
+	return r.records, r.err
+}
+
+// newTLSA creates a TLSA record for a certificate.
+func newTLSA(t *testing.T, usage, selector, matchingType uint8, cert *x509.Certificate) *dns.TLSA {
+	data, err := dns.CertificateToDANE(selector, matchingType, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dns.TLSA{
+		Hdr:          dns.RR_Header{Name: "_25._tcp.mail.example.com.", Rrtype: dns.TypeTLSA, Class: dns.ClassINET, Ttl: 300},
+		Usage:        usage,
+		Selector:     selector,
+		MatchingType: matchingType,
+		Certificate:  data,
+	}
+}
+
+func TestParseCertificates(t *testing.T) {
+	chain, err := ParseCertificates(newChain(t))
+
+	assert.NoError(t, err)
+	assert.Len(t, chain, 2)
+	assert.Equal(t, "mail.example.com", chain[0].Subject.CommonName)
+	assert.Equal(t, "Example CA", chain[1].Subject.CommonName)
+
+	_, err = ParseCertificates([]byte("not a certificate"))
+	assert.EqualError(t, err, "no certificates found")
+}
+
+func TestResult_Description(t *testing.T) {
+	assert.Equal(t, "DANE-EE SPKI SHA2-256", Result{Usage: 3, Selector: 1, MatchingType: 1}.Description())
+	assert.Equal(t, "PKIX-TA Cert Full", Result{}.Description())
+	assert.Equal(t, "7 SPKI SHA2-512", Result{Usage: 7, Selector: 1, MatchingType: 2}.Description())
+}
+
+func TestCheck(t *testing.T) {
+	chain, err := ParseCertificates(newChain(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown := newTLSA(t, UsageDANEEE, 1, 1, chain[0])
+	unknown.Usage = 4
+	r := &fakeResolver{records: []dns.RR{
+		newTLSA(t, UsageDANEEE, 1, 1, chain[0]),
+		newTLSA(t, UsageDANETA, 0, 2, chain[1]),
+		newTLSA(t, UsagePKIXEE, 0, 0, chain[0]),
+		// The CA certificate does not match records for the server certificate.
+		newTLSA(t, UsageDANEEE, 1, 1, chain[1]),
+		unknown,
+	}}
+
+	report, err := Check(r, "_25._tcp.mail.example.com", chain)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "_25._tcp.mail.example.com.", report.Name)
+	assert.Equal(t, "CN=mail.example.com", report.Certificates[0].Subject)
+	assert.Equal(t, "CN=Example CA", report.Certificates[0].Issuer)
+	assert.Equal(t, 3, report.Matches)
+
+	leaf, ca := 0, 1
+	assert.Equal(t, &leaf, report.Records[0].Certificate)
+	assert.Equal(t, &ca, report.Records[1].Certificate)
+	assert.Equal(t, &leaf, report.Records[2].Certificate)
+	assert.Nil(t, report.Records[3].Certificate)
+	assert.Empty(t, report.Records[3].Error)
+	assert.Nil(t, report.Records[4].Certificate)
+	assert.Equal(t, "unknown usage 4", report.Records[4].Error)
+}
+
+func TestCheck_Error(t *testing.T) {
+	_, err := Check(&fakeResolver{err: errors.New("SERVFAIL looking up TLSA _25._tcp.mail.example.com.")}, "_25._tcp.mail.example.com", nil)
+
+	assert.EqualError(t, err, "SERVFAIL looking up TLSA _25._tcp.mail.example.com.")
+}
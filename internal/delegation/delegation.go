@@ -0,0 +1,419 @@
+// Package delegation checks the delegation of a zone and the health of its nameservers: whether the parent zone
+// and the nameservers agree on the NS records, whether the nameservers answer authoritatively, and whether they
+// are spread over more than one network.
+package delegation
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// Resolver looks up records through a recursive resolver, and queries authoritative servers directly.
+type Resolver interface {
+	// Lookup returns the records of a type owned by a name. A name that does not exist has no records, and is not an error.
+	Lookup(name string, qtype uint16) ([]dns.RR, error)
+
+	// QueryServer sends a non-recursive query to the server at an IP address.
+	QueryServer(address, name string, qtype uint16) (*query.Response, error)
+}
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Names of the checks, in the order they are reported.
+const (
+	CheckDelegation  = "delegation"
+	CheckNameservers = "nameservers"
+	CheckGlue        = "glue"
+	CheckCNAME       = "cname"
+	CheckAuthority   = "authority"
+	CheckConsistency = "consistency"
+	CheckSerial      = "serial"
+	CheckNetwork     = "network"
+	CheckASN         = "asn"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Check  string `json:"check"`
+	Status Status `json:"status"`
+
+	// Messages explain the outcome. Checks that find several problems have a message for each.
+	Messages []string `json:"messages"`
+}
+
+// Nameserver is a nameserver the parent zone delegates to.
+type Nameserver struct {
+	Name string `json:"name"`
+
+	// Addresses holds the addresses the nameserver was queried at: its glue records if the parent has any,
+	// and otherwise the addresses the resolver returned.
+	Addresses []string `json:"addresses"`
+
+	// Glue holds the addresses of the nameserver given by the parent zone.
+	Glue []string `json:"glue"`
+}
+
+// Report holds the results of checking the delegation of a zone.
+type Report struct {
+	Domain      string       `json:"domain"`
+	Parent      string       `json:"parent"`
+	Nameservers []Nameserver `json:"nameservers"`
+	Results     []Result     `json:"results"`
+}
+
+// Count returns the number of checks with a status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) add(check string, status Status, messages ...string) {
+	r.Results = append(r.Results, Result{Check: check, Status: status, Messages: messages})
+}
+
+// addAll adds the result of a check that finds a problem in each of problems, passing with ok if there are none.
+func (r *Report) addAll(check string, status Status, problems []string, ok string) {
+	if len(problems) == 0 {
+		r.add(check, StatusPass, ok)
+		return
+	}
+	r.add(check, status, problems...)
+}
+
+// Check checks the delegation of a zone. It asks the servers of the parent zone for the NS records and glue of the
+// zone, then queries each nameserver directly for the SOA and NS records of the zone. Errors are only returned
+// if the parent zone cannot be found or none of its servers answers; problems with the zone are reported as results.
+func Check(r Resolver, domain string) (*Report, error) {
+	domain = dns.Fqdn(domain)
+	report := &Report{Domain: domain, Nameservers: []Nameserver{}, Results: []Result{}}
+
+	parent, err := findParent(r, domain)
+	if err != nil {
+		return nil, err
+	}
+	report.Parent = parent
+
+	referral, err := queryParent(r, parent, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	names := nameservers(domain, slices.Concat(referral.Answer, referral.Ns))
+	switch {
+	case referral.Rcode == dns.RcodeNameError:
+		report.add(CheckDelegation, StatusFail, fmt.Sprintf("%s does not exist in %s", domain, parent))
+		return report, nil
+	case len(names) == 0:
+		report.add(CheckDelegation, StatusFail, fmt.Sprintf("%s is not delegated by %s", domain, parent))
+		return report, nil
+	}
+	report.add(CheckDelegation, StatusPass, fmt.Sprintf("%s delegates %s to %s", parent, domain, strings.Join(names, ", ")))
+
+	if len(names) < 2 {
+		report.add(CheckNameservers, StatusWarn, "only one nameserver, the zone is unavailable if it fails")
+	} else {
+		report.add(CheckNameservers, StatusPass, fmt.Sprintf("%d nameservers", len(names)))
+	}
+
+	// Glue is needed for nameservers within the zone, which cannot be resolved without it.
+	var missingGlue, cnames []string
+	for _, name := range names {
+		ns := Nameserver{Name: name, Addresses: []string{}, Glue: glue(name, referral.Extra)}
+		if dns.IsSubDomain(domain, name) && len(ns.Glue) == 0 {
+			missingGlue = append(missingGlue, fmt.Sprintf("no glue for %s, which is within the zone", name))
+		}
+
+		if rrs, err := r.Lookup(name, dns.TypeCNAME); err == nil && len(rrs) > 0 {
+			cnames = append(cnames, fmt.Sprintf("%s is an alias of %s, NS records must not point at CNAMEs", name, rrs[0].(*dns.CNAME).Target))
+		}
+
+		ns.Addresses = ns.Glue
+		if len(ns.Addresses) == 0 {
+			ns.Addresses = addresses(r, name)
+		}
+		report.Nameservers = append(report.Nameservers, ns)
+	}
+	report.addAll(CheckGlue, StatusFail, missingGlue, "the parent has glue for the nameservers that need it")
+	report.addAll(CheckCNAME, StatusFail, cnames, "no nameserver is an alias")
+
+	checkServers(r, report)
+	checkNetworks(r, report)
+	return report, nil
+}
+
+// findParent returns the zone the domain is delegated from: the closest name above it with an SOA record.
+func findParent(r Resolver, domain string) (string, error) {
+	if domain == "." {
+		return "", fmt.Errorf("the root zone has no parent")
+	}
+	for name := domain; name != "."; {
+		_, parent, _ := strings.Cut(name, ".")
+		name = dns.Fqdn(parent)
+
+		rrs, err := r.Lookup(name, dns.TypeSOA)
+		if err != nil {
+			return "", err
+		}
+		if len(rrs) > 0 && strings.EqualFold(rrs[0].Header().Name, name) {
+			return name, nil
+		}
+	}
+	return ".", nil
+}
+
+// queryParent asks the servers of the parent zone for the NS records of the domain, and returns the first response.
+func queryParent(r Resolver, parent, domain string) (*query.Response, error) {
+	rrs, err := r.Lookup(parent, dns.TypeNS)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	for _, rr := range rrs {
+		for _, address := range addresses(r, rr.(*dns.NS).Ns) {
+			resp, err := r.QueryServer(address, domain, dns.TypeNS)
+			if err == nil {
+				return resp, nil
+			}
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no nameservers found for %s", parent)
+	}
+	return nil, fmt.Errorf("no nameserver of %s answered: %s", parent, strings.Join(errs, "; "))
+}
+
+// nameservers returns the sorted, lower case names of the NS records owned by the domain.
+func nameservers(domain string, records []dns.RR) []string {
+	var names []string
+	for _, rr := range records {
+		if ns, ok := rr.(*dns.NS); ok && strings.EqualFold(ns.Hdr.Name, domain) {
+			if name := strings.ToLower(ns.Ns); !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// glue returns the addresses of the A and AAAA records owned by name.
+func glue(name string, records []dns.RR) []string {
+	var owned []dns.RR
+	for _, rr := range records {
+		if strings.EqualFold(rr.Header().Name, name) {
+			owned = append(owned, rr)
+		}
+	}
+	return ips(owned)
+}
+
+// addresses looks up the IPv4 and IPv6 addresses of a name, following CNAMEs. Lookup errors leave the name without addresses.
+func addresses(r Resolver, name string) []string {
+	var found []dns.RR
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		rrs, _ := r.Lookup(name, qtype)
+		found = append(found, rrs...)
+	}
+	return ips(found)
+}
+
+// ips returns the addresses of the A and AAAA records.
+func ips(records []dns.RR) []string {
+	found := []string{}
+	for _, rr := range records {
+		switch rr := rr.(type) {
+		case *dns.A:
+			found = append(found, rr.A.String())
+		case *dns.AAAA:
+			found = append(found, rr.AAAA.String())
+		}
+	}
+	return found
+}
+
+// checkServers queries each address of each nameserver for the SOA and NS records of the zone, and checks that
+// they answer authoritatively, agree with each other on the SOA serial, and agree with the parent on the NS records.
+func checkServers(r Resolver, report *Report) {
+	var parentNS []string
+	for _, ns := range report.Nameservers {
+		parentNS = append(parentNS, ns.Name)
+	}
+
+	var lame, inconsistent []string
+	serials := make(map[uint32][]string)
+	for _, ns := range report.Nameservers {
+		if len(ns.Addresses) == 0 {
+			lame = append(lame, fmt.Sprintf("%s has no addresses", ns.Name))
+			continue
+		}
+
+		for _, address := range ns.Addresses {
+			server := fmt.Sprintf("%s (%s)", ns.Name, address)
+
+			resp, err := r.QueryServer(address, report.Domain, dns.TypeSOA)
+			switch {
+			case err != nil:
+				lame = append(lame, fmt.Sprintf("%s does not answer: %v", server, err))
+				continue
+			case resp.Rcode != dns.RcodeSuccess:
+				lame = append(lame, fmt.Sprintf("%s answers %s", server, dns.RcodeToString[resp.Rcode]))
+				continue
+			case !resp.Authoritative:
+				lame = append(lame, fmt.Sprintf("%s is lame, it is not authoritative for %s", server, report.Domain))
+				continue
+			}
+			for _, rr := range resp.Answer {
+				if soa, ok := rr.(*dns.SOA); ok {
+					serials[soa.Serial] = append(serials[soa.Serial], server)
+				}
+			}
+
+			resp, err = r.QueryServer(address, report.Domain, dns.TypeNS)
+			if err != nil {
+				lame = append(lame, fmt.Sprintf("%s does not answer: %v", server, err))
+				continue
+			}
+			if childNS := nameservers(report.Domain, resp.Answer); !slices.Equal(childNS, parentNS) {
+				inconsistent = append(inconsistent, fmt.Sprintf("%s has NS records %s, the parent has %s", server, strings.Join(childNS, ", "), strings.Join(parentNS, ", ")))
+			}
+		}
+	}
+	report.addAll(CheckAuthority, StatusFail, lame, "all nameservers answer authoritatively")
+	report.addAll(CheckConsistency, StatusWarn, inconsistent, "the NS records of the nameservers match those of the parent")
+
+	switch len(serials) {
+	case 0:
+		report.add(CheckSerial, StatusFail, "no nameserver returned an SOA record")
+	case 1:
+		for serial := range serials {
+			report.add(CheckSerial, StatusPass, fmt.Sprintf("all nameservers have serial %d", serial))
+		}
+	default:
+		var messages []string
+		for _, serial := range slices.Sorted(maps.Keys(serials)) {
+			messages = append(messages, fmt.Sprintf("serial %d on %s", serial, strings.Join(serials[serial], ", ")))
+		}
+		report.add(CheckSerial, StatusWarn, messages...)
+	}
+}
+
+// checkNetworks checks that the nameservers are not all in the same network, a /24 for IPv4 and a /48 for IPv6,
+// nor all in the same autonomous system, as a single outage would take all of them down. Networks are checked
+// per address family, as the IPv4 and IPv6 addresses of a single nameserver are in two networks but fail together.
+func checkNetworks(r Resolver, report *Report) {
+	ipv4 := make(map[string]bool)
+	ipv6 := make(map[string]bool)
+	asns := make(map[string]bool)
+	unknown := 0
+	for _, ns := range report.Nameservers {
+		for _, address := range ns.Addresses {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				continue
+			}
+			if ip.To4() != nil {
+				ipv4[network(ip)] = true
+			} else {
+				ipv6[network(ip)] = true
+			}
+			if asn := lookupASN(r, ip); asn != "" {
+				asns[asn] = true
+			} else {
+				unknown++
+			}
+		}
+	}
+
+	if len(ipv4) == 0 && len(ipv6) == 0 {
+		report.add(CheckNetwork, StatusFail, "no nameserver has an address")
+		return
+	}
+	// Each address family must be spread on its own, as clients that only reach one of them depend on it alone.
+	var problems, counts []string
+	for _, family := range []struct {
+		name     string
+		networks map[string]bool
+	}{{"IPv4", ipv4}, {"IPv6", ipv6}} {
+		switch len(family.networks) {
+		case 0:
+		case 1:
+			for n := range family.networks {
+				problems = append(problems, fmt.Sprintf("all %s addresses of the nameservers are in %s", family.name, n))
+			}
+		default:
+			counts = append(counts, fmt.Sprintf("%d %s networks", len(family.networks), family.name))
+		}
+	}
+	report.addAll(CheckNetwork, StatusWarn, problems, fmt.Sprintf("the nameservers are in %s", strings.Join(counts, " and ")))
+
+	switch {
+	case len(asns) > 1:
+		report.add(CheckASN, StatusPass, fmt.Sprintf("the nameservers are in %d autonomous systems", len(asns)))
+	case unknown > 0:
+		report.add(CheckASN, StatusWarn, fmt.Sprintf("the autonomous system of %d %s is unknown", unknown, plural(unknown, "address", "addresses")))
+	default:
+		for asn := range asns {
+			report.add(CheckASN, StatusWarn, fmt.Sprintf("all nameservers are in AS%s", asn))
+		}
+	}
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// network returns the /24 network of an IPv4 address, or the /48 network of an IPv6 address.
+func network(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// lookupASN returns the number of the autonomous system that announces an address, using the DNS interface of
+// Team Cymru's IP to ASN mapping. It returns an empty string if the number cannot be looked up.
+func lookupASN(r Resolver, ip net.IP) string {
+	reverse, err := dns.ReverseAddr(ip.String())
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSuffix(reverse, ".in-addr.arpa.") + ".origin.asn.cymru.com."
+	if ip.To4() == nil {
+		name = strings.TrimSuffix(reverse, ".ip6.arpa.") + ".origin6.asn.cymru.com."
+	}
+
+	rrs, err := r.Lookup(name, dns.TypeTXT)
+	if err != nil || len(rrs) == 0 || len(rrs[0].(*dns.TXT).Txt) == 0 {
+		return ""
+	}
+	// The record looks like "64496 | 192.0.2.0/24 | ZZ | arin | 2020-01-01". Addresses announced by several
+	// systems have a number for each, separated by spaces, and the first one is used.
+	fields := strings.Fields(rrs[0].(*dns.TXT).Txt[0])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
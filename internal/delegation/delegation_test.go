@@ -0,0 +1,270 @@
+package delegation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/dnstest"
+	"github.com/znscli/zns/internal/query"
+)
+
+// fakeServer is an authoritative server. Parent servers answer NS queries with a referral,
+// the other servers answer from their records.
+type fakeServer struct {
+	*dnstest.Zone
+	parent        bool
+	authoritative bool
+}
+
+// fakeResolver answers lookups from its zone, and queries to the servers at the addresses of servers.
+// Queries to other addresses time out.
+type fakeResolver struct {
+	*dnstest.Zone
+	servers map[string]*fakeServer
+}
+
+func (r *fakeResolver) QueryServer(address, name string, qtype uint16) (*query.Response, error) {
+	server, ok := r.servers[address]
+	if !ok {
+		return nil, errors.New("i/o timeout")
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.Response = true
+	msg.Authoritative = server.authoritative
+
+	found, exists := server.Find(name, qtype)
+	switch {
+	case !exists:
+		msg.Rcode = dns.RcodeNameError
+	case server.parent:
+		msg.Ns = found
+		for _, rr := range server.Records {
+			if rr.Header().Rrtype == dns.TypeA || rr.Header().Rrtype == dns.TypeAAAA {
+				msg.Extra = append(msg.Extra, rr)
+			}
+		}
+	default:
+		msg.Answer = found
+	}
+	return &query.Response{Msg: msg}, nil
+}
+
+// newResolver returns a resolver for the com. zone, whose server delegates example.com. as given by referral.
+func newResolver(t *testing.T, records []string, referral ...string) *fakeResolver {
+	return &fakeResolver{
+		Zone: dnstest.NewZone(t, append([]string{
+			"com. 300 IN SOA a.gtld-servers.net. nstld.verisign-grs.com. 1 1800 900 604800 86400",
+			"com. 300 IN NS a.gtld-servers.net.",
+			"a.gtld-servers.net. 300 IN A 192.5.6.30",
+		}, records...)...),
+		servers: map[string]*fakeServer{
+			"192.5.6.30": {Zone: dnstest.NewZone(t, referral...), parent: true},
+		},
+	}
+}
+
+// newZone returns an authoritative server for example.com.
+func newZone(t *testing.T, serial string, nameservers ...string) *fakeServer {
+	records := []string{"example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. " + serial + " 7200 3600 1209600 300"}
+	for _, ns := range nameservers {
+		records = append(records, "example.com. 300 IN NS "+ns)
+	}
+	return &fakeServer{Zone: dnstest.NewZone(t, records...), authoritative: true}
+}
+
+// statuses returns the status of each check of a report.
+func statuses(report *Report) map[string]Status {
+	m := make(map[string]Status)
+	for _, result := range report.Results {
+		m[result.Check] = result.Status
+	}
+	return m
+}
+
+func TestCheck(t *testing.T) {
+	r := newResolver(t, []string{
+		"ns2.example.net. 300 IN A 198.51.100.53",
+		`53.2.0.192.origin.asn.cymru.com. 300 IN TXT "64496 | 192.0.2.0/24 | ZZ | arin | 2020-01-01"`,
+		`53.100.51.198.origin.asn.cymru.com. 300 IN TXT "64497 | 198.51.100.0/24 | ZZ | arin | 2020-01-01"`,
+	},
+		"example.com. 300 IN NS ns1.example.com.",
+		"example.com. 300 IN NS ns2.example.net.",
+		"ns1.example.com. 300 IN A 192.0.2.53",
+	)
+	r.servers["192.0.2.53"] = newZone(t, "2024010101", "ns1.example.com.", "ns2.example.net.")
+	r.servers["198.51.100.53"] = newZone(t, "2024010101", "ns2.example.net.", "ns1.example.com.")
+
+	report, err := Check(r, "example.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com.", report.Domain)
+	assert.Equal(t, "com.", report.Parent)
+	assert.Equal(t, []Nameserver{
+		{Name: "ns1.example.com.", Addresses: []string{"192.0.2.53"}, Glue: []string{"192.0.2.53"}},
+		{Name: "ns2.example.net.", Addresses: []string{"198.51.100.53"}, Glue: []string{}},
+	}, report.Nameservers)
+	assert.Equal(t, []Result{
+		{Check: CheckDelegation, Status: StatusPass, Messages: []string{"com. delegates example.com. to ns1.example.com., ns2.example.net."}},
+		{Check: CheckNameservers, Status: StatusPass, Messages: []string{"2 nameservers"}},
+		{Check: CheckGlue, Status: StatusPass, Messages: []string{"the parent has glue for the nameservers that need it"}},
+		{Check: CheckCNAME, Status: StatusPass, Messages: []string{"no nameserver is an alias"}},
+		{Check: CheckAuthority, Status: StatusPass, Messages: []string{"all nameservers answer authoritatively"}},
+		{Check: CheckConsistency, Status: StatusPass, Messages: []string{"the NS records of the nameservers match those of the parent"}},
+		{Check: CheckSerial, Status: StatusPass, Messages: []string{"all nameservers have serial 2024010101"}},
+		{Check: CheckNetwork, Status: StatusPass, Messages: []string{"the nameservers are in 2 IPv4 networks"}},
+		{Check: CheckASN, Status: StatusPass, Messages: []string{"the nameservers are in 2 autonomous systems"}},
+	}, report.Results)
+	assert.Equal(t, 9, report.Count(StatusPass))
+}
+
+func TestCheck_Problems(t *testing.T) {
+	r := newResolver(t, []string{
+		"ns1.example.com. 300 IN A 192.0.2.53",
+		"ns2.example.net. 300 IN CNAME host.example.net.",
+		"host.example.net. 300 IN A 192.0.2.54",
+	},
+		"example.com. 300 IN NS ns1.example.com.",
+		"example.com. 300 IN NS ns2.example.net.",
+		"example.com. 300 IN NS ns3.example.org.",
+	)
+	r.servers["192.0.2.53"] = newZone(t, "1", "ns1.example.com.", "ns2.example.net.", "ns3.example.org.")
+	r.servers["192.0.2.54"] = newZone(t, "2", "ns2.example.net.")
+
+	report, err := Check(r, "example.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Status{
+		CheckDelegation:  StatusPass,
+		CheckNameservers: StatusPass,
+		CheckGlue:        StatusFail,
+		CheckCNAME:       StatusFail,
+		CheckAuthority:   StatusFail,
+		CheckConsistency: StatusWarn,
+		CheckSerial:      StatusWarn,
+		CheckNetwork:     StatusWarn,
+		CheckASN:         StatusWarn,
+	}, statuses(report))
+
+	messages := make(map[string][]string)
+	for _, result := range report.Results {
+		messages[result.Check] = result.Messages
+	}
+	assert.Equal(t, []string{"no glue for ns1.example.com., which is within the zone"}, messages[CheckGlue])
+	assert.Equal(t, []string{"ns2.example.net. is an alias of host.example.net., NS records must not point at CNAMEs"}, messages[CheckCNAME])
+	assert.Equal(t, []string{"ns3.example.org. has no addresses"}, messages[CheckAuthority])
+	assert.Equal(t, []string{"ns2.example.net. (192.0.2.54) has NS records ns2.example.net., the parent has ns1.example.com., ns2.example.net., ns3.example.org."}, messages[CheckConsistency])
+	assert.Equal(t, []string{"serial 1 on ns1.example.com. (192.0.2.53)", "serial 2 on ns2.example.net. (192.0.2.54)"}, messages[CheckSerial])
+	assert.Equal(t, []string{"all IPv4 addresses of the nameservers are in 192.0.2.0/24"}, messages[CheckNetwork])
+	assert.Equal(t, []string{"the autonomous system of 2 addresses is unknown"}, messages[CheckASN])
+}
+
+func TestCheck_DualStack(t *testing.T) {
+	tests := []struct {
+		name      string
+		addresses []string
+		status    Status
+		messages  []string
+	}{
+		{
+			name: "same networks",
+			addresses: []string{
+				"ns1.example.net. 300 IN A 192.0.2.53",
+				"ns1.example.net. 300 IN AAAA 2001:db8::53",
+				"ns2.example.net. 300 IN A 192.0.2.54",
+				"ns2.example.net. 300 IN AAAA 2001:db8::54",
+			},
+			status:   StatusWarn,
+			messages: []string{"all IPv4 addresses of the nameservers are in 192.0.2.0/24", "all IPv6 addresses of the nameservers are in 2001:db8::/48"},
+		},
+		{
+			name: "IPv4 in one network",
+			addresses: []string{
+				"ns1.example.net. 300 IN A 192.0.2.53",
+				"ns1.example.net. 300 IN AAAA 2001:db8:1::53",
+				"ns2.example.net. 300 IN A 192.0.2.54",
+				"ns2.example.net. 300 IN AAAA 2001:db8:2::53",
+			},
+			status:   StatusWarn,
+			messages: []string{"all IPv4 addresses of the nameservers are in 192.0.2.0/24"},
+		},
+		{
+			name: "spread",
+			addresses: []string{
+				"ns1.example.net. 300 IN A 192.0.2.53",
+				"ns1.example.net. 300 IN AAAA 2001:db8:1::53",
+				"ns2.example.net. 300 IN A 198.51.100.53",
+				"ns2.example.net. 300 IN AAAA 2001:db8:2::53",
+			},
+			status:   StatusPass,
+			messages: []string{"the nameservers are in 2 IPv4 networks and 2 IPv6 networks"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newResolver(t, tt.addresses,
+				"example.com. 300 IN NS ns1.example.net.",
+				"example.com. 300 IN NS ns2.example.net.",
+			)
+
+			report, err := Check(r, "example.com")
+
+			assert.NoError(t, err)
+			for _, result := range report.Results {
+				if result.Check == CheckNetwork {
+					assert.Equal(t, tt.status, result.Status)
+					assert.Equal(t, tt.messages, result.Messages)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck_Lame(t *testing.T) {
+	r := newResolver(t, []string{"ns.example.net. 300 IN A 198.51.100.53", "ns.example.org. 300 IN A 203.0.113.53"},
+		"example.com. 300 IN NS ns.example.net.",
+		"example.com. 300 IN NS ns.example.org.",
+	)
+	r.servers["198.51.100.53"] = &fakeServer{Zone: newZone(t, "1").Zone}
+
+	report, err := Check(r, "example.com")
+
+	assert.NoError(t, err)
+	for _, result := range report.Results {
+		if result.Check == CheckAuthority {
+			assert.Equal(t, StatusFail, result.Status)
+			assert.Equal(t, []string{
+				"ns.example.net. (198.51.100.53) is lame, it is not authoritative for example.com.",
+				"ns.example.org. (203.0.113.53) does not answer: i/o timeout",
+			}, result.Messages)
+		}
+	}
+	assert.Equal(t, StatusFail, statuses(report)[CheckSerial])
+}
+
+func TestCheck_NotDelegated(t *testing.T) {
+	r := newResolver(t, nil, "example.com. 300 IN A 192.0.2.1")
+
+	report, err := Check(r, "example.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Result{{Check: CheckDelegation, Status: StatusFail, Messages: []string{"example.com. is not delegated by com."}}}, report.Results)
+
+	report, err = Check(r, "example.org.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Result{{Check: CheckDelegation, Status: StatusFail, Messages: []string{"example.org.com. does not exist in com."}}}, report.Results)
+}
+
+func TestCheck_ParentUnreachable(t *testing.T) {
+	r := newResolver(t, nil)
+	delete(r.servers, "192.5.6.30")
+
+	_, err := Check(r, "example.com")
+
+	assert.EqualError(t, err, "no nameserver of com. answered: i/o timeout")
+}
@@ -0,0 +1,113 @@
+// Package dnstest provides a fake zone for testing code that resolves names, built from records in zone file format.
+package dnstest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// Parse parses records in zone file format, and fails the test if any of them is invalid.
+func Parse(t testing.TB, records ...string) []dns.RR {
+	t.Helper()
+
+	var rrs []dns.RR
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs
+}
+
+// Zone serves records the way a resolver does. It is safe for concurrent use.
+type Zone struct {
+	Records []dns.RR
+
+	// Errs holds the names whose lookups fail, as if the server answered SERVFAIL.
+	Errs map[string]bool
+
+	mu      sync.Mutex
+	lookups []string
+}
+
+// NewZone creates a zone from records in zone file format.
+func NewZone(t testing.TB, records ...string) *Zone {
+	t.Helper()
+
+	return &Zone{Records: Parse(t, records...), Errs: make(map[string]bool)}
+}
+
+// Lookup returns the records of a type owned by a name, following its CNAME chain, like QueryClient.Lookup.
+// Only the records of the queried type are returned, and a name that does not exist has no records.
+func (z *Zone) Lookup(name string, qtype uint16) ([]dns.RR, error) {
+	z.mu.Lock()
+	z.lookups = append(z.lookups, name)
+	z.mu.Unlock()
+
+	name = dns.Fqdn(name)
+	if z.Errs[name] {
+		return nil, fmt.Errorf("SERVFAIL looking up %s %s", dns.TypeToString[qtype], name)
+	}
+
+	var records []dns.RR
+	for _, rr := range z.Answer(name, qtype) {
+		if rr.Header().Rrtype == qtype {
+			records = append(records, rr)
+		}
+	}
+	return records, nil
+}
+
+// Lookups returns the names that were looked up, in order.
+func (z *Zone) Lookups() []string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	return append([]string{}, z.lookups...)
+}
+
+// Find returns the records of a type owned by a name, without following CNAMEs, and whether the name owns
+// any records at all.
+func (z *Zone) Find(name string, qtype uint16) ([]dns.RR, bool) {
+	var found []dns.RR
+	exists := false
+	for _, rr := range z.Records {
+		if strings.EqualFold(rr.Header().Name, dns.Fqdn(name)) {
+			exists = true
+			if rr.Header().Rrtype == qtype {
+				found = append(found, rr)
+			}
+		}
+	}
+	return found, exists
+}
+
+// Answer returns the answer section of a response to a query: the records of a type owned by a name, or its
+// CNAME followed by the answer for the target of the CNAME.
+func (z *Zone) Answer(name string, qtype uint16) []dns.RR {
+	return z.answer(dns.Fqdn(name), qtype, make(map[string]bool))
+}
+
+func (z *Zone) answer(name string, qtype uint16, seen map[string]bool) []dns.RR {
+	if seen[strings.ToLower(name)] {
+		return nil
+	}
+	seen[strings.ToLower(name)] = true
+
+	found, _ := z.Find(name, qtype)
+	if len(found) > 0 || qtype == dns.TypeCNAME {
+		return found
+	}
+	cnames, _ := z.Find(name, dns.TypeCNAME)
+	for _, rr := range cnames {
+		found = append(found, rr)
+		found = append(found, z.answer(rr.(*dns.CNAME).Target, qtype, seen)...)
+	}
+	return found
+}
@@ -0,0 +1,37 @@
+package dnstest
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZone(t *testing.T) {
+	z := NewZone(t,
+		"www.example.com. 300 IN CNAME cdn.example.net.",
+		"cdn.example.net. 300 IN A 192.0.2.1",
+		"loop.example.com. 300 IN CNAME loop.example.com.",
+		"example.com. 300 IN MX 10 mx.example.com.",
+	)
+	z.Errs["broken.example.com."] = true
+
+	records, err := z.Lookup("www.example.com", dns.TypeA)
+	assert.NoError(t, err)
+	assert.Equal(t, z.Records[1:2], records)
+
+	answer := z.Answer("WWW.example.com.", dns.TypeA)
+	assert.Equal(t, z.Records[:2], answer)
+	assert.Equal(t, z.Records[2:3], z.Answer("loop.example.com.", dns.TypeA))
+
+	records, exists := z.Find("www.example.com", dns.TypeA)
+	assert.Empty(t, records)
+	assert.True(t, exists)
+	_, exists = z.Find("ftp.example.com", dns.TypeA)
+	assert.False(t, exists)
+
+	_, err = z.Lookup("broken.example.com", dns.TypeTXT)
+	assert.EqualError(t, err, "SERVFAIL looking up TXT broken.example.com.")
+
+	assert.Equal(t, []string{"www.example.com", "broken.example.com"}, z.Lookups())
+}
@@ -0,0 +1,175 @@
+// Package enum discovers the names of a zone by querying candidate labels from a wordlist, as done to take
+// inventory of the names a zone exposes.
+package enum
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// Querier queries the records of several types of a name, as the query client does.
+type Querier interface {
+	MultiQuery(domain string, qtypes []uint16) ([]*query.Response, error)
+}
+
+// Options configures an enumeration.
+type Options struct {
+	// Types are the query types asked for each candidate name.
+	Types []uint16
+
+	// Workers is the number of candidate names queried concurrently. Values below 1 mean one.
+	Workers int
+
+	// Rate is the maximum number of queries sent per second, or 0 for no limit. A rate above one query per
+	// nanosecond is too high for the ticker that paces the queries, and is not limited either.
+	Rate int
+}
+
+// Result is the outcome of querying one candidate name.
+type Result struct {
+	// Name is the candidate name.
+	Name string
+
+	// Responses are the responses to the queries for the name. Answers synthesized from a wildcard are removed.
+	Responses []*query.Response
+
+	// Wildcard is set if the name only has answers that were synthesized from a wildcard.
+	Wildcard bool
+
+	// Err is the error of the queries for the name, if any failed.
+	Err error
+}
+
+// Found reports whether the name has records of its own.
+func (r Result) Found() bool {
+	for _, resp := range r.Responses {
+		if len(resp.Answer) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats counts the candidate names of an enumeration by their outcome.
+type Stats struct {
+	Queried  int
+	Found    int
+	Wildcard int
+	Failed   int
+}
+
+// Enumerate queries <word>.<domain> for every word, and calls fn with the result of each name as soon as its
+// queries complete. Calls to fn are serialized. Before the words, a random name that should not exist is
+// queried: if the zone has a wildcard, it answers that name, and the answers candidate names share with it
+// are removed from their results. An error is only returned if that probe fails.
+func Enumerate(q Querier, domain string, words []string, opts Options, fn func(Result)) (Stats, error) {
+	var stats Stats
+	domain = dns.Fqdn(domain)
+
+	var limit <-chan time.Time
+	if opts.Rate > 0 && time.Second/time.Duration(opts.Rate) > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(opts.Rate))
+		defer ticker.Stop()
+		limit = ticker.C
+	}
+	// wait blocks until the rate limit allows a query for each type.
+	wait := func() {
+		if limit == nil {
+			return
+		}
+		for range opts.Types {
+			<-limit
+		}
+	}
+
+	wait()
+	probes, err := q.MultiQuery(query.ProbeLabel()+"."+domain, opts.Types)
+	if err != nil {
+		return stats, fmt.Errorf("failed to probe for a wildcard: %v", err)
+	}
+
+	workers := max(opts.Workers, 1)
+	names := make(chan string)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				wait()
+				result := resolve(q, name, opts.Types, probes)
+
+				mu.Lock()
+				stats.Queried++
+				switch {
+				case result.Err != nil:
+					stats.Failed++
+				case result.Wildcard:
+					stats.Wildcard++
+				case result.Found():
+					stats.Found++
+				}
+				fn(result)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, word := range words {
+		names <- word + "." + domain
+	}
+	close(names)
+	wg.Wait()
+
+	return stats, nil
+}
+
+// resolve queries a candidate name and removes the answers synthesized from a wildcard, which answered the
+// probes. A response whose answers owned by the name were all synthesized loses all its answers, including the
+// records of the target of a synthesized CNAME.
+func resolve(q Querier, name string, qtypes []uint16, probes []*query.Response) Result {
+	result := Result{Name: name}
+	if _, ok := dns.IsDomainName(name); !ok {
+		result.Err = fmt.Errorf("invalid name: %s", name)
+		return result
+	}
+
+	responses, err := q.MultiQuery(name, qtypes)
+	result.Err = err
+
+	synthesized := false
+	for i, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		resp.MarkWildcards(probes[i])
+
+		var answers []dns.RR
+		own := 0
+		for _, rr := range resp.Answer {
+			if resp.IsWildcard(rr) {
+				synthesized = true
+				continue
+			}
+			if strings.EqualFold(rr.Header().Name, name) {
+				own++
+			}
+			answers = append(answers, rr)
+		}
+		if own == 0 {
+			answers = nil
+		}
+		resp.Answer = answers
+		result.Responses = append(result.Responses, resp)
+	}
+	result.Wildcard = synthesized && !result.Found()
+
+	return result
+}
@@ -0,0 +1,169 @@
+package enum
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/dnstest"
+	"github.com/znscli/zns/internal/query"
+)
+
+// fakeQuerier answers from records, and synthesizes the records of wildcard, if set, for names of example.com.
+// that have no records. Queries for names in errs fail, and all queries fail if err is set.
+type fakeQuerier struct {
+	records  []dns.RR
+	wildcard []dns.RR
+	errs     map[string]error
+	err      error
+
+	mu      sync.Mutex
+	queries int
+}
+
+func (q *fakeQuerier) MultiQuery(domain string, qtypes []uint16) ([]*query.Response, error) {
+	q.mu.Lock()
+	q.queries += len(qtypes)
+	q.mu.Unlock()
+
+	if q.err != nil {
+		return make([]*query.Response, len(qtypes)), q.err
+	}
+	if err, ok := q.errs[domain]; ok {
+		return make([]*query.Response, len(qtypes)), err
+	}
+
+	zone := &dnstest.Zone{Records: q.records}
+	_, exists := zone.Find(domain, dns.TypeANY)
+
+	var responses []*query.Response
+	for _, qtype := range qtypes {
+		msg := new(dns.Msg)
+		msg.SetQuestion(domain, qtype)
+		msg.Response = true
+		switch {
+		case exists:
+			msg.Answer = zone.Answer(domain, qtype)
+		case q.wildcard != nil:
+			var synthesized []dns.RR
+			for _, rr := range q.wildcard {
+				rr = dns.Copy(rr)
+				rr.Header().Name = domain
+				synthesized = append(synthesized, rr)
+			}
+			msg.Answer = (&dnstest.Zone{Records: append(synthesized, q.records...)}).Answer(domain, qtype)
+		default:
+			msg.Rcode = dns.RcodeNameError
+		}
+		responses = append(responses, &query.Response{Msg: msg})
+	}
+	return responses, nil
+}
+
+// enumerate runs an enumeration and returns the results, sorted by name.
+func enumerate(t *testing.T, q Querier, words []string, opts Options) ([]Result, Stats) {
+	var results []Result
+	stats, err := Enumerate(q, "example.com", words, opts, func(r Result) {
+		results = append(results, r)
+	})
+	assert.NoError(t, err)
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results, stats
+}
+
+func TestEnumerate(t *testing.T) {
+	q := &fakeQuerier{records: dnstest.Parse(t,
+		"www.example.com. 300 IN A 192.0.2.1",
+		"mail.example.com. 300 IN A 192.0.2.25",
+		"mail.example.com. 300 IN AAAA 2001:db8::25",
+	)}
+
+	results, stats := enumerate(t, q, []string{"www", "mail", "ftp"}, Options{Types: []uint16{dns.TypeA, dns.TypeAAAA}, Workers: 2})
+
+	assert.Equal(t, Stats{Queried: 3, Found: 2}, stats)
+	assert.Len(t, results, 3)
+	assert.Equal(t, "ftp.example.com.", results[0].Name)
+	assert.False(t, results[0].Found())
+	assert.Equal(t, "mail.example.com.", results[1].Name)
+	assert.True(t, results[1].Found())
+	assert.Len(t, results[1].Responses[0].Answer, 1)
+	assert.Len(t, results[1].Responses[1].Answer, 1)
+	assert.Equal(t, "www.example.com.", results[2].Name)
+	assert.True(t, results[2].Found())
+	// The probe and three names, for two types each.
+	assert.Equal(t, 8, q.queries)
+}
+
+func TestEnumerate_Wildcard(t *testing.T) {
+	q := &fakeQuerier{
+		records: dnstest.Parse(t,
+			"mail.example.com. 300 IN A 192.0.2.25",
+			"cdn.example.com. 300 IN CNAME cdn.example.net.",
+			"cdn.example.net. 300 IN A 198.51.100.2",
+			"www.example.com. 300 IN CNAME edge.example.net.",
+			"edge.example.net. 300 IN A 198.51.100.1",
+		),
+		wildcard: dnstest.Parse(t, "*.example.com. 300 IN CNAME edge.example.net."),
+	}
+
+	results, stats := enumerate(t, q, []string{"www", "mail", "cdn", "ftp"}, Options{Types: []uint16{dns.TypeA}})
+
+	assert.Equal(t, Stats{Queried: 4, Found: 2, Wildcard: 2}, stats)
+	found := make(map[string]bool)
+	for _, r := range results {
+		found[r.Name] = r.Found()
+	}
+	assert.Equal(t, map[string]bool{
+		"cdn.example.com.":  true,
+		"ftp.example.com.":  false,
+		"mail.example.com.": true,
+		// The CNAME of www has the same target as the wildcard, so it cannot be told apart from it.
+		"www.example.com.": false,
+	}, found)
+	// The records of the target of the synthesized CNAME are removed too.
+	assert.True(t, results[1].Wildcard)
+	assert.Empty(t, results[1].Responses[0].Answer)
+}
+
+func TestEnumerate_Errors(t *testing.T) {
+	q := &fakeQuerier{
+		records: dnstest.Parse(t, "www.example.com. 300 IN A 192.0.2.1"),
+		errs:    map[string]error{"mail.example.com.": errors.New("i/o timeout")},
+	}
+
+	results, stats := enumerate(t, q, []string{"www", "mail", "bad..label"}, Options{Types: []uint16{dns.TypeA}})
+
+	assert.Equal(t, Stats{Queried: 3, Found: 1, Failed: 2}, stats)
+	assert.EqualError(t, results[0].Err, "invalid name: bad..label.example.com.")
+	assert.EqualError(t, results[1].Err, "i/o timeout")
+
+	_, err := Enumerate(&fakeQuerier{err: errors.New("i/o timeout")}, "example.com", []string{"www"}, Options{Types: []uint16{dns.TypeA}}, func(Result) {
+		t.Error("no names should be queried if the probe fails")
+	})
+	assert.EqualError(t, err, "failed to probe for a wildcard: i/o timeout")
+}
+
+func TestEnumerate_Rate(t *testing.T) {
+	q := &fakeQuerier{}
+
+	start := time.Now()
+	_, stats := enumerate(t, q, []string{"a", "b", "c"}, Options{Types: []uint16{dns.TypeA}, Workers: 3, Rate: 100})
+
+	assert.Equal(t, 3, stats.Queried)
+	// Four queries at 100 per second take at least 40ms.
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestEnumerate_RateUnlimited(t *testing.T) {
+	q := &fakeQuerier{}
+
+	// A rate above one query per nanosecond cannot be paced, and must not panic.
+	_, stats := enumerate(t, q, []string{"a", "b", "c"}, Options{Types: []uint16{dns.TypeA}, Workers: 3, Rate: 2_000_000_000})
+
+	assert.Equal(t, 3, stats.Queried)
+}
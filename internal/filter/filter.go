@@ -0,0 +1,339 @@
+// Package filter implements the expressions accepted by the --filter flag, such as `type=MX && preference<20`.
+//
+// An expression is made of conditions on record fields, combined with && (and), || (or) and ! (not),
+// and grouped with parentheses. A condition compares a field with a value:
+//
+//	type        the record type, e.g. MX
+//	name        the owner name, with or without the trailing dot
+//	class       the record class, e.g. IN
+//	ttl         the TTL in seconds
+//	value       the record data as displayed, with the strings of TXT records unquoted and joined with spaces
+//	preference  the preference of MX records
+//
+// The operators = and != compare fields for equality, ignoring case for all fields but value.
+// The operators <, <=, > and >= compare the numeric fields ttl and preference.
+// The operators ~ and !~ match fields against a regular expression.
+//
+// Values that contain spaces or any of the characters =!<>~&|() must be quoted with single or double quotes.
+// A condition on a field that the record does not have, such as the preference of an A record, never matches.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// Expr is a parsed filter expression.
+type Expr interface {
+	// Match reports whether a record matches the expression.
+	Match(rr dns.RR) bool
+}
+
+// Filter returns the records that match the expression, in their original order.
+func Filter(expr Expr, records []dns.RR) []dns.RR {
+	var out []dns.RR
+	for _, rr := range records {
+		if expr.Match(rr) {
+			out = append(out, rr)
+		}
+	}
+	return out
+}
+
+type and struct{ left, right Expr }
+
+func (e and) Match(rr dns.RR) bool { return e.left.Match(rr) && e.right.Match(rr) }
+
+type or struct{ left, right Expr }
+
+func (e or) Match(rr dns.RR) bool { return e.left.Match(rr) || e.right.Match(rr) }
+
+type not struct{ expr Expr }
+
+func (e not) Match(rr dns.RR) bool { return !e.expr.Match(rr) }
+
+// condition compares a single field of a record with a value.
+type condition struct {
+	field string
+	op    string
+	value string
+	num   uint64
+	re    *regexp.Regexp
+}
+
+func (c condition) Match(rr dns.RR) bool {
+	value, ok := field(rr, c.field)
+	if !ok {
+		return false
+	}
+
+	switch c.op {
+	case "~":
+		return c.re.MatchString(value)
+	case "!~":
+		return !c.re.MatchString(value)
+	}
+
+	if !numeric(c.field) {
+		if c.op == "=" {
+			return c.equal(value)
+		}
+		return !c.equal(value)
+	}
+
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return false
+	}
+	switch c.op {
+	case "=":
+		return n == c.num
+	case "!=":
+		return n != c.num
+	case "<":
+		return n < c.num
+	case "<=":
+		return n <= c.num
+	case ">":
+		return n > c.num
+	default:
+		return n >= c.num
+	}
+}
+
+func (c condition) equal(value string) bool {
+	switch c.field {
+	case "value":
+		return value == c.value
+	case "name":
+		return strings.EqualFold(value, dns.Fqdn(c.value))
+	default:
+		return strings.EqualFold(value, c.value)
+	}
+}
+
+// field returns the value of a field of a record. The second return value is false if the record does not have the field.
+func field(rr dns.RR, name string) (string, bool) {
+	switch name {
+	case "type":
+		return dns.TypeToString[rr.Header().Rrtype], true
+	case "name":
+		return rr.Header().Name, true
+	case "class":
+		return dns.ClassToString[rr.Header().Class], true
+	case "ttl":
+		return strconv.FormatUint(uint64(rr.Header().Ttl), 10), true
+	case "value":
+		return query.Value(rr), true
+	case "preference":
+		preference, ok := query.Preference(rr)
+		return strconv.FormatUint(uint64(preference), 10), ok
+	default:
+		return "", false
+	}
+}
+
+// fields holds the names of the fields conditions can compare.
+var fields = map[string]bool{"type": true, "name": true, "class": true, "ttl": true, "value": true, "preference": true}
+
+// numeric reports whether a field holds a number, and can be compared with <, <=, > and >=.
+func numeric(field string) bool {
+	return field == "ttl" || field == "preference"
+}
+
+// Parse parses a filter expression.
+func Parse(s string) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid filter: %v", err)
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid filter: %v", err)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek returns the text of the next operator token, or an empty string if there is none.
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].op {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = and{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	switch p.peek() {
+	case "!":
+		p.pos++
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return not{expr}, nil
+	case "(":
+		p.pos++
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	default:
+		return p.condition()
+	}
+}
+
+func (p *parser) condition() (Expr, error) {
+	if p.pos+3 > len(p.tokens) {
+		return nil, fmt.Errorf("incomplete condition")
+	}
+	f, op, v := p.tokens[p.pos], p.tokens[p.pos+1], p.tokens[p.pos+2]
+	if f.op {
+		return nil, fmt.Errorf("unexpected %q", f.text)
+	}
+	if !fields[f.text] {
+		return nil, fmt.Errorf("unknown field %q", f.text)
+	}
+	if !op.op || !comparison(op.text) {
+		return nil, fmt.Errorf("expected an operator after %q", f.text)
+	}
+	if v.op {
+		return nil, fmt.Errorf("expected a value after %q", op.text)
+	}
+	p.pos += 3
+
+	c := condition{field: f.text, op: op.text, value: v.text}
+	switch op.text {
+	case "~", "!~":
+		re, err := regexp.Compile(v.text)
+		if err != nil {
+			return nil, err
+		}
+		c.re = re
+	case "<", "<=", ">", ">=":
+		if !numeric(f.text) {
+			return nil, fmt.Errorf("operator %s is only supported by the ttl and preference fields", op.text)
+		}
+	}
+	if numeric(f.text) && c.re == nil {
+		n, err := strconv.ParseUint(v.text, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be compared with a number, got %q", f.text, v.text)
+		}
+		c.num = n
+	}
+	return c, nil
+}
+
+func comparison(op string) bool {
+	switch op {
+	case "=", "!=", "<", "<=", ">", ">=", "~", "!~":
+		return true
+	default:
+		return false
+	}
+}
+
+// token is a single operator or word of an expression. Quoted values are never operators.
+type token struct {
+	text string
+	op   bool
+}
+
+// operators holds the operators of the expression language, longest first so that they are matched greedily.
+var operators = []string{"&&", "||", "!=", "!~", "<=", ">=", "=", "<", ">", "~", "!", "(", ")"}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, token{text: s[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			if op := operatorAt(s[i:]); op != "" {
+				tokens = append(tokens, token{text: op, op: true})
+				i += len(op)
+				continue
+			}
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\"'", rune(s[j])) && operatorAt(s[j:]) == "" {
+				j++
+			}
+			tokens = append(tokens, token{text: s[i:j]})
+			i = j
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
+
+// operatorAt returns the operator at the start of s, if any. Single & and | characters are not operators.
+func operatorAt(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	a = &dns.A{
+		Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   net.IPv4(192, 0, 2, 1),
+	}
+	mx10 = &dns.MX{
+		Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeMX, Class: dns.ClassINET, Ttl: 3600},
+		Preference: 10,
+		Mx:         "mx1.example.com.",
+	}
+	mx30 = &dns.MX{
+		Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeMX, Class: dns.ClassINET, Ttl: 3600},
+		Preference: 30,
+		Mx:         "backup.example.net.",
+	}
+	txt = &dns.TXT{
+		Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 300},
+		Txt: []string{"v=spf1 -all"},
+	}
+	cname = &dns.CNAME{
+		Hdr:    dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 300},
+		Target: "example.com.",
+	}
+	records = []dns.RR{a, mx10, mx30, txt, cname}
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []dns.RR
+	}{
+		{"type=MX", []dns.RR{mx10, mx30}},
+		{"type=mx", []dns.RR{mx10, mx30}},
+		{"type!=MX", []dns.RR{a, txt, cname}},
+		{"type=MX && preference<20", []dns.RR{mx10}},
+		{"preference>=30", []dns.RR{mx30}},
+		{"preference=10", []dns.RR{mx10}},
+		{"preference!=10", []dns.RR{mx30}},
+		{"ttl<=300", []dns.RR{a, txt, cname}},
+		{"ttl>300 || type=A", []dns.RR{a, mx10, mx30}},
+		{"name=www.example.com", []dns.RR{cname}},
+		{"name=WWW.example.com.", []dns.RR{cname}},
+		{`value~"^v=spf1"`, []dns.RR{txt}},
+		{`value="v=spf1 -all"`, []dns.RR{txt}},
+		{`value~'example\.net\.$'`, []dns.RR{mx30}},
+		{"value!~example", []dns.RR{a, txt}},
+		{"value=192.0.2.1", []dns.RR{a}},
+		{"!type=MX && !(type=TXT || type=CNAME)", []dns.RR{a}},
+		{"(type=A || type=TXT) && ttl<100", []dns.RR{a}},
+		{"class=IN && type=CNAME", []dns.RR{cname}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, Filter(expr, records))
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "error: invalid filter: empty expression"},
+		{"type", "error: invalid filter: incomplete condition"},
+		{"color=red", `error: invalid filter: unknown field "color"`},
+		{"type MX TXT", `error: invalid filter: expected an operator after "type"`},
+		{"type=", "error: invalid filter: incomplete condition"},
+		{"type=&&", `error: invalid filter: expected a value after "="`},
+		{"type<MX", "error: invalid filter: operator < is only supported by the ttl and preference fields"},
+		{"ttl>60 hour", `error: invalid filter: unexpected "hour"`},
+		{"ttl>hour", `error: invalid filter: ttl must be compared with a number, got "hour"`},
+		{"(type=MX", "error: invalid filter: missing closing parenthesis"},
+		{"type=MX)", `error: invalid filter: unexpected ")"`},
+		{`value~"spf`, "error: invalid filter: unterminated string"},
+		{"value~[", "error: invalid filter: error parsing regexp: missing closing ]: `[`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package mail
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// MaxSPFDepth is the maximum number of nested include: and redirect= terms ExpandSPF follows.
+// Real records never come close, so the limit only stops include loops that the loop detection misses.
+const MaxSPFDepth = 10
+
+// MaxSPFMX is the maximum number of MX records an mx mechanism may resolve to, see RFC 7208, section 4.6.4.
+const MaxSPFMX = 10
+
+// Resolver looks up records, as needed to expand SPF records.
+type Resolver interface {
+	// Lookup returns the records of a type owned by a name. A name that does not exist has no records, and is not an error.
+	Lookup(name string, qtype uint16) ([]dns.RR, error)
+}
+
+// SPFResult is the result of evaluating an SPF record for a sender, see RFC 7208, section 2.6.
+type SPFResult string
+
+const (
+	SPFNone      SPFResult = "none"
+	SPFNeutral   SPFResult = "neutral"
+	SPFPass      SPFResult = "pass"
+	SPFFail      SPFResult = "fail"
+	SPFSoftFail  SPFResult = "softfail"
+	SPFTempError SPFResult = "temperror"
+	SPFPermError SPFResult = "permerror"
+)
+
+// qualifierResults maps the qualifiers of mechanisms to the result of a match.
+var qualifierResults = map[string]SPFResult{"": SPFPass, "+": SPFPass, "-": SPFFail, "~": SPFSoftFail, "?": SPFNeutral}
+
+// Result returns the result of a matching mechanism.
+func (t SPFTerm) Result() SPFResult {
+	return qualifierResults[t.Qualifier]
+}
+
+// SPFRecord is the SPF record of a domain, expanded along with the records it includes.
+type SPFRecord struct {
+	Domain string `json:"domain"`
+
+	// Record is the SPF record of the domain. It is empty if the domain has no single, valid SPF record.
+	Record string `json:"record,omitempty"`
+
+	Terms []SPFExpansion `json:"terms"`
+
+	// Error describes why the record could not be expanded, e.g. because the domain has no SPF record.
+	Error string `json:"error,omitempty"`
+
+	// result is the result of evaluating the record, if it could not be expanded.
+	result SPFResult
+}
+
+// SPFExpansion is a term of an SPF record, along with the results of the lookups it causes.
+type SPFExpansion struct {
+	SPFTerm
+
+	// Addresses holds the addresses a mechanism matches: the addresses of a and mx mechanisms,
+	// and those of ip4 and ip6 mechanisms.
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Exists is set if the domain of an exists mechanism has an A record.
+	Exists bool `json:"exists,omitempty"`
+
+	// Include is the expanded record of an include mechanism or a redirect modifier.
+	Include *SPFRecord `json:"include,omitempty"`
+
+	// Error describes why the term could not be expanded.
+	Error string `json:"error,omitempty"`
+
+	// result is the result of evaluating the term, if it could not be expanded.
+	result SPFResult
+}
+
+// ExpandSPF looks up the SPF record of a domain, and recursively expands the terms that cause DNS lookups:
+// include:, redirect=, a, mx and exists. Lookup failures are recorded in the tree, rather than returned.
+func ExpandSPF(r Resolver, domain string) *SPFRecord {
+	return expandSPF(r, dns.Fqdn(domain), nil)
+}
+
+func expandSPF(r Resolver, domain string, path []string) *SPFRecord {
+	record := &SPFRecord{Domain: domain, Terms: []SPFExpansion{}}
+	fail := func(result SPFResult, format string, args ...any) *SPFRecord {
+		record.Error, record.result = fmt.Sprintf(format, args...), result
+		return record
+	}
+
+	for _, seen := range path {
+		if strings.EqualFold(seen, domain) {
+			return fail(SPFPermError, "include loop")
+		}
+	}
+	if len(path) > MaxSPFDepth {
+		return fail(SPFPermError, "more than %d nested includes", MaxSPFDepth)
+	}
+	path = append(path, domain)
+
+	rrs, err := r.Lookup(domain, dns.TypeTXT)
+	if err != nil {
+		return fail(SPFTempError, "%v", err)
+	}
+	records := versioned(texts(rrs), "v=spf1")
+	switch {
+	case len(records) == 0:
+		return fail(SPFNone, "no SPF record")
+	case len(records) > 1:
+		return fail(SPFPermError, "%d SPF records", len(records))
+	}
+	record.Record = records[0]
+
+	terms, err := ParseSPF(record.Record)
+	if err != nil {
+		return fail(SPFPermError, "%v", err)
+	}
+
+	for _, term := range terms {
+		record.Terms = append(record.Terms, expandTerm(r, domain, term, path))
+	}
+	return record
+}
+
+// expandTerm looks up what a single term of the SPF record of domain refers to.
+func expandTerm(r Resolver, domain string, term SPFTerm, path []string) SPFExpansion {
+	e := SPFExpansion{SPFTerm: term}
+	fail := func(result SPFResult, format string, args ...any) SPFExpansion {
+		e.Error, e.result = fmt.Sprintf(format, args...), result
+		return e
+	}
+
+	target, cidr4, cidr6 := splitDomainSpec(term.Value)
+	if target == "" {
+		target = domain
+	}
+	target = dns.Fqdn(target)
+	if strings.Contains(target, "%") {
+		return fail(SPFPermError, "macros are not supported")
+	}
+
+	switch {
+	case term.Modifier && term.Name != "redirect":
+		// Other modifiers, such as exp=, do not affect the result.
+	case term.Name == "include", term.Name == "redirect":
+		if term.Value == "" {
+			return fail(SPFPermError, "missing domain")
+		}
+		e.Include = expandSPF(r, target, path)
+	case term.Name == "ip4", term.Name == "ip6":
+		if _, _, err := net.ParseCIDR(cidr(term.Value)); err != nil {
+			return fail(SPFPermError, "invalid network %s", term.Value)
+		}
+		e.Addresses = []string{term.Value}
+	case term.Name == "a":
+		addresses, err := lookupAddresses(r, target, cidr4, cidr6)
+		if err != nil {
+			return fail(SPFTempError, "%v", err)
+		}
+		e.Addresses = addresses
+	case term.Name == "mx":
+		rrs, err := r.Lookup(target, dns.TypeMX)
+		if err != nil {
+			return fail(SPFTempError, "%v", err)
+		}
+		if len(rrs) > MaxSPFMX {
+			return fail(SPFPermError, "%d MX records, more than the limit of %d", len(rrs), MaxSPFMX)
+		}
+		for _, rr := range rrs {
+			addresses, err := lookupAddresses(r, rr.(*dns.MX).Mx, cidr4, cidr6)
+			if err != nil {
+				return fail(SPFTempError, "%v", err)
+			}
+			e.Addresses = append(e.Addresses, addresses...)
+		}
+	case term.Name == "exists":
+		rrs, err := r.Lookup(target, dns.TypeA)
+		if err != nil {
+			return fail(SPFTempError, "%v", err)
+		}
+		e.Exists = len(rrs) > 0
+	case term.Name == "ptr":
+		// The ptr mechanism depends on the reverse zone of the sender, and is not expanded.
+	}
+	return e
+}
+
+// lookupAddresses returns the IPv4 and IPv6 addresses of a name as networks, with the given prefix lengths.
+func lookupAddresses(r Resolver, name string, cidr4, cidr6 int) ([]string, error) {
+	var addresses []string
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		rrs, err := r.Lookup(name, qtype)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range rrs {
+			switch rr := rr.(type) {
+			case *dns.A:
+				addresses = append(addresses, withPrefix(rr.A.String(), cidr4, 32))
+			case *dns.AAAA:
+				addresses = append(addresses, withPrefix(rr.AAAA.String(), cidr6, 128))
+			}
+		}
+	}
+	return addresses, nil
+}
+
+// withPrefix appends a prefix length to an address, unless it covers the address only.
+func withPrefix(address string, prefix, bits int) string {
+	if prefix == bits {
+		return address
+	}
+	return address + "/" + strconv.Itoa(prefix)
+}
+
+// splitDomainSpec splits the value of an a or mx mechanism, e.g. example.com/24//64, into its domain
+// and prefix lengths. The prefix lengths default to a single address.
+func splitDomainSpec(value string) (domain string, cidr4, cidr6 int) {
+	cidr4, cidr6 = 32, 128
+	domain, lengths, _ := strings.Cut(value, "/")
+	if lengths == "" {
+		return domain, cidr4, cidr6
+	}
+	// What follows the first slash is the IPv4 length, optionally followed by //, and the IPv6 length.
+	v4, v6, _ := strings.Cut("/"+lengths, "//")
+	if n, err := strconv.Atoi(strings.TrimPrefix(v4, "/")); err == nil {
+		cidr4 = n
+	}
+	if n, err := strconv.Atoi(v6); err == nil {
+		cidr6 = n
+	}
+	return domain, cidr4, cidr6
+}
+
+// cidr returns a network in CIDR notation, adding the prefix length of a single address if it has none.
+func cidr(network string) string {
+	if strings.Contains(network, "/") {
+		return network
+	}
+	if strings.Contains(network, ":") {
+		return network + "/128"
+	}
+	return network + "/32"
+}
+
+// Lookups returns the number of terms that cause DNS lookups in the record and in all the records it includes,
+// to be compared with SPFLookupLimit.
+func (r *SPFRecord) Lookups() int {
+	n := 0
+	for _, e := range r.Terms {
+		if e.Lookup() {
+			n++
+		}
+		if e.Include != nil {
+			n += e.Include.Lookups()
+		}
+	}
+	return n
+}
+
+// SPFReport holds an expanded SPF record, and optionally its evaluation for a sender.
+type SPFReport struct {
+	Domain      string         `json:"domain"`
+	Record      *SPFRecord     `json:"record"`
+	Lookups     int            `json:"lookups"`
+	LookupLimit int            `json:"lookup_limit"`
+	Evaluation  *SPFEvaluation `json:"evaluation,omitempty"`
+}
+
+// SPFEvaluation is the result of evaluating an SPF record for the address of a sender.
+type SPFEvaluation struct {
+	IP     string    `json:"ip"`
+	Result SPFResult `json:"result"`
+
+	// Domain and Term are the record and the term that decided the result. They are empty if no term matched.
+	Domain string `json:"domain,omitempty"`
+	Term   string `json:"term,omitempty"`
+}
+
+// Evaluate evaluates the expanded record for the address of a sender, as receivers do with check_host(),
+// see RFC 7208, section 4. Records that need more than SPFLookupLimit lookups are a permanent error.
+// Macros are not supported, and the ptr mechanism never matches.
+func (r *SPFRecord) Evaluate(ip net.IP) SPFEvaluation {
+	if r.Lookups() > SPFLookupLimit {
+		return SPFEvaluation{IP: ip.String(), Result: SPFPermError, Domain: r.Domain}
+	}
+	eval := r.evaluate(ip)
+	eval.IP = ip.String()
+	return eval
+}
+
+func (r *SPFRecord) evaluate(ip net.IP) SPFEvaluation {
+	if r.Error != "" {
+		return SPFEvaluation{Result: r.result, Domain: r.Domain}
+	}
+
+	var redirect *SPFExpansion
+	for i, e := range r.Terms {
+		if e.Error != "" {
+			return SPFEvaluation{Result: e.result, Domain: r.Domain, Term: e.String()}
+		}
+		if e.Modifier {
+			if e.Name == "redirect" {
+				redirect = &r.Terms[i]
+			}
+			continue
+		}
+
+		matched := false
+		eval := SPFEvaluation{Result: e.Result(), Domain: r.Domain, Term: e.String()}
+		switch e.Name {
+		case "all":
+			matched = true
+		case "ip4", "ip6", "a", "mx":
+			matched = contains(e.Addresses, ip)
+		case "exists":
+			matched = e.Exists
+		case "include":
+			included := e.Include.evaluate(ip)
+			switch included.Result {
+			case SPFPass:
+				// The result is the qualifier of the include mechanism, but the term that matched is reported.
+				matched = true
+				eval.Domain, eval.Term = included.Domain, included.Term
+			case SPFTempError, SPFPermError:
+				return included
+			case SPFNone:
+				return SPFEvaluation{Result: SPFPermError, Domain: e.Include.Domain}
+			}
+		}
+		if matched {
+			return eval
+		}
+	}
+
+	// The redirect modifier only applies if no mechanism matched, so it is ignored by records with an all mechanism.
+	if redirect != nil {
+		redirected := redirect.Include.evaluate(ip)
+		if redirected.Result == SPFNone {
+			redirected.Result = SPFPermError
+		}
+		return redirected
+	}
+	return SPFEvaluation{Result: SPFNeutral, Domain: r.Domain}
+}
+
+// contains reports whether any of the networks contains the address.
+func contains(networks []string, ip net.IP) bool {
+	for _, network := range networks {
+		if _, n, err := net.ParseCIDR(cidr(network)); err == nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
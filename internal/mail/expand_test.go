@@ -0,0 +1,119 @@
+package mail
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/dnstest"
+)
+
+// testSPFZone is an SPF record that includes another one, and uses every mechanism that causes lookups.
+var testSPFZone = []string{
+	`example.com. 300 IN TXT "v=spf1 include:_spf.example.net a mx/24 exists:relay.example.com ~all"`,
+	`example.com. 300 IN TXT "google-site-verification=abc"`,
+	`example.com. 300 IN A 192.0.2.1`,
+	`example.com. 300 IN MX 10 mx.example.com.`,
+	`mx.example.com. 300 IN A 198.51.100.25`,
+	`mx.example.com. 300 IN AAAA 2001:db8::25`,
+	`_spf.example.net. 300 IN TXT "v=spf1 ip4:203.0.113.0/24 ip6:2001:db8:1::/48 -all"`,
+}
+
+func TestExpandSPF(t *testing.T) {
+	record := ExpandSPF(dnstest.NewZone(t, testSPFZone...), "example.com")
+
+	assert.Equal(t, "example.com.", record.Domain)
+	assert.Equal(t, "v=spf1 include:_spf.example.net a mx/24 exists:relay.example.com ~all", record.Record)
+	assert.Empty(t, record.Error)
+	assert.Len(t, record.Terms, 5)
+
+	include := record.Terms[0].Include
+	if assert.NotNil(t, include) {
+		assert.Equal(t, "_spf.example.net.", include.Domain)
+		assert.Equal(t, []string{"203.0.113.0/24"}, include.Terms[0].Addresses)
+		assert.Equal(t, []string{"2001:db8:1::/48"}, include.Terms[1].Addresses)
+	}
+	assert.Equal(t, []string{"192.0.2.1"}, record.Terms[1].Addresses)
+	assert.Equal(t, []string{"198.51.100.25/24", "2001:db8::25"}, record.Terms[2].Addresses)
+	assert.False(t, record.Terms[3].Exists)
+	assert.Equal(t, 4, record.Lookups())
+}
+
+func TestExpandSPF_Errors(t *testing.T) {
+	r := dnstest.NewZone(t,
+		`example.com. 300 IN TXT "v=spf1 include:loop.example.com include:missing.example.com a:broken.example.com ip4:300.0.0.1 -all"`,
+		`loop.example.com. 300 IN TXT "v=spf1 include:example.com -all"`,
+		`twice.example.com. 300 IN TXT "v=spf1 -all"`,
+		`twice.example.com. 300 IN TXT "v=spf1 ~all"`,
+	)
+	r.Errs["broken.example.com."] = true
+
+	record := ExpandSPF(r, "example.com")
+
+	assert.Equal(t, "include loop", record.Terms[0].Include.Terms[0].Include.Error)
+	assert.Equal(t, "no SPF record", record.Terms[1].Include.Error)
+	assert.Equal(t, "SERVFAIL looking up A broken.example.com.", record.Terms[2].Error)
+	assert.Equal(t, "invalid network 300.0.0.1", record.Terms[3].Error)
+
+	assert.Equal(t, "2 SPF records", ExpandSPF(r, "twice.example.com").Error)
+	assert.Equal(t, "no SPF record", ExpandSPF(r, "example.org").Error)
+}
+
+func TestSPFRecord_Evaluate(t *testing.T) {
+	record := ExpandSPF(dnstest.NewZone(t, testSPFZone...), "example.com")
+
+	tests := []struct {
+		ip   string
+		want SPFEvaluation
+	}{
+		{"203.0.113.7", SPFEvaluation{IP: "203.0.113.7", Result: SPFPass, Domain: "_spf.example.net.", Term: "ip4:203.0.113.0/24"}},
+		{"192.0.2.1", SPFEvaluation{IP: "192.0.2.1", Result: SPFPass, Domain: "example.com.", Term: "a"}},
+		{"198.51.100.99", SPFEvaluation{IP: "198.51.100.99", Result: SPFPass, Domain: "example.com.", Term: "mx/24"}},
+		{"2001:db8::25", SPFEvaluation{IP: "2001:db8::25", Result: SPFPass, Domain: "example.com.", Term: "mx/24"}},
+		{"10.0.0.1", SPFEvaluation{IP: "10.0.0.1", Result: SPFSoftFail, Domain: "example.com.", Term: "~all"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, record.Evaluate(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestSPFRecord_Evaluate_Results(t *testing.T) {
+	r := dnstest.NewZone(t,
+		`fail.example.com. 300 IN TXT "v=spf1 -include:_spf.example.net ?all"`,
+		`neutral.example.com. 300 IN TXT "v=spf1 ip4:192.0.2.0/24"`,
+		`redirect.example.com. 300 IN TXT "v=spf1 redirect=_spf.example.net"`,
+		`exists.example.com. 300 IN TXT "v=spf1 exists:relay.example.com -all"`,
+		`relay.example.com. 300 IN A 127.0.0.2`,
+		`broken.example.com. 300 IN TXT "v=spf1 include:none.example.com -all"`,
+		`_spf.example.net. 300 IN TXT "v=spf1 ip4:203.0.113.0/24 -all"`,
+	)
+	ip := net.ParseIP("203.0.113.7")
+
+	tests := []struct {
+		domain string
+		want   SPFResult
+	}{
+		{"fail.example.com", SPFFail},
+		{"neutral.example.com", SPFNeutral},
+		{"redirect.example.com", SPFPass},
+		{"exists.example.com", SPFPass},
+		{"broken.example.com", SPFPermError},
+		{"none.example.com", SPFNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExpandSPF(r, tt.domain).Evaluate(ip).Result)
+		})
+	}
+}
+
+func TestSPFRecord_Evaluate_LookupLimit(t *testing.T) {
+	r := dnstest.NewZone(t, `example.com. 300 IN TXT "v=spf1 a a a a a a a a a a a -all"`)
+
+	record := ExpandSPF(r, "example.com")
+
+	assert.Equal(t, 11, record.Lookups())
+	assert.Equal(t, SPFEvaluation{IP: "192.0.2.1", Result: SPFPermError, Domain: "example.com."}, record.Evaluate(net.ParseIP("192.0.2.1")))
+}
@@ -0,0 +1,366 @@
+// Package mail audits the records that route, authenticate and secure the email of a domain:
+// MX, SPF, DMARC, DKIM, MTA-STS, TLS-RPT and BIMI.
+package mail
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// Severity is how serious a problem found by an audit is.
+type Severity string
+
+const (
+	// SeverityError is a problem that breaks the delivery or the authentication of email.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is a problem that weakens the protection of the domain.
+	SeverityWarning Severity = "warning"
+
+	// SeverityInfo is a note about an optional record that is missing.
+	SeverityInfo Severity = "info"
+)
+
+// Problem is a single problem found by an audit.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Check holds the records of a single mechanism, such as SPF or the DKIM key of a selector,
+// along with the problems found in them.
+type Check struct {
+	// Name is the name of the mechanism, e.g. SPF. DKIM checks are named after their selector, e.g. DKIM (google).
+	Name string `json:"name"`
+
+	// Owner is the name the records were looked up at.
+	Owner string `json:"owner"`
+
+	// Records holds the records of the mechanism. The strings of TXT records are concatenated, as receivers do.
+	Records []string `json:"records"`
+
+	Problems []Problem `json:"problems"`
+}
+
+// Report holds the results of an audit.
+type Report struct {
+	Domain string  `json:"domain"`
+	Checks []Check `json:"checks"`
+}
+
+// Count returns the number of problems of a severity across all checks.
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, c := range r.Checks {
+		for _, p := range c.Problems {
+			if p.Severity == severity {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Answers holds the answers to the questions of an audit.
+type Answers map[query.Question][]dns.RR
+
+// Questions returns the queries an audit of a domain needs, for the given DKIM selectors.
+func Questions(domain string, selectors []string) []query.Question {
+	questions := []query.Question{
+		{Name: domain, Qtype: dns.TypeMX},
+		{Name: domain, Qtype: dns.TypeTXT},
+		{Name: "_dmarc." + domain, Qtype: dns.TypeTXT},
+		{Name: "_mta-sts." + domain, Qtype: dns.TypeTXT},
+		{Name: "_smtp._tls." + domain, Qtype: dns.TypeTXT},
+		{Name: "default._bimi." + domain, Qtype: dns.TypeTXT},
+	}
+	for _, selector := range selectors {
+		questions = append(questions, query.Question{Name: selector + "._domainkey." + domain, Qtype: dns.TypeTXT})
+	}
+	return questions
+}
+
+// Audit checks the email records of a domain, given the answers to its Questions.
+func Audit(domain string, selectors []string, answers Answers) *Report {
+	txt := func(name string) []string {
+		return texts(answers[query.Question{Name: name, Qtype: dns.TypeTXT}])
+	}
+
+	dmarc, policy := checkDMARC(dns.Fqdn("_dmarc."+domain), txt("_dmarc."+domain))
+
+	report := &Report{Domain: dns.Fqdn(domain)}
+	report.Checks = append(report.Checks,
+		checkMX(dns.Fqdn(domain), answers[query.Question{Name: domain, Qtype: dns.TypeMX}]),
+		checkSPF(dns.Fqdn(domain), txt(domain)),
+		dmarc,
+	)
+
+	if len(selectors) == 0 {
+		report.Checks = append(report.Checks, Check{
+			Name:     "DKIM",
+			Problems: []Problem{{SeverityInfo, "no DKIM selectors given, DKIM keys were not checked"}},
+		})
+	}
+	for _, selector := range selectors {
+		name := selector + "._domainkey." + domain
+		report.Checks = append(report.Checks, checkDKIM(selector, dns.Fqdn(name), txt(name)))
+	}
+
+	report.Checks = append(report.Checks,
+		checkMTASTS(dns.Fqdn("_mta-sts."+domain), txt("_mta-sts."+domain)),
+		checkTLSRPT(dns.Fqdn("_smtp._tls."+domain), txt("_smtp._tls."+domain)),
+		checkBIMI(dns.Fqdn("default._bimi."+domain), txt("default._bimi."+domain), policy),
+	)
+
+	// Empty lists are kept in the JSON output, rather than written as null.
+	for i := range report.Checks {
+		if report.Checks[i].Records == nil {
+			report.Checks[i].Records = []string{}
+		}
+		if report.Checks[i].Problems == nil {
+			report.Checks[i].Problems = []Problem{}
+		}
+	}
+
+	return report
+}
+
+// texts returns the TXT records among records, with their strings concatenated.
+// Records of other types, such as the CNAME records of an alias, are skipped.
+func texts(records []dns.RR) []string {
+	var out []string
+	for _, rr := range records {
+		if txt, ok := rr.(*dns.TXT); ok {
+			out = append(out, strings.Join(txt.Txt, ""))
+		}
+	}
+	return out
+}
+
+// versioned returns the records that start with a version tag, e.g. v=DMARC1, ignoring case.
+func versioned(records []string, version string) []string {
+	var out []string
+	for _, record := range records {
+		rest, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(record)), strings.ToLower(version))
+		if ok && (rest == "" || rest[0] == ';' || rest[0] == ' ') {
+			out = append(out, record)
+		}
+	}
+	return out
+}
+
+func (c *Check) add(severity Severity, format string, args ...any) {
+	c.Problems = append(c.Problems, Problem{severity, fmt.Sprintf(format, args...)})
+}
+
+func checkMX(owner string, records []dns.RR) Check {
+	c := Check{Name: "MX", Owner: owner}
+	for _, rr := range records {
+		if mx, ok := rr.(*dns.MX); ok {
+			c.Records = append(c.Records, fmt.Sprintf("%d %s", mx.Preference, mx.Mx))
+			// A null MX (RFC 7505) states that the domain does not accept email.
+			if mx.Mx == "." && len(records) == 1 {
+				c.add(SeverityInfo, "null MX record, the domain does not accept email")
+			}
+		}
+	}
+	if len(c.Records) == 0 {
+		c.add(SeverityWarning, "no MX records, email is delivered to the address of the domain itself")
+	}
+	return c
+}
+
+func checkSPF(owner string, records []string) Check {
+	c := Check{Name: "SPF", Owner: owner, Records: versioned(records, "v=spf1")}
+	switch {
+	case len(c.Records) == 0:
+		c.add(SeverityError, "no SPF record, receivers cannot tell which servers may send email for the domain")
+		return c
+	case len(c.Records) > 1:
+		c.add(SeverityError, "%d SPF records, receivers treat multiple records as a permanent error", len(c.Records))
+	}
+
+	for _, record := range c.Records {
+		terms, err := ParseSPF(record)
+		if err != nil {
+			c.add(SeverityError, "invalid SPF record: %v", err)
+			continue
+		}
+
+		if n := SPFLookups(terms); n > SPFLookupLimit {
+			c.add(SeverityError, "%d DNS-lookup mechanisms, more than the limit of %d", n, SPFLookupLimit)
+		}
+
+		var all *SPFTerm
+		redirect := false
+		for i, term := range terms {
+			switch {
+			case term.Name == "all" && !term.Modifier:
+				all = &terms[i]
+			case term.Name == "redirect" && term.Modifier:
+				redirect = true
+			case term.Name == "ptr" && !term.Modifier:
+				c.add(SeverityWarning, "the ptr mechanism is slow and deprecated")
+			}
+		}
+		switch {
+		case all == nil && !redirect:
+			c.add(SeverityWarning, "no all mechanism, email from other servers gets a neutral result")
+		case all != nil && all.Result() == SPFPass:
+			c.add(SeverityError, "+all allows any server to send email for the domain")
+		case all != nil && all.Result() == SPFNeutral:
+			c.add(SeverityWarning, "?all gives email from other servers a neutral result")
+		}
+	}
+	return c
+}
+
+// checkDMARC checks the DMARC record of a domain, and returns its policy. The policy is empty if there is no valid record.
+func checkDMARC(owner string, records []string) (Check, string) {
+	c := Check{Name: "DMARC", Owner: owner, Records: versioned(records, "v=DMARC1")}
+	switch {
+	case len(c.Records) == 0:
+		c.add(SeverityError, "no DMARC record, receivers apply their own policy to email failing SPF and DKIM")
+		return c, ""
+	case len(c.Records) > 1:
+		c.add(SeverityError, "%d DMARC records, receivers ignore DMARC if there is more than one", len(c.Records))
+		return c, ""
+	}
+
+	tags, err := ParseTags(c.Records[0])
+	if err != nil {
+		c.add(SeverityError, "invalid DMARC record: %v", err)
+		return c, ""
+	}
+
+	// Policies are case-insensitive, like the other values of the DMARC record.
+	policy, ok := lookupTag(tags, "p")
+	policy = strings.ToLower(policy)
+	switch {
+	case !ok:
+		c.add(SeverityError, "missing p tag")
+	case policy == "none":
+		c.add(SeverityWarning, "p=none only monitors, email failing authentication is still delivered")
+	case policy != "quarantine" && policy != "reject":
+		c.add(SeverityError, "invalid policy p=%s", policy)
+		policy = ""
+	}
+	if sp, ok := lookupTag(tags, "sp"); ok && !slices.Contains([]string{"none", "quarantine", "reject"}, strings.ToLower(sp)) {
+		c.add(SeverityError, "invalid subdomain policy sp=%s", sp)
+	}
+	if pct, ok := lookupTag(tags, "pct"); ok && pct != "100" {
+		if n, err := strconv.Atoi(pct); err != nil || n < 0 || n > 100 {
+			c.add(SeverityError, "invalid pct=%s", pct)
+		} else {
+			c.add(SeverityWarning, "pct=%d applies the policy to only %d%% of failing email", n, n)
+		}
+	}
+	if _, ok := lookupTag(tags, "rua"); !ok {
+		c.add(SeverityWarning, "missing rua tag, no aggregate reports are sent")
+	}
+	return c, policy
+}
+
+func checkDKIM(selector string, owner string, records []string) Check {
+	c := Check{Name: fmt.Sprintf("DKIM (%s)", selector), Owner: owner, Records: records}
+	switch {
+	case len(records) == 0:
+		c.add(SeverityError, "no DKIM key for selector %s", selector)
+		return c
+	case len(records) > 1:
+		c.add(SeverityError, "%d DKIM records, receivers fail verification if there is more than one", len(records))
+		return c
+	}
+
+	tags, err := ParseTags(records[0])
+	if err != nil {
+		c.add(SeverityError, "invalid DKIM record: %v", err)
+		return c
+	}
+	if v, ok := lookupTag(tags, "v"); ok && v != "DKIM1" {
+		c.add(SeverityError, "invalid version v=%s", v)
+	}
+	if key, ok := lookupTag(tags, "p"); !ok {
+		c.add(SeverityError, "missing p tag")
+	} else if key == "" {
+		c.add(SeverityWarning, "the key was revoked")
+	}
+	if flags, ok := lookupTag(tags, "t"); ok && strings.Contains(flags, "y") {
+		c.add(SeverityWarning, "t=y marks the domain as testing DKIM, receivers may ignore failures")
+	}
+	return c
+}
+
+func checkMTASTS(owner string, records []string) Check {
+	c := Check{Name: "MTA-STS", Owner: owner, Records: versioned(records, "v=STSv1")}
+	switch {
+	case len(c.Records) == 0:
+		c.add(SeverityInfo, "no MTA-STS record, sending servers do not require TLS")
+		return c
+	case len(c.Records) > 1:
+		c.add(SeverityError, "%d MTA-STS records, sending servers ignore MTA-STS if there is more than one", len(c.Records))
+		return c
+	}
+
+	tags, err := ParseTags(c.Records[0])
+	if err != nil {
+		c.add(SeverityError, "invalid MTA-STS record: %v", err)
+	} else if id, _ := lookupTag(tags, "id"); id == "" {
+		c.add(SeverityError, "missing id tag")
+	}
+	return c
+}
+
+func checkTLSRPT(owner string, records []string) Check {
+	c := Check{Name: "TLS-RPT", Owner: owner, Records: versioned(records, "v=TLSRPTv1")}
+	switch {
+	case len(c.Records) == 0:
+		c.add(SeverityInfo, "no TLS-RPT record, no reports about TLS failures are sent")
+		return c
+	case len(c.Records) > 1:
+		c.add(SeverityError, "%d TLS-RPT records, no reports are sent if there is more than one", len(c.Records))
+		return c
+	}
+
+	tags, err := ParseTags(c.Records[0])
+	if err != nil {
+		c.add(SeverityError, "invalid TLS-RPT record: %v", err)
+	} else if rua, _ := lookupTag(tags, "rua"); rua == "" {
+		c.add(SeverityError, "missing rua tag")
+	}
+	return c
+}
+
+// checkBIMI checks the BIMI record of a domain. BIMI logos are only shown for email protected by a DMARC policy
+// of quarantine or reject.
+func checkBIMI(owner string, records []string, policy string) Check {
+	c := Check{Name: "BIMI", Owner: owner, Records: versioned(records, "v=BIMI1")}
+	switch {
+	case len(c.Records) == 0:
+		c.add(SeverityInfo, "no BIMI record")
+		return c
+	case len(c.Records) > 1:
+		c.add(SeverityError, "%d BIMI records, receivers ignore BIMI if there is more than one", len(c.Records))
+		return c
+	}
+
+	tags, err := ParseTags(c.Records[0])
+	if err != nil {
+		c.add(SeverityError, "invalid BIMI record: %v", err)
+		return c
+	}
+	if l, ok := lookupTag(tags, "l"); !ok {
+		c.add(SeverityError, "missing l tag")
+	} else if l != "" && !strings.HasPrefix(l, "https://") {
+		c.add(SeverityError, "the logo l=%s must be served over HTTPS", l)
+	}
+	if policy != "quarantine" && policy != "reject" {
+		c.add(SeverityWarning, "BIMI requires a DMARC policy of quarantine or reject")
+	}
+	return c
+}
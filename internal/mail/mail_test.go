@@ -0,0 +1,174 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/dnstest"
+	"github.com/znscli/zns/internal/query"
+)
+
+// newAnswers parses records in zone file format into the answers of an audit of example.com.
+func newAnswers(t *testing.T, records ...string) Answers {
+	answers := make(Answers)
+	for _, rr := range dnstest.Parse(t, records...) {
+		q := query.Question{Name: strings.TrimSuffix(rr.Header().Name, "."), Qtype: rr.Header().Rrtype}
+		answers[q] = append(answers[q], rr)
+	}
+	return answers
+}
+
+// problems returns the problems of the check with the given name.
+func problems(report *Report, name string) []Problem {
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c.Problems
+		}
+	}
+	return nil
+}
+
+func TestQuestions(t *testing.T) {
+	questions := Questions("example.com", []string{"google"})
+
+	assert.Len(t, questions, 7)
+	assert.Contains(t, questions, query.Question{Name: "_dmarc.example.com", Qtype: dns.TypeTXT})
+	assert.Contains(t, questions, query.Question{Name: "google._domainkey.example.com", Qtype: dns.TypeTXT})
+}
+
+func TestAudit_Clean(t *testing.T) {
+	answers := newAnswers(t,
+		"example.com. 300 IN MX 10 mx.example.com.",
+		`example.com. 300 IN TXT "v=spf1 mx " "-all"`,
+		`_dmarc.example.com. 300 IN TXT "v=DMARC1; p=reject; rua=mailto:dmarc@example.com"`,
+		`google._domainkey.example.com. 300 IN TXT "v=DKIM1; k=rsa; p=MIGf"`,
+		`_mta-sts.example.com. 300 IN TXT "v=STSv1; id=20240101"`,
+		`_smtp._tls.example.com. 300 IN TXT "v=TLSRPTv1; rua=mailto:tls@example.com"`,
+		`default._bimi.example.com. 300 IN TXT "v=BIMI1; l=https://example.com/logo.svg"`,
+	)
+
+	report := Audit("example.com", []string{"google"}, answers)
+
+	assert.Equal(t, "example.com.", report.Domain)
+	assert.Equal(t, 0, report.Count(SeverityError))
+	assert.Equal(t, 0, report.Count(SeverityWarning))
+	assert.Equal(t, 0, report.Count(SeverityInfo))
+	assert.Equal(t, []string{"v=spf1 mx -all"}, report.Checks[1].Records)
+}
+
+func TestAudit_Missing(t *testing.T) {
+	report := Audit("example.com", nil, newAnswers(t))
+
+	assert.Equal(t, []Problem{{SeverityWarning, "no MX records, email is delivered to the address of the domain itself"}}, problems(report, "MX"))
+	assert.Equal(t, []Problem{{SeverityError, "no SPF record, receivers cannot tell which servers may send email for the domain"}}, problems(report, "SPF"))
+	assert.Equal(t, []Problem{{SeverityInfo, "no DKIM selectors given, DKIM keys were not checked"}}, problems(report, "DKIM"))
+	assert.Equal(t, []Problem{{SeverityInfo, "no BIMI record"}}, problems(report, "BIMI"))
+	assert.Equal(t, []string{}, report.Checks[0].Records)
+}
+
+func TestAudit_SPF(t *testing.T) {
+	testCases := []struct {
+		record string
+		want   []Problem
+	}{
+		{"v=spf1 +all", []Problem{{SeverityError, "+all allows any server to send email for the domain"}}},
+		{"v=spf1 mx", []Problem{{SeverityWarning, "no all mechanism, email from other servers gets a neutral result"}}},
+		{"v=spf1 redirect=_spf.example.com", []Problem{}},
+		{"v=spf1 ptr ?all", []Problem{
+			{SeverityWarning, "the ptr mechanism is slow and deprecated"},
+			{SeverityWarning, "?all gives email from other servers a neutral result"},
+		}},
+		{"v=spf1 a mx include:a include:b include:c include:d include:e include:f include:g include:h exists:i -all", []Problem{
+			{SeverityError, "11 DNS-lookup mechanisms, more than the limit of 10"},
+		}},
+		{"v=spf1 bogus -all", []Problem{{SeverityError, `invalid SPF record: unknown mechanism "bogus"`}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.record, func(t *testing.T) {
+			report := Audit("example.com", nil, newAnswers(t, `example.com. 300 IN TXT "`+tc.record+`"`))
+			assert.Equal(t, tc.want, problems(report, "SPF"))
+		})
+	}
+}
+
+func TestAudit_SPF_Multiple(t *testing.T) {
+	report := Audit("example.com", nil, newAnswers(t,
+		`example.com. 300 IN TXT "v=spf1 -all"`,
+		`example.com. 300 IN TXT "v=spf1 mx -all"`,
+	))
+
+	assert.Equal(t, []Problem{{SeverityError, "2 SPF records, receivers treat multiple records as a permanent error"}}, problems(report, "SPF"))
+}
+
+func TestAudit_DMARC(t *testing.T) {
+	testCases := []struct {
+		record string
+		want   []Problem
+	}{
+		{"v=DMARC1; p=none; rua=mailto:d@example.com", []Problem{{SeverityWarning, "p=none only monitors, email failing authentication is still delivered"}}},
+		{"v=DMARC1; p=reject", []Problem{{SeverityWarning, "missing rua tag, no aggregate reports are sent"}}},
+		{"v=DMARC1; p=Reject; sp=QUARANTINE; rua=mailto:d@example.com", []Problem{}},
+		{"v=DMARC1; p=reject; sp=block; rua=mailto:d@example.com", []Problem{{SeverityError, "invalid subdomain policy sp=block"}}},
+		{"v=DMARC1; rua=mailto:d@example.com", []Problem{{SeverityError, "missing p tag"}}},
+		{"v=DMARC1; p=block; rua=mailto:d@example.com", []Problem{{SeverityError, "invalid policy p=block"}}},
+		{"v=DMARC1; p=reject; pct=50; rua=mailto:d@example.com", []Problem{{SeverityWarning, "pct=50 applies the policy to only 50% of failing email"}}},
+		{"v=DMARC1; p", []Problem{{SeverityError, `invalid DMARC record: invalid tag "p"`}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.record, func(t *testing.T) {
+			report := Audit("example.com", nil, newAnswers(t, `_dmarc.example.com. 300 IN TXT "`+tc.record+`"`))
+			assert.Equal(t, tc.want, problems(report, "DMARC"))
+		})
+	}
+}
+
+func TestAudit_DKIM(t *testing.T) {
+	report := Audit("example.com", []string{"revoked", "testing", "missing"}, newAnswers(t,
+		`revoked._domainkey.example.com. 300 IN TXT "v=DKIM1; p="`,
+		`testing._domainkey.example.com. 300 IN TXT "v=DKIM1; t=y; p=MIGf"`,
+	))
+
+	assert.Equal(t, []Problem{{SeverityWarning, "the key was revoked"}}, problems(report, "DKIM (revoked)"))
+	assert.Equal(t, []Problem{{SeverityWarning, "t=y marks the domain as testing DKIM, receivers may ignore failures"}}, problems(report, "DKIM (testing)"))
+	assert.Equal(t, []Problem{{SeverityError, "no DKIM key for selector missing"}}, problems(report, "DKIM (missing)"))
+}
+
+func TestAudit_BIMI(t *testing.T) {
+	report := Audit("example.com", nil, newAnswers(t,
+		`_dmarc.example.com. 300 IN TXT "v=DMARC1; p=none; rua=mailto:d@example.com"`,
+		`default._bimi.example.com. 300 IN TXT "v=BIMI1; l=http://example.com/logo.svg"`,
+	))
+
+	assert.Equal(t, []Problem{
+		{SeverityError, "the logo l=http://example.com/logo.svg must be served over HTTPS"},
+		{SeverityWarning, "BIMI requires a DMARC policy of quarantine or reject"},
+	}, problems(report, "BIMI"))
+}
+
+func TestAudit_MTASTS_TLSRPT(t *testing.T) {
+	report := Audit("example.com", nil, newAnswers(t,
+		`_mta-sts.example.com. 300 IN TXT "v=STSv1;"`,
+		`_smtp._tls.example.com. 300 IN TXT "v=TLSRPTv1"`,
+	))
+
+	assert.Equal(t, []Problem{{SeverityError, "missing id tag"}}, problems(report, "MTA-STS"))
+	assert.Equal(t, []Problem{{SeverityError, "missing rua tag"}}, problems(report, "TLS-RPT"))
+}
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags(" v=DMARC1 ;p=reject; rua = mailto:d@example.com; ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Tag{{"v", "DMARC1"}, {"p", "reject"}, {"rua", "mailto:d@example.com"}}, tags)
+}
+
+func TestVersioned(t *testing.T) {
+	records := []string{"v=spf1 -all", "V=SPF1 -all", "v=spf1", "v=spf10 -all", "google-site-verification=abc"}
+	assert.Equal(t, []string{"v=spf1 -all", "V=SPF1 -all", "v=spf1"}, versioned(records, "v=spf1"))
+
+	assert.Equal(t, []string{"v=DMARC1; p=none"}, versioned([]string{"v=DMARC1; p=none", "v=DMARC10"}, "v=DMARC1"))
+}
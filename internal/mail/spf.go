@@ -0,0 +1,102 @@
+package mail
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SPFLookupLimit is the maximum number of mechanisms and modifiers that cause DNS lookups an SPF
+// evaluation may use, see RFC 7208, section 4.6.4. Receivers treat evaluations over the limit as a permanent error.
+const SPFLookupLimit = 10
+
+// SPFTerm is a single mechanism or modifier of an SPF record.
+type SPFTerm struct {
+	// Qualifier is the result of a matching mechanism: +, -, ~ or ?. It is empty if the record does not
+	// give one, which means +, and for modifiers.
+	Qualifier string `json:"qualifier,omitempty"`
+
+	// Name is the name of the mechanism or modifier, in lower case, e.g. include or redirect.
+	Name string `json:"name"`
+
+	// Value is the argument of the term, e.g. the domain of an include mechanism. It is empty if there is none.
+	Value string `json:"value,omitempty"`
+
+	// Modifier is set for modifiers, such as redirect=, as opposed to mechanisms.
+	Modifier bool `json:"modifier,omitempty"`
+}
+
+// String returns the term as written in the record.
+func (t SPFTerm) String() string {
+	switch {
+	case t.Modifier:
+		return t.Name + "=" + t.Value
+	case strings.HasPrefix(t.Value, "/"):
+		return t.Qualifier + t.Name + t.Value
+	case t.Value != "":
+		return t.Qualifier + t.Name + ":" + t.Value
+	default:
+		return t.Qualifier + t.Name
+	}
+}
+
+// Lookup reports whether evaluating the term requires a DNS lookup, counting towards SPFLookupLimit.
+func (t SPFTerm) Lookup() bool {
+	switch t.Name {
+	case "include", "a", "mx", "ptr", "exists":
+		return !t.Modifier
+	case "redirect":
+		return t.Modifier
+	default:
+		return false
+	}
+}
+
+// spfMechanisms holds the mechanisms defined by RFC 7208.
+var spfMechanisms = map[string]bool{
+	"all": true, "include": true, "a": true, "mx": true, "ptr": true, "ip4": true, "ip6": true, "exists": true,
+}
+
+// ParseSPF parses the terms of an SPF record.
+func ParseSPF(record string) ([]SPFTerm, error) {
+	fields := strings.Fields(record)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "v=spf1") {
+		return nil, fmt.Errorf("missing v=spf1 version tag")
+	}
+
+	var terms []SPFTerm
+	for _, field := range fields[1:] {
+		// Modifiers are name=value pairs, whose name cannot contain a colon or slash.
+		if i := strings.IndexByte(field, '='); i > 0 && !strings.ContainsAny(field[:i], ":/") {
+			terms = append(terms, SPFTerm{Name: strings.ToLower(field[:i]), Value: field[i+1:], Modifier: true})
+			continue
+		}
+
+		var term SPFTerm
+		if strings.ContainsRune("+-~?", rune(field[0])) {
+			term.Qualifier, field = field[:1], field[1:]
+		}
+		name, value, _ := strings.Cut(field, ":")
+		// The a and mx mechanisms take an optional CIDR length instead of, or after, a domain.
+		if i := strings.IndexByte(name, '/'); i >= 0 {
+			name, value = name[:i], name[i:]+value
+		}
+		term.Name, term.Value = strings.ToLower(name), value
+
+		if !spfMechanisms[term.Name] {
+			return nil, fmt.Errorf("unknown mechanism %q", field)
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// SPFLookups returns the number of terms of a record that require a DNS lookup.
+func SPFLookups(terms []SPFTerm) int {
+	n := 0
+	for _, term := range terms {
+		if term.Lookup() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSPF(t *testing.T) {
+	terms, err := ParseSPF("v=spf1 ip4:192.0.2.0/24 a/24 mx:example.com -include:_spf.example.net ~all redirect=example.org")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []SPFTerm{
+		{Name: "ip4", Value: "192.0.2.0/24"},
+		{Name: "a", Value: "/24"},
+		{Name: "mx", Value: "example.com"},
+		{Qualifier: "-", Name: "include", Value: "_spf.example.net"},
+		{Qualifier: "~", Name: "all"},
+		{Name: "redirect", Value: "example.org", Modifier: true},
+	}, terms)
+	assert.Equal(t, 4, SPFLookups(terms))
+	assert.Equal(t, "a/24", terms[1].String())
+	assert.Equal(t, "-include:_spf.example.net", terms[3].String())
+	assert.Equal(t, "redirect=example.org", terms[5].String())
+}
+
+func TestParseSPF_Invalid(t *testing.T) {
+	_, err := ParseSPF("v=spf2 -all")
+	assert.EqualError(t, err, "missing v=spf1 version tag")
+
+	_, err = ParseSPF("v=spf1 ip5:192.0.2.1 -all")
+	assert.EqualError(t, err, `unknown mechanism "ip5:192.0.2.1"`)
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tag is a single tag=value pair of a DMARC, DKIM, MTA-STS, TLS-RPT or BIMI record.
+type Tag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ParseTags parses a record made of tag=value pairs separated by semicolons, such as `v=DMARC1; p=reject`.
+// Whitespace around tags and values is ignored, and so is a trailing semicolon.
+func ParseTags(record string) ([]Tag, error) {
+	var tags []Tag
+	for _, part := range strings.Split(record, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tag %q", part)
+		}
+		tags = append(tags, Tag{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+	return tags, nil
+}
+
+// lookupTag returns the value of the first tag with the given name. Tag names are case-sensitive.
+func lookupTag(tags []Tag, name string) (string, bool) {
+	for _, tag := range tags {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,240 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// SortKey represents the record field answers are sorted by.
+type SortKey rune
+
+const (
+	SortNone       SortKey = 0
+	SortType       SortKey = 'T'
+	SortName       SortKey = 'N'
+	SortTTL        SortKey = 'L'
+	SortValue      SortKey = 'V'
+	SortPreference SortKey = 'P'
+)
+
+func (sk SortKey) String() string {
+	switch sk {
+	case SortNone:
+		return "none"
+	case SortType:
+		return "type"
+	case SortName:
+		return "name"
+	case SortTTL:
+		return "ttl"
+	case SortValue:
+		return "value"
+	case SortPreference:
+		return "preference"
+	default:
+		return "unknown"
+	}
+}
+
+// GroupBy represents the record field answers are grouped by.
+type GroupBy rune
+
+const (
+	GroupNone   GroupBy = 0
+	GroupType   GroupBy = 'T'
+	GroupDomain GroupBy = 'D'
+)
+
+func (gb GroupBy) String() string {
+	switch gb {
+	case GroupNone:
+		return "none"
+	case GroupType:
+		return "type"
+	case GroupDomain:
+		return "domain"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSortKey returns the sort key selected by the --sort flag.
+func ParseSortKey(s string) (SortKey, error) {
+	switch s {
+	case "", "none":
+		return SortNone, nil
+	case "type":
+		return SortType, nil
+	case "name":
+		return SortName, nil
+	case "ttl":
+		return SortTTL, nil
+	case "value":
+		return SortValue, nil
+	case "preference":
+		return SortPreference, nil
+	default:
+		return SortNone, fmt.Errorf("error: invalid sort key: %s", s)
+	}
+}
+
+// ParseGroupBy returns the grouping selected by the --group flag.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch s {
+	case "", "none":
+		return GroupNone, nil
+	case "type":
+		return GroupType, nil
+	case "domain":
+		return GroupDomain, nil
+	default:
+		return GroupNone, fmt.Errorf("error: invalid grouping: %s", s)
+	}
+}
+
+// Group holds the answers that share a grouping key, as responses to render in order.
+type Group struct {
+	// Key is the record type or owner name the answers share. It is empty if the answers are not grouped.
+	Key string
+
+	// Responses holds the answers of the group, each response holding a consecutive run of answers
+	// that came from the same query.
+	Responses []*Response
+}
+
+// answer is a single answer along with the response it came from.
+type answer struct {
+	dns.RR
+	resp *Response
+}
+
+// Arrange sorts the answers of all responses by key and splits them into groups.
+// Answers that compare equal keep their order, and with neither a key nor a grouping the responses are returned as is.
+// The returned responses are shallow copies of the responses the answers came from, so the metadata of each
+// query is kept. Responses without answers are added to the last group, so that no query is dropped.
+func Arrange(responses []*Response, key SortKey, by GroupBy) []Group {
+	if key == SortNone && by == GroupNone {
+		return []Group{{Responses: responses}}
+	}
+
+	var answers []answer
+	var empty []*Response
+	for _, resp := range responses {
+		if len(resp.Answer) == 0 {
+			empty = append(empty, resp)
+		}
+		for _, rr := range resp.Answer {
+			answers = append(answers, answer{RR: rr, resp: resp})
+		}
+	}
+
+	sort.SliceStable(answers, func(i, j int) bool {
+		return less(answers[i].RR, answers[j].RR, key)
+	})
+
+	var keys []string
+	grouped := make(map[string][]answer)
+	for _, a := range answers {
+		k := groupKey(a.RR, by)
+		if _, ok := grouped[k]; !ok {
+			keys = append(keys, k)
+		}
+		grouped[k] = append(grouped[k], a)
+	}
+
+	// Types are listed alphabetically, while names keep the order in which they were first answered,
+	// so the queried domain comes before the targets of its CNAME chain.
+	if by == GroupType {
+		sort.Strings(keys)
+	} else if by == GroupDomain {
+		keys = keys[:0]
+		for _, resp := range responses {
+			for _, rr := range resp.Answer {
+				k := groupKey(rr, by)
+				if !slices.Contains(keys, k) {
+					keys = append(keys, k)
+				}
+			}
+		}
+	}
+
+	groups := make([]Group, 0, len(keys)+1)
+	for _, k := range keys {
+		groups = append(groups, Group{Key: k, Responses: runs(grouped[k])})
+	}
+	if len(groups) == 0 {
+		groups = append(groups, Group{})
+	}
+	groups[len(groups)-1].Responses = append(groups[len(groups)-1].Responses, empty...)
+
+	return groups
+}
+
+// runs splits answers into consecutive runs that came from the same response,
+// and returns a shallow copy of the response holding each run.
+func runs(answers []answer) []*Response {
+	var out []*Response
+	for i := 0; i < len(answers); {
+		j := i
+		var records []dns.RR
+		for ; j < len(answers) && answers[j].resp == answers[i].resp; j++ {
+			records = append(records, answers[j].RR)
+		}
+
+		msg := *answers[i].resp.Msg
+		msg.Answer = records
+		resp := *answers[i].resp
+		resp.Msg = &msg
+		out = append(out, &resp)
+
+		i = j
+	}
+	return out
+}
+
+// less reports whether record a sorts before record b by key.
+// Records without a preference sort after those with one.
+func less(a, b dns.RR, key SortKey) bool {
+	switch key {
+	case SortType:
+		return dns.TypeToString[a.Header().Rrtype] < dns.TypeToString[b.Header().Rrtype]
+	case SortName:
+		return strings.ToLower(a.Header().Name) < strings.ToLower(b.Header().Name)
+	case SortTTL:
+		return a.Header().Ttl < b.Header().Ttl
+	case SortValue:
+		return Rdata(a) < Rdata(b)
+	case SortPreference:
+		pa, oka := Preference(a)
+		pb, okb := Preference(b)
+		if oka != okb {
+			return oka
+		}
+		return pa < pb
+	default:
+		return false
+	}
+}
+
+func groupKey(rr dns.RR, by GroupBy) string {
+	switch by {
+	case GroupType:
+		return dns.TypeToString[rr.Header().Rrtype]
+	case GroupDomain:
+		return strings.ToLower(rr.Header().Name)
+	default:
+		return ""
+	}
+}
+
+// Preference returns the preference of an MX record. The second return value is false for other record types.
+func Preference(rr dns.RR) (uint16, bool) {
+	if mx, ok := rr.(*dns.MX); ok {
+		return mx.Preference, true
+	}
+	return 0, false
+}
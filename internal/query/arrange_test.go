@@ -0,0 +1,138 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMX(name string, preference uint16, exchange string, ttl uint32) *dns.MX {
+	return &dns.MX{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: dns.TypeMX,
+			Class:  dns.ClassINET,
+			Ttl:    ttl,
+		},
+		Preference: preference,
+		Mx:         exchange,
+	}
+}
+
+func newTestResponse(qtype uint16, records ...dns.RR) *Response {
+	msg := new(dns.Msg)
+	msg.SetQuestion("example.com.", qtype)
+	msg.Answer = records
+	return &Response{Msg: msg, Server: "127.0.0.1:53"}
+}
+
+// arranged returns the key of each group followed by its records, in order.
+func arranged(groups []Group) [][]string {
+	var out [][]string
+	for _, g := range groups {
+		var records []string
+		for _, r := range g.Responses {
+			for _, rr := range r.Answer {
+				records = append(records, rr.String())
+			}
+		}
+		out = append(out, append([]string{g.Key}, records...))
+	}
+	return out
+}
+
+func TestArrange(t *testing.T) {
+	mx20 := newMX("example.com.", 20, "mx2.example.com.", 300)
+	mx10 := newMX("example.com.", 10, "mx1.example.com.", 600)
+	a := newA("example.com.", "192.0.2.1")
+	cname := newCNAME("www.example.com.", "example.com.")
+
+	responses := func() []*Response {
+		return []*Response{
+			newTestResponse(dns.TypeA, cname, a),
+			newTestResponse(dns.TypeMX, mx20, mx10),
+			newTestResponse(dns.TypeTXT),
+		}
+	}
+
+	t.Run("No sorting or grouping", func(t *testing.T) {
+		in := responses()
+		groups := Arrange(in, SortNone, GroupNone)
+
+		assert.Len(t, groups, 1)
+		assert.Equal(t, in, groups[0].Responses)
+	})
+
+	t.Run("Sort by preference", func(t *testing.T) {
+		groups := Arrange(responses(), SortPreference, GroupNone)
+
+		assert.Equal(t, [][]string{
+			{"", mx10.String(), mx20.String(), cname.String(), a.String()},
+		}, arranged(groups))
+
+		// The MX records stay in a single response, while the records of the A query follow in another one.
+		// The TXT query has no answers, and is kept at the end.
+		assert.Len(t, groups[0].Responses, 3)
+		assert.Equal(t, dns.TypeMX, groups[0].Responses[0].Question[0].Qtype)
+		assert.Equal(t, dns.TypeA, groups[0].Responses[1].Question[0].Qtype)
+		assert.Equal(t, dns.TypeTXT, groups[0].Responses[2].Question[0].Qtype)
+	})
+
+	t.Run("Sort by TTL", func(t *testing.T) {
+		groups := Arrange(responses(), SortTTL, GroupNone)
+
+		assert.Equal(t, [][]string{
+			{"", cname.String(), a.String(), mx20.String(), mx10.String()},
+		}, arranged(groups))
+	})
+
+	t.Run("Group by type", func(t *testing.T) {
+		groups := Arrange(responses(), SortValue, GroupType)
+
+		assert.Equal(t, [][]string{
+			{"A", a.String()},
+			{"CNAME", cname.String()},
+			{"MX", mx10.String(), mx20.String()},
+		}, arranged(groups))
+	})
+
+	t.Run("Group by domain", func(t *testing.T) {
+		groups := Arrange(responses(), SortNone, GroupDomain)
+
+		assert.Equal(t, [][]string{
+			{"www.example.com.", cname.String()},
+			{"example.com.", a.String(), mx20.String(), mx10.String()},
+		}, arranged(groups))
+	})
+
+	t.Run("Responses are not modified", func(t *testing.T) {
+		in := responses()
+		Arrange(in, SortPreference, GroupType)
+
+		assert.Equal(t, []dns.RR{cname, a}, in[0].Answer)
+		assert.Equal(t, []dns.RR{mx20, mx10}, in[1].Answer)
+	})
+}
+
+func TestParseSortKey(t *testing.T) {
+	sk, err := ParseSortKey("preference")
+	assert.NoError(t, err)
+	assert.Equal(t, SortPreference, sk)
+
+	sk, err = ParseSortKey("")
+	assert.NoError(t, err)
+	assert.Equal(t, SortNone, sk)
+
+	_, err = ParseSortKey("color")
+	assert.EqualError(t, err, "error: invalid sort key: color")
+}
+
+func TestParseGroupBy(t *testing.T) {
+	gb, err := ParseGroupBy("domain")
+	assert.NoError(t, err)
+	assert.Equal(t, GroupDomain, gb)
+
+	_, err = ParseGroupBy("color")
+	assert.EqualError(t, err, "error: invalid grouping: color")
+}
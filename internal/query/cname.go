@@ -0,0 +1,144 @@
+package query
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// MaxCNAMEDepth is the maximum number of CNAME records zns follows for a single name.
+// It keeps zns from chasing overly long or looping chains forever.
+const MaxCNAMEDepth = 8
+
+// Chain represents the CNAME chain a name resolved through.
+type Chain struct {
+	// Names holds the queried name followed by each CNAME target, in resolution order.
+	Names []string
+
+	// Loop is set if the last CNAME points back to a name already in the chain.
+	Loop bool
+
+	// Truncated is set if the chain is longer than MaxCNAMEDepth.
+	Truncated bool
+
+	// Apex holds the names in the chain that own a CNAME next to SOA or NS records,
+	// which is not allowed and usually means a CNAME was placed at a zone apex.
+	Apex []string
+
+	// Chased holds the responses to the queries FollowCNAME sent for the targets of the chain, when the
+	// resolver did not include their records. Each keeps the metadata of its own query.
+	Chased []*Response
+}
+
+// Target returns the name the chain finally resolves to.
+func (c *Chain) Target() string {
+	return c.Names[len(c.Names)-1]
+}
+
+// IsAlias reports whether the queried name is an alias, i.e. whether the chain has at least one CNAME.
+func (c *Chain) IsAlias() bool {
+	return len(c.Names) > 1
+}
+
+// BuildChain follows the CNAME records found in records, starting at name.
+// The records may come from any section of any number of responses.
+func BuildChain(name string, records []dns.RR) *Chain {
+	targets := make(map[string]string)
+	owners := make(map[string]map[uint16]bool)
+	for _, rr := range records {
+		owner := strings.ToLower(rr.Header().Name)
+		if owners[owner] == nil {
+			owners[owner] = make(map[uint16]bool)
+		}
+		owners[owner][rr.Header().Rrtype] = true
+
+		if cname, ok := rr.(*dns.CNAME); ok {
+			targets[owner] = cname.Target
+		}
+	}
+
+	chain := &Chain{Names: []string{dns.Fqdn(name)}}
+	seen := map[string]bool{strings.ToLower(dns.Fqdn(name)): true}
+
+	for {
+		current := strings.ToLower(chain.Target())
+		if owners[current][dns.TypeSOA] || owners[current][dns.TypeNS] {
+			if _, ok := targets[current]; ok {
+				chain.Apex = append(chain.Apex, chain.Target())
+			}
+		}
+
+		target, ok := targets[current]
+		if !ok {
+			return chain
+		}
+
+		if len(chain.Names) > MaxCNAMEDepth {
+			chain.Truncated = true
+			return chain
+		}
+
+		chain.Names = append(chain.Names, target)
+		if seen[strings.ToLower(target)] {
+			chain.Loop = true
+			return chain
+		}
+		seen[strings.ToLower(target)] = true
+	}
+}
+
+// FollowCNAME follows the CNAME chain in the answer section of resp. If the resolver
+// did not include the records of the final target, they are queried for, up to MaxCNAMEDepth
+// additional queries, and the responses are returned in the Chased field of the chain.
+// resp itself is not modified.
+func (q *QueryClient) FollowCNAME(resp *dns.Msg) (*Chain, error) {
+	domain := resp.Question[0].Name
+	qtype := resp.Question[0].Qtype
+
+	records := slices.Clone(resp.Answer)
+	var chased []*Response
+	for i := 0; ; i++ {
+		chain := BuildChain(domain, records)
+		chain.Chased = chased
+		if !chain.IsAlias() || chain.Loop || chain.Truncated || qtype == dns.TypeCNAME || i == MaxCNAMEDepth {
+			return chain, nil
+		}
+
+		target := chain.Target()
+		if hasRecord(records, target, qtype) {
+			return chain, nil
+		}
+
+		q.Debug("Following CNAME target", "domain", domain, "target", target, "qtype", dns.TypeToString[qtype])
+
+		next, err := q.query(target, qtype)
+		if err != nil {
+			return chain, err
+		}
+
+		added := false
+		for _, rr := range next.Answer {
+			if !hasRecord(records, rr.Header().Name, rr.Header().Rrtype) {
+				records = append(records, rr)
+				added = true
+			}
+		}
+
+		// The target has no records of the queried type, so there is nothing left to follow.
+		if !added {
+			return chain, nil
+		}
+		chased = append(chased, next)
+	}
+}
+
+// hasRecord reports whether records contains a record of the given type owned by name.
+func hasRecord(records []dns.RR, name string, rtype uint16) bool {
+	for _, rr := range records {
+		if rr.Header().Rrtype == rtype && strings.EqualFold(rr.Header().Name, name) {
+			return true
+		}
+	}
+	return false
+}
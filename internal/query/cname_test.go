@@ -0,0 +1,188 @@
+package query
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCNAME(name, target string) *dns.CNAME {
+	return &dns.CNAME{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: dns.TypeCNAME,
+			Class:  dns.ClassINET,
+			Ttl:    60,
+		},
+		Target: target,
+	}
+}
+
+func newA(name, ip string) *dns.A {
+	return &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    60,
+		},
+		A: net.ParseIP(ip),
+	}
+}
+
+// MockCNAMEClient is a mock DNS client that answers from a fixed set of records, like an
+// authoritative server would: it returns the CNAME for aliases without following it.
+type MockCNAMEClient struct {
+	Records []dns.RR
+
+	// Queries stores the names that were queried, in order.
+	Queries []string
+}
+
+func (m *MockCNAMEClient) Exchange(req *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+	q := req.Question[0]
+	m.Queries = append(m.Queries, q.Name)
+
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	for _, rr := range m.Records {
+		if rr.Header().Name == q.Name && (rr.Header().Rrtype == q.Qtype || rr.Header().Rrtype == dns.TypeCNAME) {
+			resp.Answer = append(resp.Answer, rr)
+		}
+	}
+	return resp, time.Microsecond * 42, nil
+}
+
+func TestBuildChain(t *testing.T) {
+	t.Run("no alias", func(t *testing.T) {
+		chain := BuildChain("example.com", []dns.RR{newA("example.com.", "192.0.2.1")})
+
+		assert.False(t, chain.IsAlias())
+		assert.Equal(t, []string{"example.com."}, chain.Names)
+		assert.Equal(t, "example.com.", chain.Target())
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		chain := BuildChain("www.example.com", []dns.RR{
+			newCNAME("www.example.com.", "cdn.example.net."),
+			newCNAME("cdn.example.net.", "edge.example.org."),
+			newA("edge.example.org.", "192.0.2.1"),
+		})
+
+		assert.True(t, chain.IsAlias())
+		assert.False(t, chain.Loop)
+		assert.Equal(t, []string{"www.example.com.", "cdn.example.net.", "edge.example.org."}, chain.Names)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		chain := BuildChain("a.example.com", []dns.RR{
+			newCNAME("a.example.com.", "b.example.com."),
+			newCNAME("b.example.com.", "a.example.com."),
+		})
+
+		assert.True(t, chain.Loop)
+		assert.Equal(t, []string{"a.example.com.", "b.example.com.", "a.example.com."}, chain.Names)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		var records []dns.RR
+		names := []string{"a.", "b.", "c.", "d.", "e.", "f.", "g.", "h.", "i.", "j.", "k."}
+		for i := 0; i < len(names)-1; i++ {
+			records = append(records, newCNAME(names[i], names[i+1]))
+		}
+
+		chain := BuildChain("a", records)
+
+		assert.True(t, chain.Truncated)
+		assert.Len(t, chain.Names, MaxCNAMEDepth+1)
+	})
+
+	t.Run("apex", func(t *testing.T) {
+		chain := BuildChain("example.com", []dns.RR{
+			newCNAME("example.com.", "example.net."),
+			&dns.SOA{
+				Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 60},
+				Ns:  "ns.example.com.",
+			},
+		})
+
+		assert.Equal(t, []string{"example.com."}, chain.Apex)
+	})
+}
+
+func TestQueryClient_FollowCNAME(t *testing.T) {
+	t.Run("chases missing target", func(t *testing.T) {
+		mockDNSClient := &MockCNAMEClient{Records: []dns.RR{
+			newCNAME("www.example.com.", "cdn.example.net."),
+			newCNAME("cdn.example.net.", "edge.example.org."),
+			newA("edge.example.org.", "192.0.2.1"),
+		}}
+		client := NewQueryClient("8.8.8.8", mockDNSClient, hclog.NewNullLogger())
+
+		resp, err := client.query("www.example.com", dns.TypeA)
+		assert.NoError(t, err)
+
+		chain, err := client.FollowCNAME(resp.Msg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"www.example.com.", "cdn.example.net.", "edge.example.org."}, chain.Names)
+		assert.Equal(t, []string{"www.example.com.", "cdn.example.net.", "edge.example.org."}, mockDNSClient.Queries)
+		// The records of the targets are in the responses to the chase, and the response itself is left as it was.
+		assert.Len(t, resp.Answer, 1)
+		assert.Len(t, chain.Chased, 2)
+		assert.Equal(t, "cdn.example.net.", chain.Chased[0].Question[0].Name)
+		assert.Equal(t, "edge.example.org.", chain.Chased[1].Question[0].Name)
+		assert.Equal(t, "8.8.8.8", chain.Chased[1].Server)
+	})
+
+	t.Run("target already included", func(t *testing.T) {
+		mockDNSClient := &MockCNAMEClient{}
+		client := NewQueryClient("8.8.8.8", mockDNSClient, hclog.NewNullLogger())
+
+		resp := new(dns.Msg)
+		resp.SetQuestion("www.example.com.", dns.TypeA)
+		resp.Answer = []dns.RR{
+			newCNAME("www.example.com.", "cdn.example.net."),
+			newA("cdn.example.net.", "192.0.2.1"),
+		}
+
+		chain, err := client.FollowCNAME(resp)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "cdn.example.net.", chain.Target())
+		assert.Empty(t, mockDNSClient.Queries)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		mockDNSClient := &MockCNAMEClient{Records: []dns.RR{
+			newCNAME("a.example.com.", "b.example.com."),
+			newCNAME("b.example.com.", "a.example.com."),
+		}}
+		client := NewQueryClient("8.8.8.8", mockDNSClient, hclog.NewNullLogger())
+
+		resp, err := client.query("a.example.com", dns.TypeA)
+		assert.NoError(t, err)
+
+		chain, err := client.FollowCNAME(resp.Msg)
+
+		assert.NoError(t, err)
+		assert.True(t, chain.Loop)
+		assert.LessOrEqual(t, len(mockDNSClient.Queries), MaxCNAMEDepth+1)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		client := NewQueryClient("8.8.8.8", &MockDNSClientWithError{}, hclog.NewNullLogger())
+
+		resp := new(dns.Msg)
+		resp.SetQuestion("www.example.com.", dns.TypeA)
+		resp.Answer = []dns.RR{newCNAME("www.example.com.", "cdn.example.net.")}
+
+		_, err := client.FollowCNAME(resp)
+
+		assert.Error(t, err)
+	})
+}
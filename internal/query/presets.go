@@ -0,0 +1,97 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Presets holds the named sets of queries that can be given to the -q flag in place of query types.
+// Each entry is a query type, or a label and a query type separated by a space, to query a name under the domain.
+var Presets = map[string][]string{
+	"mail": {"MX", "TXT", "_dmarc TXT"},
+	"web":  {"A", "AAAA", "CNAME", "HTTPS", "CAA"},
+}
+
+// ParseQuestions returns the questions to ask about a domain for the value of the -q flag, a comma-separated
+// list of query types, presets and entries such as `_dmarc TXT`. The presets given take precedence over the
+// built-in Presets. An empty value asks for every type of QueryTypes. Duplicate questions are only asked once.
+func ParseQuestions(domain string, spec string, presets map[string][]string) ([]Question, error) {
+	if strings.TrimSpace(spec) == "" {
+		qtypes := make([]uint16, 0, len(QueryTypes))
+		for _, qtype := range QueryTypes {
+			qtypes = append(qtypes, qtype)
+		}
+		return Questions(domain, qtypes), nil
+	}
+
+	var questions []Question
+	seen := make(map[Question]bool)
+	add := func(entry string) error {
+		question, err := parseEntry(domain, entry)
+		if err != nil {
+			return err
+		}
+		if !seen[question] {
+			seen[question] = true
+			questions = append(questions, question)
+		}
+		return nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+
+		preset, ok := presets[strings.ToLower(entry)]
+		if !ok {
+			preset, ok = Presets[strings.ToLower(entry)]
+		}
+		if !ok {
+			preset = []string{entry}
+		}
+
+		for _, e := range preset {
+			if err := add(strings.TrimSpace(e)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return questions, nil
+}
+
+// parseEntry parses a query type, or a label and a query type, into a question about the domain.
+func parseEntry(domain string, entry string) (Question, error) {
+	fields := strings.Fields(entry)
+	switch len(fields) {
+	case 1:
+		qtype, ok := Type(fields[0])
+		if !ok {
+			return Question{}, fmt.Errorf("error: invalid query type: %s", entry)
+		}
+		return Question{Name: domain, Qtype: qtype}, nil
+	case 2:
+		qtype, ok := Type(fields[1])
+		if !ok {
+			return Question{}, fmt.Errorf("error: invalid query type: %s", fields[1])
+		}
+		if _, ok := dns.IsDomainName(fields[0]); !ok || strings.HasSuffix(fields[0], ".") {
+			return Question{}, fmt.Errorf("error: invalid label: %s", fields[0])
+		}
+		return Question{Name: fields[0] + "." + domain, Qtype: qtype}, nil
+	default:
+		return Question{}, fmt.Errorf("error: invalid query type: %s", entry)
+	}
+}
+
+// Type returns the query type for its name, ignoring case. Types outside of QueryTypes, such as HTTPS and CAA,
+// can be queried explicitly, but are not part of the default queries.
+func Type(name string) (uint16, bool) {
+	name = strings.ToUpper(name)
+	if qtype, ok := QueryTypes[name]; ok {
+		return qtype, true
+	}
+	qtype, ok := dns.StringToType[name]
+	return qtype, ok && qtype != dns.TypeNone
+}
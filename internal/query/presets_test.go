@@ -0,0 +1,76 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuestions_Default(t *testing.T) {
+	questions, err := ParseQuestions("example.com", "", nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, questions, len(QueryTypes))
+}
+
+func TestParseQuestions_List(t *testing.T) {
+	questions, err := ParseQuestions("example.com", "a, mx,A,_dmarc TXT", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Question{
+		{Name: "example.com", Qtype: dns.TypeA},
+		{Name: "example.com", Qtype: dns.TypeMX},
+		{Name: "_dmarc.example.com", Qtype: dns.TypeTXT},
+	}, questions)
+}
+
+func TestParseQuestions_Presets(t *testing.T) {
+	questions, err := ParseQuestions("example.com", "mail,web", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Question{
+		{Name: "example.com", Qtype: dns.TypeMX},
+		{Name: "example.com", Qtype: dns.TypeTXT},
+		{Name: "_dmarc.example.com", Qtype: dns.TypeTXT},
+		{Name: "example.com", Qtype: dns.TypeA},
+		{Name: "example.com", Qtype: dns.TypeAAAA},
+		{Name: "example.com", Qtype: dns.TypeCNAME},
+		{Name: "example.com", Qtype: dns.TypeHTTPS},
+		{Name: "example.com", Qtype: dns.TypeCAA},
+	}, questions)
+}
+
+func TestParseQuestions_UserPresets(t *testing.T) {
+	// User presets take precedence over the built-in ones.
+	presets := map[string][]string{"mail": {"MX"}, "infra": {"NS", "SOA"}}
+
+	questions, err := ParseQuestions("example.com", "Mail,infra", presets)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Question{
+		{Name: "example.com", Qtype: dns.TypeMX},
+		{Name: "example.com", Qtype: dns.TypeNS},
+		{Name: "example.com", Qtype: dns.TypeSOA},
+	}, questions)
+}
+
+func TestParseQuestions_Invalid(t *testing.T) {
+	testCases := []struct {
+		spec string
+		err  string
+	}{
+		{"FOO", "error: invalid query type: FOO"},
+		{"A,", "error: invalid query type: "},
+		{"_dmarc FOO", "error: invalid query type: FOO"},
+		{"_dmarc. TXT", "error: invalid label: _dmarc."},
+		{"_dmarc TXT MX", "error: invalid query type: _dmarc TXT MX"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			_, err := ParseQuestions("example.com", tc.spec, nil)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
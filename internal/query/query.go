@@ -1,6 +1,9 @@
 package query
 
 import (
+	"fmt"
+	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,61 +30,277 @@ type DNSClient interface {
 	Exchange(*dns.Msg, string) (*dns.Msg, time.Duration, error)
 }
 
+// Response holds a DNS response along with metadata about the exchange that produced it.
+type Response struct {
+	*dns.Msg
+
+	// Server is the address of the DNS server that answered the query.
+	Server string
+
+	// RTT is the round-trip time of the exchange.
+	RTT time.Duration
+
+	// Size is the size of the response message in bytes, as it was received.
+	Size int
+
+	// Transport is the protocol the query was sent over: UDP, TCP or TLS.
+	Transport string
+
+	// Wildcards holds the answers synthesized from a wildcard, by their presentation format.
+	// It is only set if DetectWildcards was called for the response.
+	Wildcards map[string]bool
+}
+
+// Err returns an error for a response code that means the query failed, such as SERVFAIL or REFUSED.
+// A name that does not exist is not an error, as NXDOMAIN answers the query.
+func (r *Response) Err() error {
+	if r.Rcode == dns.RcodeSuccess || r.Rcode == dns.RcodeNameError {
+		return nil
+	}
+	if len(r.Question) == 0 {
+		return fmt.Errorf("%s without a question section", dns.RcodeToString[r.Rcode])
+	}
+	q := r.Question[0]
+	return fmt.Errorf("%s looking up %s %s", dns.RcodeToString[r.Rcode], dns.TypeToString[q.Qtype], q.Name)
+}
+
 type QueryClient struct {
 	Server string
 	Client DNSClient
 	hclog.Logger
+
+	// AuthClient and AuthTCPClient are the clients QueryServer queries authoritative servers with: over UDP,
+	// and over TCP when an answer is truncated. They are independent of the transport of Client, as
+	// authoritative servers are not reached over TLS.
+	AuthClient    DNSClient
+	AuthTCPClient DNSClient
+
+	// AuthPort is the port QueryServer queries authoritative servers on.
+	AuthPort string
 }
 
 // NewQueryClient initializes a QueryClient with the given DNS server, client, and logger.
-// The provided client must implement the Exchange method for DNS queries.
+// The provided client must implement the Exchange method for DNS queries. Authoritative servers are queried
+// on port 53, with the timeout of the client if it is a *dns.Client.
 func NewQueryClient(server string, client DNSClient, logger hclog.Logger) *QueryClient {
+	var timeout time.Duration
+	if c, ok := client.(*dns.Client); ok {
+		timeout = c.Timeout
+	}
+
 	return &QueryClient{
-		Server: server,
-		Client: client,
-		Logger: logger,
+		Server:        server,
+		Client:        client,
+		Logger:        logger,
+		AuthClient:    &dns.Client{Net: "udp", Timeout: timeout},
+		AuthTCPClient: &dns.Client{Net: "tcp", Timeout: timeout},
+		AuthPort:      "53",
+	}
+}
+
+// Question is a single query, for a record type of a domain name.
+type Question struct {
+	Name  string
+	Qtype uint16
+}
+
+// Questions returns a question for each type of a single domain name.
+func Questions(domain string, qtypes []uint16) []Question {
+	questions := make([]Question, len(qtypes))
+	for i, qtype := range qtypes {
+		questions[i] = Question{Name: domain, Qtype: qtype}
 	}
+	return questions
 }
 
 // MultiQuery performs DNS queries for multiple types concurrently.
-func (q *QueryClient) MultiQuery(domain string, qtypes []uint16) ([]*dns.Msg, error) {
+func (q *QueryClient) MultiQuery(domain string, qtypes []uint16) ([]*Response, error) {
+	return q.MultiQuestion(Questions(domain, qtypes))
+}
+
+// MultiQuestion performs DNS queries for multiple questions concurrently. The responses are in the order of the questions.
+func (q *QueryClient) MultiQuestion(questions []Question) ([]*Response, error) {
 	var errors *multierror.Error
+
+	messages := make([]*Response, len(questions))
+
+	q.each(questions, false, func(i int, resp *Response, chain *Chain, err error) {
+		messages[i] = resp
+		errors = multierror.Append(errors, err)
+	})
+
+	return messages, errors.ErrorOrNil()
+}
+
+// StreamQuestion performs DNS queries for multiple questions concurrently, follows the CNAME chain of each
+// response, and calls fn as soon as each query and its chase complete. The chains are followed concurrently,
+// before fn is called. Calls to fn are serialized, so fn does not need to be safe for concurrent use.
+// StreamQuestion returns once all queries have completed and fn has returned for each of them.
+func (q *QueryClient) StreamQuestion(questions []Question, fn func(question Question, resp *Response, chain *Chain, err error)) {
+	q.each(questions, true, func(i int, resp *Response, chain *Chain, err error) {
+		fn(questions[i], resp, chain, err)
+	})
+}
+
+// each queries every question concurrently and calls fn with the index of the question and the result of its query.
+// If follow is set, the CNAME chain of each response is followed before fn is called. Calls to fn are serialized.
+func (q *QueryClient) each(questions []Question, follow bool, fn func(i int, resp *Response, chain *Chain, err error)) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
-	messages := make([]*dns.Msg, len(qtypes))
-
-	for i, qtype := range qtypes {
+	for i, question := range questions {
 		wg.Add(1)
-		go func(i int, qtype uint16) {
+		go func(i int, question Question) {
 			defer wg.Done()
-			msg, err := q.query(domain, qtype)
+			var chain *Chain
+			msg, err := q.query(question.Name, question.Qtype)
+			if err == nil && follow {
+				chain, err = q.FollowCNAME(msg.Msg)
+			}
 			mu.Lock()
-			messages[i] = msg
-			errors = multierror.Append(errors, err)
+			fn(i, msg, chain, err)
 			mu.Unlock()
-		}(i, qtype)
+		}(i, question)
 	}
 
 	wg.Wait()
+}
 
-	return messages, errors.ErrorOrNil()
+// Lookup queries the records of a type owned by a name, following its CNAME chain. Only the records of the
+// queried type are returned. A name that does not exist has no records, and is not an error, but other
+// response codes such as SERVFAIL are.
+func (q *QueryClient) Lookup(name string, qtype uint16) ([]dns.RR, error) {
+	resp, err := q.query(name, qtype)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	chain, err := q.FollowCNAME(resp.Msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []dns.RR
+	for _, r := range append([]*Response{resp}, chain.Chased...) {
+		for _, rr := range r.Answer {
+			if rr.Header().Rrtype == qtype {
+				records = append(records, rr)
+			}
+		}
+	}
+	return records, nil
+}
+
+// Query performs a single DNS query for a type of a domain name. Unlike Lookup, it does not follow CNAMEs,
+// and returns the response whatever its response code.
+func (q *QueryClient) Query(domain string, qtype uint16) (*Response, error) {
+	return q.query(domain, qtype)
+}
+
+// QueryServer sends a non-recursive query to the server at an IP address, rather than to the configured server,
+// as needed to query authoritative servers directly. The server is queried on AuthPort over UDP, and the query
+// is retried over TCP if the answer is truncated.
+func (q *QueryClient) QueryServer(address, name string, qtype uint16) (*Response, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.RecursionDesired = false
+
+	server := net.JoinHostPort(address, q.AuthPort)
+	resp, err := q.exchange(q.AuthClient, server, name, msg)
+	if err != nil || !resp.Truncated {
+		return resp, err
+	}
+
+	q.Debug("Retrying truncated DNS response over TCP", "server", server, "domain", name)
+	return q.exchange(q.AuthTCPClient, server, name, msg)
+}
+
+// QueryDNSSEC queries the configured server with the DO bit set, so that the response includes the DNSSEC
+// records of the answer, such as the NSEC and NSEC3 records that deny the existence of a name.
+func (q *QueryClient) QueryDNSSEC(name string, qtype uint16) (*Response, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.SetEdns0(4096, true)
+
+	return q.exchange(q.Client, q.Server, name, msg)
 }
 
 // query performs the DNS query and returns the response and any error encountered.
-func (q *QueryClient) query(domain string, qtype uint16) (*dns.Msg, error) {
+func (q *QueryClient) query(domain string, qtype uint16) (*Response, error) {
 	msg := new(dns.Msg)
 	msg.SetQuestion(dns.Fqdn(domain), qtype)
 
-	q.Debug("Querying DNS server", "server", q.Server, "domain", domain, "qtype", dns.TypeToString[qtype])
+	return q.exchange(q.Client, q.Server, domain, msg)
+}
+
+// exchange sends a query for domain to a server with a client, and returns the response along with metadata about the exchange.
+func (q *QueryClient) exchange(client DNSClient, server, domain string, msg *dns.Msg) (*Response, error) {
+	qtype := msg.Question[0].Qtype
 
-	resp, rtt, err := q.Client.Exchange(msg, q.Server)
+	q.Debug("Querying DNS server", "server", server, "domain", domain, "qtype", dns.TypeToString[qtype])
+
+	resp, rtt, err := client.Exchange(msg, server)
 	if err != nil {
 		return nil, err
 	}
 
-	q.Debug("Received DNS response", "server", q.Server, "domain", domain, "qtype", dns.TypeToString[qtype], "rcode", dns.RcodeToString[resp.Rcode])
+	q.Debug("Received DNS response", "server", server, "domain", domain, "qtype", dns.TypeToString[qtype], "rcode", dns.RcodeToString[resp.Rcode])
 	q.Debug("Round trip time", "rtt", rtt)
 
-	return resp, nil
+	return &Response{
+		Msg:       resp,
+		Server:    server,
+		RTT:       rtt,
+		Size:      size(resp),
+		Transport: transport(client),
+	}, nil
+}
+
+// transport returns the protocol a client sends queries over. Clients other than *dns.Client are assumed to use UDP.
+func transport(client DNSClient) string {
+	c, ok := client.(*dns.Client)
+	if !ok {
+		return "UDP"
+	}
+	switch c.Net {
+	case "tcp", "tcp4", "tcp6":
+		return "TCP"
+	case "tcp-tls", "tcp4-tls", "tcp6-tls":
+		return "TLS"
+	default:
+		return "UDP"
+	}
+}
+
+// Rdata returns the presentation format of the record data, without the owner name, TTL, class and type.
+func Rdata(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// Value returns the record data as zns displays it. The strings of TXT records are unquoted and joined with
+// spaces; all other record types use the presentation format of Rdata.
+func Value(rr dns.RR) string {
+	if txt, ok := rr.(*dns.TXT); ok {
+		return strings.Join(txt.Txt, " ")
+	}
+	return Rdata(rr)
+}
+
+// TypeNames returns the names of the types of an NSEC or NSEC3 type bitmap.
+func TypeNames(bitmap []uint16) []string {
+	names := make([]string, len(bitmap))
+	for i, t := range bitmap {
+		names[i] = dns.Type(t).String()
+	}
+	return names
+}
+
+// size returns the wire size of a received message. Servers compress names in their responses,
+// so the size is calculated with compression, without altering the message itself.
+func size(msg *dns.Msg) int {
+	m := *msg
+	m.Compress = true
+	return m.Len()
 }
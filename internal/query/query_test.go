@@ -21,6 +21,15 @@ type MockDNSClient struct {
 	// QueryType stores the DNS query type (e.g., A, MX) extracted from the request.
 	// This is used to verify that the correct query type is passed to the underlying DNS client.
 	QueryType uint16
+
+	// ReceivedServer stores the address the query was sent to.
+	ReceivedServer string
+
+	// RecursionDesired stores whether the query asked for recursion.
+	RecursionDesired bool
+
+	// DNSSECOK stores whether the query set the DO bit.
+	DNSSECOK bool
 }
 
 func (m *MockDNSClient) Exchange(req *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
@@ -29,9 +38,14 @@ func (m *MockDNSClient) Exchange(req *dns.Msg, addr string) (*dns.Msg, time.Dura
 		m.ReceivedDomain = req.Question[0].Name
 		m.QueryType = req.Question[0].Qtype
 	}
-
-	// Return a mock response with a fixed round-trip time and no error.
-	return &dns.Msg{}, time.Microsecond * 42, nil
+	m.ReceivedServer = addr
+	m.RecursionDesired = req.RecursionDesired
+	m.DNSSECOK = req.IsEdns0() != nil && req.IsEdns0().Do()
+
+	// Return an empty reply with a fixed round-trip time and no error.
+	msg := new(dns.Msg)
+	msg.SetReply(req)
+	return msg, time.Microsecond * 42, nil
 }
 
 // MockDNSClientWithError is a mock DNS client used for testing purposes.
@@ -83,6 +97,84 @@ func TestQueryClient_Query_Error(t *testing.T) {
 	assert.Equal(t, "it's always DNS", err.Error())
 }
 
+func TestQueryClient_Lookup(t *testing.T) {
+	mockDNSClient := &MockCNAMEClient{Records: []dns.RR{
+		newCNAME("www.example.com.", "cdn.example.net."),
+		newA("cdn.example.net.", "192.0.2.1"),
+	}}
+	client := NewQueryClient("8.8.8.8", mockDNSClient, hclog.NewNullLogger())
+
+	records, err := client.Lookup("www.example.com", dns.TypeA)
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "cdn.example.net.", records[0].Header().Name)
+
+	records, err = client.Lookup("missing.example.com", dns.TypeA)
+
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestQueryClient_QueryServer(t *testing.T) {
+	mockDNSClient := &MockDNSClient{}
+	client := NewQueryClient("1.1.1.1:853", &dns.Client{Net: "tcp-tls"}, hclog.NewNullLogger())
+	client.AuthClient = mockDNSClient
+
+	resp, err := client.QueryServer("2001:db8::53", "example.com", dns.TypeSOA)
+
+	// The port and transport of the configured server are not used for authoritative servers.
+	assert.NoError(t, err)
+	assert.Equal(t, "[2001:db8::53]:53", mockDNSClient.ReceivedServer)
+	assert.Equal(t, "[2001:db8::53]:53", resp.Server)
+	assert.Equal(t, "UDP", resp.Transport)
+	assert.Equal(t, "example.com.", mockDNSClient.ReceivedDomain)
+	assert.False(t, mockDNSClient.RecursionDesired)
+
+	client.AuthPort = "5353"
+	_, err = client.QueryServer("192.0.2.53", "example.com", dns.TypeSOA)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "192.0.2.53:5353", mockDNSClient.ReceivedServer)
+}
+
+// truncatingClient answers every query with a truncated message.
+type truncatingClient struct{}
+
+func (truncatingClient) Exchange(req *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+	msg := new(dns.Msg)
+	msg.SetReply(req)
+	msg.Truncated = true
+	return msg, time.Millisecond, nil
+}
+
+func TestQueryClient_QueryServer_Truncated(t *testing.T) {
+	tcpClient := &MockDNSClient{}
+	client := NewQueryClient("8.8.8.8:53", &MockDNSClient{}, hclog.NewNullLogger())
+	client.AuthClient = truncatingClient{}
+	client.AuthTCPClient = tcpClient
+
+	resp, err := client.QueryServer("192.0.2.53", "example.com", dns.TypeNS)
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Truncated)
+	assert.Equal(t, "192.0.2.53:53", tcpClient.ReceivedServer)
+	assert.Equal(t, dns.TypeNS, tcpClient.QueryType)
+}
+
+func TestQueryClient_QueryDNSSEC(t *testing.T) {
+	mockDNSClient := &MockDNSClient{}
+	client := NewQueryClient("8.8.8.8:53", mockDNSClient, hclog.NewNullLogger())
+
+	_, err := client.QueryDNSSEC("example.com", dns.TypeNSEC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "8.8.8.8:53", mockDNSClient.ReceivedServer)
+	assert.Equal(t, "example.com.", mockDNSClient.ReceivedDomain)
+	assert.Equal(t, dns.TypeNSEC, mockDNSClient.QueryType)
+	assert.True(t, mockDNSClient.DNSSECOK)
+}
+
 func TestQueryClient_MultiQuery(t *testing.T) {
 	mockDNSClient := &MockDNSClient{}
 	client := NewQueryClient("8.8.8.8", mockDNSClient, hclog.NewNullLogger())
@@ -129,3 +221,103 @@ func TestQueryClient_MultiQuery_TypeAssert_MultiError(t *testing.T) {
 		}
 	}
 }
+
+func TestQueryClient_MultiQuestion(t *testing.T) {
+	mockDNSClient := &MockDNSClient{}
+	client := NewQueryClient("8.8.8.8", mockDNSClient, hclog.NewNullLogger())
+
+	resp, err := client.MultiQuestion([]Question{{Name: "_dmarc.example.com", Qtype: dns.TypeTXT}})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp, 1)
+	assert.Equal(t, "_dmarc.example.com.", mockDNSClient.ReceivedDomain)
+	assert.Equal(t, dns.TypeTXT, mockDNSClient.QueryType)
+}
+
+func TestQueryClient_StreamQuestion(t *testing.T) {
+	mockDNSClient := &MockDNSClient{}
+	client := NewQueryClient("8.8.8.8", mockDNSClient, hclog.NewNullLogger())
+
+	var questions []Question
+	client.StreamQuestion(Questions("example.com", []uint16{dns.TypeA, dns.TypeMX}), func(question Question, resp *Response, chain *Chain, err error) {
+		assert.NoError(t, err)
+		assert.Equal(t, "8.8.8.8", resp.Server)
+		questions = append(questions, question)
+	})
+
+	assert.ElementsMatch(t, []Question{{Name: "example.com", Qtype: dns.TypeA}, {Name: "example.com", Qtype: dns.TypeMX}}, questions)
+}
+
+func TestQueryClient_StreamQuestion_FollowCNAME(t *testing.T) {
+	mockDNSClient := &MockCNAMEClient{Records: []dns.RR{
+		newCNAME("www.example.com.", "cdn.example.net."),
+		newA("cdn.example.net.", "192.0.2.1"),
+	}}
+	client := NewQueryClient("8.8.8.8", mockDNSClient, hclog.NewNullLogger())
+
+	var chains []*Chain
+	client.StreamQuestion(Questions("www.example.com", []uint16{dns.TypeA}), func(question Question, resp *Response, chain *Chain, err error) {
+		assert.NoError(t, err)
+		// The chain was followed before fn was called.
+		assert.Equal(t, []string{"www.example.com.", "cdn.example.net."}, mockDNSClient.Queries)
+		chains = append(chains, chain)
+	})
+
+	assert.Len(t, chains, 1)
+	assert.Equal(t, []string{"www.example.com.", "cdn.example.net."}, chains[0].Names)
+}
+
+func TestQueryClient_StreamQuestion_Error(t *testing.T) {
+	mockDNSClientWithError := &MockDNSClientWithError{}
+	client := NewQueryClient("8.8.8.8", mockDNSClientWithError, hclog.NewNullLogger())
+
+	var errors []error
+	client.StreamQuestion(Questions("example.com", []uint16{dns.TypeA, dns.TypeMX}), func(question Question, resp *Response, chain *Chain, err error) {
+		assert.Nil(t, resp)
+		errors = append(errors, err)
+	})
+
+	assert.Len(t, errors, 2)
+}
+
+func TestResponse_Err(t *testing.T) {
+	msg := new(dns.Msg)
+	msg.SetQuestion("_dmarc.example.com.", dns.TypeTXT)
+	resp := &Response{Msg: msg}
+
+	assert.NoError(t, resp.Err())
+
+	resp.Rcode = dns.RcodeNameError
+	assert.NoError(t, resp.Err())
+
+	resp.Rcode = dns.RcodeServerFailure
+	assert.EqualError(t, resp.Err(), "SERVFAIL looking up TXT _dmarc.example.com.")
+
+	resp.Rcode = dns.RcodeRefused
+	assert.EqualError(t, resp.Err(), "REFUSED looking up TXT _dmarc.example.com.")
+
+	// Servers may leave out the question of a query they cannot parse.
+	resp = &Response{Msg: &dns.Msg{MsgHdr: dns.MsgHdr{Rcode: dns.RcodeFormatError}}}
+	assert.EqualError(t, resp.Err(), "FORMERR without a question section")
+}
+
+func TestValue(t *testing.T) {
+	txt := &dns.TXT{
+		Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 300},
+		Txt: []string{"v=spf1", "-all"},
+	}
+	mx := &dns.MX{
+		Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeMX, Class: dns.ClassINET, Ttl: 300},
+		Preference: 10,
+		Mx:         "mx1.example.com.",
+	}
+
+	assert.Equal(t, `"v=spf1" "-all"`, Rdata(txt))
+	assert.Equal(t, "v=spf1 -all", Value(txt))
+	assert.Equal(t, "10 mx1.example.com.", Value(mx))
+}
+
+func TestTypeNames(t *testing.T) {
+	assert.Equal(t, []string{"A", "MX", "RRSIG", "NSEC"}, TypeNames([]uint16{dns.TypeA, dns.TypeMX, dns.TypeRRSIG, dns.TypeNSEC}))
+	assert.Empty(t, TypeNames(nil))
+}
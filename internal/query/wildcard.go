@@ -0,0 +1,89 @@
+package query
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// IsWildcard reports whether a record of the answer was synthesized from a wildcard, as found by DetectWildcards.
+func (r *Response) IsWildcard(rr dns.RR) bool {
+	return r.Wildcards[rr.String()]
+}
+
+// ProbeLabel returns a random label, that is unlikely to exist in any zone.
+func ProbeLabel() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "zns-" + hex.EncodeToString(b)
+}
+
+// Sibling returns the name next to name, with its first label replaced by label.
+// A top-level name has no parent, so its sibling is label itself.
+func Sibling(name, label string) string {
+	labels := dns.SplitDomainName(name)
+	if len(labels) <= 1 {
+		return dns.Fqdn(label)
+	}
+	return dns.Fqdn(label + "." + strings.Join(labels[1:], "."))
+}
+
+// DetectWildcards probes a random sibling of the queried name of each response, for the same type. Names that do
+// not exist are only answered if a wildcard covers them, so the answers the queried name shares with its sibling
+// are marked as synthesized from a wildcard, see MarkWildcards.
+func (q *QueryClient) DetectWildcards(responses []*Response) error {
+	label := ProbeLabel()
+
+	var questions []Question
+	var probed []*Response
+	for _, resp := range responses {
+		if resp == nil || len(resp.Answer) == 0 {
+			continue
+		}
+		question := resp.Question[0]
+		questions = append(questions, Question{Name: Sibling(question.Name, label), Qtype: question.Qtype})
+		probed = append(probed, resp)
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+
+	probes, err := q.MultiQuestion(questions)
+	for i, probe := range probes {
+		probed[i].MarkWildcards(probe)
+	}
+	return err
+}
+
+// MarkWildcards marks the answers the response shares with a probe, the response to a query of the same type for a
+// name that does not exist. The probe was answered from a wildcard, so these answers were synthesized from it.
+// Only records owned by the queried name are marked, the records of the target of a synthesized CNAME are not.
+func (r *Response) MarkWildcards(probe *Response) {
+	if probe == nil || probe.Rcode != dns.RcodeSuccess {
+		return
+	}
+
+	synthesized := make(map[string]bool)
+	for _, rr := range probe.Answer {
+		if strings.EqualFold(rr.Header().Name, probe.Question[0].Name) {
+			synthesized[wildcardKey(rr)] = true
+		}
+	}
+
+	for _, rr := range r.Answer {
+		if strings.EqualFold(rr.Header().Name, r.Question[0].Name) && synthesized[wildcardKey(rr)] {
+			if r.Wildcards == nil {
+				r.Wildcards = make(map[string]bool)
+			}
+			r.Wildcards[rr.String()] = true
+		}
+	}
+}
+
+// wildcardKey identifies a record by its type and data, which a record synthesized from a wildcard shares
+// with the wildcard, regardless of its owner name.
+func wildcardKey(rr dns.RR) string {
+	return dns.TypeToString[rr.Header().Rrtype] + " " + Rdata(rr)
+}
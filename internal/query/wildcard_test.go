@@ -0,0 +1,75 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockWildcardClient is a mock DNS client for a zone with a wildcard, *.example.com, with an A record.
+// It answers from Records, and synthesizes the wildcard's record for the other names of the zone.
+type MockWildcardClient struct {
+	Records []dns.RR
+}
+
+func (m *MockWildcardClient) Exchange(req *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+	q := req.Question[0]
+
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	exists := false
+	for _, rr := range m.Records {
+		if rr.Header().Name == q.Name {
+			exists = true
+			if rr.Header().Rrtype == q.Qtype {
+				resp.Answer = append(resp.Answer, rr)
+			}
+		}
+	}
+	switch {
+	case exists:
+	case strings.HasSuffix(q.Name, ".example.com.") && q.Qtype == dns.TypeA:
+		resp.Answer = append(resp.Answer, newA(q.Name, "192.0.2.80"))
+	default:
+		resp.Rcode = dns.RcodeNameError
+	}
+	return resp, time.Microsecond * 42, nil
+}
+
+func TestSibling(t *testing.T) {
+	assert.Equal(t, "probe.example.com.", Sibling("www.example.com.", "probe"))
+	assert.Equal(t, "probe.com.", Sibling("example.com", "probe"))
+	assert.Equal(t, "probe.", Sibling("com.", "probe"))
+}
+
+func TestProbeLabel(t *testing.T) {
+	label := ProbeLabel()
+
+	assert.Regexp(t, "^zns-[0-9a-f]{16}$", label)
+	assert.NotEqual(t, label, ProbeLabel())
+}
+
+func TestQueryClient_DetectWildcards(t *testing.T) {
+	client := NewQueryClient("8.8.8.8", &MockWildcardClient{Records: []dns.RR{
+		newA("mail.example.com.", "192.0.2.25"),
+	}}, hclog.NewNullLogger())
+
+	responses, err := client.MultiQuestion([]Question{
+		{Name: "www.example.com", Qtype: dns.TypeA},
+		{Name: "mail.example.com", Qtype: dns.TypeA},
+		{Name: "www.example.com", Qtype: dns.TypeMX},
+	})
+	assert.NoError(t, err)
+
+	err = client.DetectWildcards(responses)
+
+	assert.NoError(t, err)
+	assert.True(t, responses[0].IsWildcard(responses[0].Answer[0]))
+	assert.False(t, responses[1].IsWildcard(responses[1].Answer[0]))
+	assert.Nil(t, responses[1].Wildcards)
+	assert.Nil(t, responses[2].Wildcards)
+}
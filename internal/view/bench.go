@@ -0,0 +1,110 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/bench"
+)
+
+// BenchRenderer renders the results of a benchmark of DNS servers.
+type BenchRenderer interface {
+	RenderBench(report *bench.Report) error
+}
+
+// NewBenchRenderer creates a BenchRenderer for a view type. Only the human and JSON document views are supported.
+func NewBenchRenderer(vt arguments.ViewType, view *View) (BenchRenderer, error) {
+	switch vt {
+	case arguments.ViewHuman:
+		return &HumanBenchRenderer{view: view}, nil
+	case arguments.ViewJSONDocument:
+		return &JSONBenchRenderer{view: view}, nil
+	default:
+		return nil, fmt.Errorf("error: the %s output is not supported by the bench command", vt)
+	}
+}
+
+// HumanBenchRenderer for rendering the results of a benchmark in human-readable format, as a table with
+// one row per server, so that servers can be compared at a glance.
+type HumanBenchRenderer struct {
+	view *View
+}
+
+// Validate that HumanBenchRenderer implements the BenchRenderer interface.
+var _ BenchRenderer = (*HumanBenchRenderer)(nil)
+
+// RenderBench renders the results of a benchmark in human-readable format to the output stream.
+func (v *HumanBenchRenderer) RenderBench(report *bench.Report) error {
+	lines := []string{color.HiYellowString("SERVER\tSENT\tANSWERED\tTIMEOUTS\tERRORS\tQPS\tMIN\tP50\tP90\tP99\tMAX\tCACHE HITS\tRCODES")}
+	for _, r := range report.Results {
+		timeouts := fmt.Sprint(r.Timeouts)
+		if r.Timeouts > 0 {
+			timeouts = color.HiRedString(timeouts)
+		}
+		errors := fmt.Sprint(r.Errors)
+		if r.Errors > 0 {
+			errors = color.HiRedString(errors)
+		}
+
+		cache := color.HiBlackString("-")
+		if r.Repeated > 0 {
+			cache = fmt.Sprintf("%.0f%% (%d/%d)", r.CacheHitRate()*100, r.CacheHits, r.Repeated)
+		}
+
+		lines = append(lines, strings.Join([]string{
+			color.HiBlueString(r.Server),
+			fmt.Sprint(r.Sent),
+			fmt.Sprint(r.Answered),
+			timeouts,
+			errors,
+			fmt.Sprintf("%.1f", r.QPS),
+			formatMilliseconds(r.Latency.Min),
+			color.HiWhiteString(formatMilliseconds(r.Latency.P50)),
+			formatMilliseconds(r.Latency.P90),
+			formatMilliseconds(r.Latency.P99),
+			formatMilliseconds(r.Latency.Max),
+			cache,
+			formatRcodes(r.Rcodes),
+		}, "\t"))
+	}
+
+	_, err := v.view.Stream.Writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	return err
+}
+
+// formatRcodes formats the number of responses per response code, sorted by response code.
+func formatRcodes(rcodes map[string]int) string {
+	if len(rcodes) == 0 {
+		return color.HiBlackString("-")
+	}
+	names := make([]string, 0, len(rcodes))
+	for name := range rcodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, rcodes[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// JSONBenchRenderer for rendering the results of a benchmark as a single JSON document.
+type JSONBenchRenderer struct {
+	view *View
+}
+
+// Validate that JSONBenchRenderer implements the BenchRenderer interface.
+var _ BenchRenderer = (*JSONBenchRenderer)(nil)
+
+// RenderBench renders the results of a benchmark as an indented JSON document to the output stream.
+func (v *JSONBenchRenderer) RenderBench(report *bench.Report) error {
+	enc := json.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,88 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/bench"
+)
+
+// TestNewBenchRenderer tests that NewBenchRenderer returns a renderer for the human and JSON views only.
+func TestNewBenchRenderer(t *testing.T) {
+	b := bytes.Buffer{}
+
+	r, err := NewBenchRenderer(arguments.ViewHuman, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &HumanBenchRenderer{}, r)
+
+	r, err = NewBenchRenderer(arguments.ViewJSONDocument, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &JSONBenchRenderer{}, r)
+
+	_, err = NewBenchRenderer(arguments.ViewYAML, NewView(&b))
+	assert.EqualError(t, err, "error: the yaml output is not supported by the bench command")
+}
+
+// TestHumanBenchRenderer_RenderBench tests that each server is rendered as a row of the table.
+func TestHumanBenchRenderer_RenderBench(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanBenchRenderer{view: NewView(&b)}
+
+	report := &bench.Report{Results: []bench.Result{
+		{
+			Server: "192.0.2.1:53", Sent: 100, Answered: 99, Timeouts: 1, QPS: 49.95,
+			Rcodes:    map[string]int{"NXDOMAIN": 9, "NOERROR": 90},
+			Latency:   bench.Latency{Min: 0.5, Mean: 2, P50: 1.25, P90: 3, P99: 10, Max: 12.5},
+			Repeated:  96,
+			CacheHits: 90,
+		},
+		{Server: "192.0.2.2:53", Sent: 10, Errors: 10, Rcodes: map[string]int{}},
+	}}
+	assert.NoError(t, r.RenderBench(report))
+
+	want := "SERVER\tSENT\tANSWERED\tTIMEOUTS\tERRORS\tQPS\tMIN\tP50\tP90\tP99\tMAX\tCACHE HITS\tRCODES\n" +
+		"192.0.2.1:53\t100\t99\t1\t0\t50.0\t0.5ms\t1.2ms\t3.0ms\t10.0ms\t12.5ms\t94% (90/96)\tNOERROR 90, NXDOMAIN 9\n" +
+		"192.0.2.2:53\t10\t0\t0\t10\t0.0\t0.0ms\t0.0ms\t0.0ms\t0.0ms\t0.0ms\t-\t-\n"
+	assert.Equal(t, want, b.String())
+}
+
+// TestJSONBenchRenderer_RenderBench tests that the report is written as an indented JSON document.
+func TestJSONBenchRenderer_RenderBench(t *testing.T) {
+	b := bytes.Buffer{}
+	r := &JSONBenchRenderer{view: NewView(&b)}
+
+	report := &bench.Report{Results: []bench.Result{{Server: "192.0.2.1:53", Sent: 1, Answered: 1, Rcodes: map[string]int{"NOERROR": 1}}}}
+	assert.NoError(t, r.RenderBench(report))
+
+	want := `{
+  "results": [
+    {
+      "server": "192.0.2.1:53",
+      "sent": 1,
+      "answered": 1,
+      "timeouts": 0,
+      "errors": 0,
+      "rcodes": {
+        "NOERROR": 1
+      },
+      "latency": {
+        "min_ms": 0,
+        "mean_ms": 0,
+        "p50_ms": 0,
+        "p90_ms": 0,
+        "p99_ms": 0,
+        "max_ms": 0
+      },
+      "qps": 0,
+      "repeated": 0,
+      "cache_hits": 0
+    }
+  ]
+}
+`
+	assert.Equal(t, want, b.String())
+}
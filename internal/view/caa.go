@@ -0,0 +1,92 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/caa"
+)
+
+// CAARenderer renders the evaluation of the CAA records of a domain.
+type CAARenderer interface {
+	RenderCAA(report *caa.Report) error
+}
+
+// NewCAARenderer creates a CAARenderer for a view type. Only the human and JSON document views are supported.
+func NewCAARenderer(vt arguments.ViewType, view *View) (CAARenderer, error) {
+	switch vt {
+	case arguments.ViewHuman:
+		return &HumanCAARenderer{view: view}, nil
+	case arguments.ViewJSONDocument:
+		return &JSONCAARenderer{view: view}, nil
+	default:
+		return nil, fmt.Errorf("error: the %s output is not supported by the caa command", vt)
+	}
+}
+
+// HumanCAARenderer for rendering a CAA evaluation in human-readable format: the names looked up with their
+// CAA records, followed by who may issue certificates for the domain and for wildcards.
+type HumanCAARenderer struct {
+	view *View
+}
+
+// Validate that HumanCAARenderer implements the CAARenderer interface.
+var _ CAARenderer = (*HumanCAARenderer)(nil)
+
+// RenderCAA renders a CAA evaluation in human-readable format to the output stream.
+func (v *HumanCAARenderer) RenderCAA(report *caa.Report) error {
+	var lines []string
+	for _, name := range report.Checked {
+		if name != report.Owner {
+			lines = append(lines, fmt.Sprintf("%s\t%s", color.HiBlueString(name), color.HiBlackString("-")))
+		}
+	}
+	for _, p := range report.Properties {
+		tag := color.HiRedString("%d %s", p.Flag, p.Tag)
+		lines = append(lines, fmt.Sprintf("%s\t%s %s", color.HiBlueString(p.Owner), tag, color.HiWhiteString(strconv.Quote(p.Value))))
+	}
+
+	lines = append(lines, "", formatDecision(report.CA, report.Issue), formatDecision(report.CA, report.Wildcard))
+
+	_, err := v.view.Stream.Writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	return err
+}
+
+// formatDecision generates a human-readable string for who may issue certificates for a name. If a CA was given,
+// it tells whether that CA may issue, and otherwise lists the CAs that may.
+func formatDecision(ca string, d caa.Decision) string {
+	if d.Allowed != nil {
+		if *d.Allowed {
+			return fmt.Sprintf("%s %s for %s (%s)", ca, color.HiGreenString("may issue"), color.HiBlueString(d.Name), d.Reason)
+		}
+		return fmt.Sprintf("%s %s for %s (%s)", ca, color.HiRedString("may not issue"), color.HiBlueString(d.Name), d.Reason)
+	}
+
+	issuers := color.HiWhiteString(strings.Join(d.Issuers, ", "))
+	switch {
+	case d.Any:
+		issuers = color.YellowString("any CA")
+	case len(d.Issuers) == 0:
+		issuers = color.HiRedString("no CA")
+	}
+	return fmt.Sprintf("%s: %s (%s)", color.HiBlueString(d.Name), issuers, d.Reason)
+}
+
+// JSONCAARenderer for rendering a CAA evaluation as a single JSON document.
+type JSONCAARenderer struct {
+	view *View
+}
+
+// Validate that JSONCAARenderer implements the CAARenderer interface.
+var _ CAARenderer = (*JSONCAARenderer)(nil)
+
+// RenderCAA renders a CAA evaluation as an indented JSON document to the output stream.
+func (v *JSONCAARenderer) RenderCAA(report *caa.Report) error {
+	enc := json.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
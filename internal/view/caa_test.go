@@ -0,0 +1,130 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/caa"
+)
+
+// newCAAReport returns a report of the CAA records of example.com, which forbid wildcard certificates.
+func newCAAReport(ca string, allowed, wildcardAllowed *bool) *caa.Report {
+	return &caa.Report{
+		Domain:  "www.example.com.",
+		CA:      ca,
+		Checked: []string{"www.example.com.", "example.com."},
+		Owner:   "example.com.",
+		Properties: []caa.Property{
+			{Owner: "example.com.", Tag: "issue", Value: "letsencrypt.org"},
+			{Owner: "example.com.", Tag: "issuewild", Value: ";"},
+		},
+		Iodef:    []string{},
+		Issue:    caa.Decision{Name: "www.example.com.", Issuers: []string{"letsencrypt.org"}, Allowed: allowed, Reason: "issue properties"},
+		Wildcard: caa.Decision{Name: "*.www.example.com.", Issuers: []string{}, Allowed: wildcardAllowed, Reason: "issuewild properties"},
+	}
+}
+
+// TestNewCAARenderer tests that NewCAARenderer returns a renderer for the human and JSON views only.
+func TestNewCAARenderer(t *testing.T) {
+	b := bytes.Buffer{}
+
+	r, err := NewCAARenderer(arguments.ViewHuman, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &HumanCAARenderer{}, r)
+
+	r, err = NewCAARenderer(arguments.ViewJSONDocument, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &JSONCAARenderer{}, r)
+
+	_, err = NewCAARenderer(arguments.ViewZone, NewView(&b))
+	assert.EqualError(t, err, "error: the zone output is not supported by the caa command")
+}
+
+// TestHumanCAARenderer_RenderCAA tests that the names looked up are followed by whether the CA may issue.
+func TestHumanCAARenderer_RenderCAA(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanCAARenderer{view: NewView(&b)}
+
+	allowed, denied := true, false
+	assert.NoError(t, r.RenderCAA(newCAAReport("letsencrypt.org", &allowed, &denied)))
+
+	want := "www.example.com.\t-\n" +
+		"example.com.\t0 issue \"letsencrypt.org\"\n" +
+		"example.com.\t0 issuewild \";\"\n" +
+		"\n" +
+		"letsencrypt.org may issue for www.example.com. (issue properties)\n" +
+		"letsencrypt.org may not issue for *.www.example.com. (issuewild properties)\n"
+	assert.Equal(t, want, b.String())
+}
+
+// TestHumanCAARenderer_RenderCAA_Issuers tests that the CAs that may issue are listed if no CA is given.
+func TestHumanCAARenderer_RenderCAA_Issuers(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanCAARenderer{view: NewView(&b)}
+
+	report := &caa.Report{
+		Domain:     "example.com.",
+		Checked:    []string{"example.com.", "com."},
+		Properties: []caa.Property{},
+		Iodef:      []string{},
+		Issue:      caa.Decision{Name: "example.com.", Any: true, Issuers: []string{}, Reason: "no CAA records"},
+		Wildcard:   caa.Decision{Name: "*.example.com.", Issuers: []string{}, Reason: "unknown critical property tbs"},
+	}
+	assert.NoError(t, r.RenderCAA(report))
+
+	want := "example.com.\t-\n" +
+		"com.\t-\n" +
+		"\n" +
+		"example.com.: any CA (no CAA records)\n" +
+		"*.example.com.: no CA (unknown critical property tbs)\n"
+	assert.Equal(t, want, b.String())
+
+	b.Reset()
+	assert.NoError(t, r.RenderCAA(newCAAReport("", nil, nil)))
+	assert.Contains(t, b.String(), "www.example.com.: letsencrypt.org (issue properties)\n")
+}
+
+// TestJSONCAARenderer_RenderCAA tests that the report is written as an indented JSON document.
+func TestJSONCAARenderer_RenderCAA(t *testing.T) {
+	b := bytes.Buffer{}
+	r := &JSONCAARenderer{view: NewView(&b)}
+
+	report := &caa.Report{
+		Domain:     "example.com.",
+		Checked:    []string{"example.com."},
+		Properties: []caa.Property{},
+		Iodef:      []string{},
+		Issue:      caa.Decision{Name: "example.com.", Any: true, Issuers: []string{}, Reason: "no CAA records"},
+		Wildcard:   caa.Decision{Name: "*.example.com.", Any: true, Issuers: []string{}, Reason: "no CAA records"},
+	}
+	assert.NoError(t, r.RenderCAA(report))
+
+	want := `{
+  "domain": "example.com.",
+  "checked": [
+    "example.com."
+  ],
+  "properties": [],
+  "iodef": [],
+  "issue": {
+    "name": "example.com.",
+    "any": true,
+    "issuers": [],
+    "reason": "no CAA records"
+  },
+  "wildcard": {
+    "name": "*.example.com.",
+    "any": true,
+    "issuers": [],
+    "reason": "no CAA records"
+  }
+}
+`
+	assert.Equal(t, want, b.String())
+}
@@ -0,0 +1,92 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/delegation"
+)
+
+// CheckRenderer renders the report of a delegation check.
+type CheckRenderer interface {
+	RenderCheck(report *delegation.Report) error
+}
+
+// NewCheckRenderer creates a CheckRenderer for a view type. Only the human and JSON document views are supported.
+func NewCheckRenderer(vt arguments.ViewType, view *View) (CheckRenderer, error) {
+	switch vt {
+	case arguments.ViewHuman:
+		return &HumanCheckRenderer{view: view}, nil
+	case arguments.ViewJSONDocument:
+		return &JSONCheckRenderer{view: view}, nil
+	default:
+		return nil, fmt.Errorf("error: the %s output is not supported by the check command", vt)
+	}
+}
+
+// HumanCheckRenderer for rendering a delegation check in human-readable format, one line per check
+// with its status, followed by a line for each further message.
+type HumanCheckRenderer struct {
+	view *View
+}
+
+// Validate that HumanCheckRenderer implements the CheckRenderer interface.
+var _ CheckRenderer = (*HumanCheckRenderer)(nil)
+
+// RenderCheck renders the report of a delegation check in human-readable format to the output stream.
+func (v *HumanCheckRenderer) RenderCheck(report *delegation.Report) error {
+	var lines []string
+	for _, r := range report.Results {
+		for i, message := range r.Messages {
+			if i == 0 {
+				lines = append(lines, fmt.Sprintf("%s\t%s\t%s", formatStatus(r.Status), color.HiYellowString(r.Check), message))
+			} else {
+				lines = append(lines, fmt.Sprintf("\t\t%s", message))
+			}
+		}
+	}
+
+	passed, warnings, failed := report.Count(delegation.StatusPass), report.Count(delegation.StatusWarn), report.Count(delegation.StatusFail)
+	summary := fmt.Sprintf("%d passed, %d %s, %d failed", passed, warnings, plural(warnings, "warning"), failed)
+	if failed > 0 {
+		summary = color.HiRedString(summary)
+	} else if warnings > 0 {
+		summary = color.YellowString(summary)
+	} else {
+		summary = color.HiGreenString(summary)
+	}
+	lines = append(lines, "", summary)
+
+	_, err := v.view.Stream.Writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	return err
+}
+
+// formatStatus generates a human-readable string for the status of a check, colored by outcome.
+func formatStatus(status delegation.Status) string {
+	switch status {
+	case delegation.StatusPass:
+		return color.HiGreenString(string(status))
+	case delegation.StatusWarn:
+		return color.YellowString(string(status))
+	default:
+		return color.HiRedString(string(status))
+	}
+}
+
+// JSONCheckRenderer for rendering a delegation check as a single JSON document.
+type JSONCheckRenderer struct {
+	view *View
+}
+
+// Validate that JSONCheckRenderer implements the CheckRenderer interface.
+var _ CheckRenderer = (*JSONCheckRenderer)(nil)
+
+// RenderCheck renders the report of a delegation check as an indented JSON document to the output stream.
+func (v *JSONCheckRenderer) RenderCheck(report *delegation.Report) error {
+	enc := json.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,84 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/delegation"
+)
+
+// TestNewCheckRenderer tests that NewCheckRenderer returns a renderer for the human and JSON views only.
+func TestNewCheckRenderer(t *testing.T) {
+	b := bytes.Buffer{}
+
+	r, err := NewCheckRenderer(arguments.ViewHuman, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &HumanCheckRenderer{}, r)
+
+	r, err = NewCheckRenderer(arguments.ViewJSONDocument, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &JSONCheckRenderer{}, r)
+
+	_, err = NewCheckRenderer(arguments.ViewZone, NewView(&b))
+	assert.EqualError(t, err, "error: the zone output is not supported by the check command")
+}
+
+// TestHumanCheckRenderer_RenderCheck tests that each check is rendered with its status and messages, followed by a summary.
+func TestHumanCheckRenderer_RenderCheck(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanCheckRenderer{view: NewView(&b)}
+
+	report := &delegation.Report{
+		Domain: "example.com.",
+		Parent: "com.",
+		Results: []delegation.Result{
+			{Check: "delegation", Status: delegation.StatusPass, Messages: []string{"com. delegates example.com. to ns1.example.com., ns2.example.com."}},
+			{Check: "serial", Status: delegation.StatusWarn, Messages: []string{"serial 1 on ns1.example.com. (192.0.2.1)", "serial 2 on ns2.example.com. (192.0.2.2)"}},
+			{Check: "authority", Status: delegation.StatusFail, Messages: []string{"ns3.example.org. has no addresses"}},
+		},
+	}
+	assert.NoError(t, r.RenderCheck(report))
+
+	want := "pass\tdelegation\tcom. delegates example.com. to ns1.example.com., ns2.example.com.\n" +
+		"warn\tserial\tserial 1 on ns1.example.com. (192.0.2.1)\n" +
+		"\t\tserial 2 on ns2.example.com. (192.0.2.2)\n" +
+		"fail\tauthority\tns3.example.org. has no addresses\n" +
+		"\n" +
+		"1 passed, 1 warning, 1 failed\n"
+	assert.Equal(t, want, b.String())
+}
+
+// TestJSONCheckRenderer_RenderCheck tests that the report is written as an indented JSON document.
+func TestJSONCheckRenderer_RenderCheck(t *testing.T) {
+	b := bytes.Buffer{}
+	r := &JSONCheckRenderer{view: NewView(&b)}
+
+	report := &delegation.Report{
+		Domain:      "example.com.",
+		Parent:      "com.",
+		Nameservers: []delegation.Nameserver{},
+		Results:     []delegation.Result{{Check: "delegation", Status: delegation.StatusFail, Messages: []string{"example.com. is not delegated by com."}}},
+	}
+	assert.NoError(t, r.RenderCheck(report))
+
+	want := `{
+  "domain": "example.com.",
+  "parent": "com.",
+  "nameservers": [],
+  "results": [
+    {
+      "check": "delegation",
+      "status": "fail",
+      "messages": [
+        "example.com. is not delegated by com."
+      ]
+    }
+  ]
+}
+`
+	assert.Equal(t, want, b.String())
+}
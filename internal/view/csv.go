@@ -0,0 +1,76 @@
+package view
+
+import (
+	"encoding/csv"
+	"strconv"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// csvHeader holds the column names of the CSVRenderer, in order.
+var csvHeader = []string{"domain", "name", "type", "ttl", "value", "server", "rcode"}
+
+// CSVRenderer for rendering records as comma- or tab-separated values, one row per record,
+// preceded by a header row. Fields containing the separator, quotes or newlines are quoted.
+// Values are in presentation format, so the strings of TXT records keep their quotes, as dig shows them.
+type CSVRenderer struct {
+	view     *View
+	csv      *csv.Writer
+	header   bool
+	rendered recordSet
+}
+
+// Validate that CSVRenderer implements the Renderer and Flusher interfaces.
+var _ Renderer = (*CSVRenderer)(nil)
+var _ Flusher = (*CSVRenderer)(nil)
+
+// NewCSVRenderer creates a CSVRenderer bound to an output stream, separating fields with comma.
+func NewCSVRenderer(view *View, comma rune) *CSVRenderer {
+	w := csv.NewWriter(view.Stream.Writer)
+	w.Comma = comma
+
+	return &CSVRenderer{
+		view:     view,
+		csv:      w,
+		rendered: make(recordSet),
+	}
+}
+
+// Render renders the answers of a DNS response to the output stream, one row per record.
+func (v *CSVRenderer) Render(domain string, resp *query.Response) {
+	v.writeHeader()
+
+	for _, record := range v.rendered.filter(resp.Answer) {
+		v.write([]string{
+			domain,
+			record.Header().Name,
+			dns.TypeToString[record.Header().Rrtype],
+			strconv.FormatUint(uint64(record.Header().Ttl), 10),
+			query.Rdata(record),
+			resp.Server,
+			dns.RcodeToString[resp.Rcode],
+		})
+	}
+	v.csv.Flush()
+}
+
+// Flush writes the header row if no records were rendered, and flushes any buffered rows to the output stream.
+func (v *CSVRenderer) Flush() error {
+	v.writeHeader()
+	v.csv.Flush()
+	return v.csv.Error()
+}
+
+func (v *CSVRenderer) writeHeader() {
+	if !v.header {
+		v.write(csvHeader)
+		v.header = true
+	}
+}
+
+func (v *CSVRenderer) write(row []string) {
+	if err := v.csv.Write(row); err != nil {
+		panic(err)
+	}
+}
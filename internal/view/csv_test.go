@@ -0,0 +1,98 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+)
+
+// TestNewRenderer_CSV tests the NewRenderer function, which should return a CSVRenderer
+// for both CSV and TSV, and bind provided io.Writer to the view's stream writer.
+func TestNewRenderer_CSV(t *testing.T) {
+	for _, vt := range []arguments.ViewType{arguments.ViewCSV, arguments.ViewTSV} {
+		t.Run(vt.String(), func(t *testing.T) {
+			b := bytes.Buffer{}
+			r := NewRenderer(vt, NewView(&b))
+
+			// Check that the view is a CSVRenderer
+			csvRenderer, ok := r.(*CSVRenderer)
+			assert.True(t, ok, "Expected r to be of type *CSVRenderer")
+
+			// Check that the view's stream writer is the same as the buffer
+			assert.Equal(t, &b, csvRenderer.view.Stream.Writer)
+		})
+	}
+}
+
+func TestCSVRenderer_Render(t *testing.T) {
+	domain := "example.com"
+	records := []dns.RR{
+		&dns.MX{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeMX,
+				Class:  dns.ClassINET,
+				Ttl:    500,
+			},
+			Preference: 10,
+			Mx:         "mail.example.com.",
+		},
+		&dns.TXT{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeTXT,
+				Class:  dns.ClassINET,
+				Ttl:    500,
+			},
+			Txt: []string{`say "hello", world`},
+		},
+		&dns.TXT{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeTXT,
+				Class:  dns.ClassINET,
+				Ttl:    500,
+			},
+			Txt: []string{"v=DKIM1; k=rsa; ", "p=MIGf"},
+		},
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		b := bytes.Buffer{}
+		r := NewCSVRenderer(NewView(&b), ',')
+
+		r.Render(domain, newResponse(records...))
+		assert.NoError(t, r.Flush())
+
+		want := "domain,name,type,ttl,value,server,rcode\n" +
+			"example.com,example.com.,MX,500,10 mail.example.com.,127.0.0.1:53,NOERROR\n" +
+			`example.com,example.com.,TXT,500,"""say \""hello\"", world""",127.0.0.1:53,NOERROR` + "\n" +
+			`example.com,example.com.,TXT,500,"""v=DKIM1; k=rsa; "" ""p=MIGf""",127.0.0.1:53,NOERROR` + "\n"
+		assert.Equal(t, want, b.String())
+	})
+
+	t.Run("TSV", func(t *testing.T) {
+		b := bytes.Buffer{}
+		r := NewCSVRenderer(NewView(&b), '\t')
+
+		r.Render(domain, newResponse(records...))
+		assert.NoError(t, r.Flush())
+
+		want := "domain\tname\ttype\tttl\tvalue\tserver\trcode\n" +
+			"example.com\texample.com.\tMX\t500\t10 mail.example.com.\t127.0.0.1:53\tNOERROR\n" +
+			"example.com\texample.com.\tTXT\t500\t\"\"\"say \\\"\"hello\\\"\", world\"\"\"\t127.0.0.1:53\tNOERROR\n" +
+			"example.com\texample.com.\tTXT\t500\t\"\"\"v=DKIM1; k=rsa; \"\" \"\"p=MIGf\"\"\"\t127.0.0.1:53\tNOERROR\n"
+		assert.Equal(t, want, b.String())
+	})
+
+	t.Run("no records", func(t *testing.T) {
+		b := bytes.Buffer{}
+		r := NewCSVRenderer(NewView(&b), ',')
+
+		assert.NoError(t, r.Flush())
+		assert.Equal(t, "domain,name,type,ttl,value,server,rcode\n", b.String())
+	})
+}
@@ -0,0 +1,185 @@
+package view
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+	znsversion "github.com/znscli/zns/version"
+)
+
+// digTimeFormat is the layout of the WHEN line in dig's footer.
+const digTimeFormat = "Mon Jan 02 15:04:05 MST 2006"
+
+// DigRenderer for rendering DNS responses in the layout of dig, so the output can be used wherever dig output is expected.
+// Every response is rendered as a complete message, including the header, the OPT pseudo-section, all sections and the footer.
+type DigRenderer struct {
+	view     *View
+	rendered bool
+	now      func() time.Time
+}
+
+// Validate that DigRenderer implements the Renderer interface.
+var _ Renderer = (*DigRenderer)(nil)
+
+// NewDigRenderer creates a DigRenderer bound to an output stream.
+func NewDigRenderer(view *View) *DigRenderer {
+	return &DigRenderer{
+		view: view,
+		now:  time.Now,
+	}
+}
+
+// Render renders a DNS response in dig's layout to the output stream.
+func (v *DigRenderer) Render(domain string, resp *query.Response) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "\n; <<>> zns %s <<>> %s %s\n", znsversion.Version, domain, dns.TypeToString[resp.Question[0].Qtype])
+	if !v.rendered {
+		b.WriteString(";; global options: +cmd\n")
+		v.rendered = true
+	}
+
+	b.WriteString(";; Got answer:\n")
+	fmt.Fprintf(&b, ";; ->>HEADER<<- opcode: %s, status: %s, id: %d\n", dns.OpcodeToString[resp.Opcode], dns.RcodeToString[resp.Rcode], resp.Id)
+	fmt.Fprintf(&b, ";; flags: %s; QUERY: %d, ANSWER: %d, AUTHORITY: %d, ADDITIONAL: %d\n",
+		digFlags(resp.Msg), len(resp.Question), len(resp.Answer), len(resp.Ns), len(resp.Extra))
+
+	// dig separates the OPT pseudo-section from the header, but not from the question section.
+	b.WriteString("\n")
+	if opt := resp.IsEdns0(); opt != nil {
+		b.WriteString(";; OPT PSEUDOSECTION:\n")
+		writeDigOPT(&b, opt)
+	}
+
+	b.WriteString(";; QUESTION SECTION:\n")
+	for _, q := range resp.Question {
+		line := digColumn(";"+q.Name, 32) + dns.ClassToString[q.Qclass]
+		b.WriteString(digColumn(line, 40) + dns.TypeToString[q.Qtype] + "\n")
+	}
+	b.WriteString("\n")
+
+	writeDigSection(&b, "ANSWER", resp.Answer)
+	writeDigSection(&b, "AUTHORITY", resp.Ns)
+	writeDigSection(&b, "ADDITIONAL", resp.Extra)
+
+	host, port, err := net.SplitHostPort(resp.Server)
+	if err != nil {
+		host, port = resp.Server, "53"
+	}
+
+	transport := resp.Transport
+	if transport == "" {
+		transport = "UDP"
+	}
+
+	fmt.Fprintf(&b, ";; Query time: %d msec\n", resp.RTT.Milliseconds())
+	fmt.Fprintf(&b, ";; SERVER: %s#%s(%s) (%s)\n", host, port, host, transport)
+	fmt.Fprintf(&b, ";; WHEN: %s\n", v.now().Format(digTimeFormat))
+	fmt.Fprintf(&b, ";; MSG SIZE  rcvd: %d\n", resp.Size)
+
+	_, err = v.view.Stream.Writer.Write([]byte(b.String()))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// writeDigSection writes a section of a DNS message, skipping the OPT pseudo-record and empty sections like dig does.
+func writeDigSection(b *strings.Builder, name string, records []dns.RR) {
+	var lines []string
+	for _, record := range records {
+		if record.Header().Rrtype == dns.TypeOPT {
+			continue
+		}
+		lines = append(lines, digRecord(record))
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, ";; %s SECTION:\n", name)
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+}
+
+// writeDigOPT writes the EDNS version, flags, UDP payload size and options of an OPT pseudo-record.
+func writeDigOPT(b *strings.Builder, opt *dns.OPT) {
+	var flags string
+	if opt.Do() {
+		flags = " do"
+	}
+	fmt.Fprintf(b, "; EDNS: version: %d, flags:%s; udp: %d\n", opt.Version(), flags, opt.UDPSize())
+
+	for _, o := range opt.Option {
+		switch o := o.(type) {
+		case *dns.EDNS0_COOKIE:
+			fmt.Fprintf(b, "; COOKIE: %s\n", o.Cookie)
+		case *dns.EDNS0_NSID:
+			fmt.Fprintf(b, "; NSID: %s\n", o.Nsid)
+		default:
+			fmt.Fprintf(b, "; OPT=%d: %s\n", o.Option(), o.String())
+		}
+	}
+}
+
+// digFlags returns the header flags that are set, in the order dig lists them.
+func digFlags(msg *dns.Msg) string {
+	return strings.Join(headerFlags(msg), " ")
+}
+
+// headerFlags returns the names of the header flags of a message that are set, in the order dig lists them.
+func headerFlags(msg *dns.Msg) []string {
+	flags := []string{}
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"qr", msg.Response},
+		{"aa", msg.Authoritative},
+		{"tc", msg.Truncated},
+		{"rd", msg.RecursionDesired},
+		{"ra", msg.RecursionAvailable},
+		{"ad", msg.AuthenticatedData},
+		{"cd", msg.CheckingDisabled},
+	} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+	return flags
+}
+
+// digRecord formats a record with its fields aligned to dig's tab stops.
+func digRecord(record dns.RR) string {
+	h := record.Header()
+	line := digColumn(h.Name, 24) + fmt.Sprint(h.Ttl)
+	line = digColumn(line, 32) + dns.ClassToString[h.Class]
+	line = digColumn(line, 40) + dns.TypeToString[h.Rrtype]
+	return digColumn(line, 48) + query.Rdata(record)
+}
+
+// digColumn pads a line with tabs up to the given column, assuming tab stops every 8 characters.
+// Like dig, a single space is used instead when the line already reaches past the column.
+func digColumn(line string, column int) string {
+	n := 0
+	for _, c := range line {
+		if c == '\t' {
+			n = (n/8 + 1) * 8
+		} else {
+			n++
+		}
+	}
+	if n >= column {
+		return line + " "
+	}
+	for n < column {
+		line += "\t"
+		n = (n/8 + 1) * 8
+	}
+	return line
+}
@@ -0,0 +1,102 @@
+package view
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	znsversion "github.com/znscli/zns/version"
+)
+
+// TestNewRenderer_Dig tests the NewRenderer function, which should return a DigRenderer
+// and bind provided io.Writer to the view's stream writer.
+func TestNewRenderer_Dig(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewRenderer(arguments.ViewDig, NewView(&b))
+
+	// Check that the view is a DigRenderer
+	digRenderer, ok := r.(*DigRenderer)
+	assert.True(t, ok, "Expected r to be of type *DigRenderer")
+
+	// Check that the view's stream writer is the same as the buffer
+	assert.Equal(t, &b, digRenderer.view.Stream.Writer)
+}
+
+func TestDigRenderer_Render(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewDigRenderer(NewView(&b))
+	r.now = func() time.Time {
+		return time.Date(2024, 12, 17, 0, 4, 6, 0, time.UTC)
+	}
+
+	resp := newResponse(
+		&dns.A{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 222},
+			A:   net.IPv4(93, 184, 216, 34),
+		},
+	)
+	resp.Id = 4242
+	resp.Response = true
+	resp.RecursionDesired = true
+	resp.RecursionAvailable = true
+	resp.Ns = []dns.RR{
+		&dns.NS{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: 86400},
+			Ns:  "a.iana-servers.net.",
+		},
+	}
+	resp.SetEdns0(1232, true)
+	resp.Size = 100
+
+	empty := newResponse()
+	empty.Id = 4243
+
+	r.Render("example.com", resp)
+	r.Render("example.com", empty)
+
+	want := "\n; <<>> zns " + znsversion.Version + " <<>> example.com A\n" +
+		";; global options: +cmd\n" +
+		";; Got answer:\n" +
+		";; ->>HEADER<<- opcode: QUERY, status: NOERROR, id: 4242\n" +
+		";; flags: qr rd ra; QUERY: 1, ANSWER: 1, AUTHORITY: 1, ADDITIONAL: 1\n" +
+		"\n" +
+		";; OPT PSEUDOSECTION:\n" +
+		"; EDNS: version: 0, flags: do; udp: 1232\n" +
+		";; QUESTION SECTION:\n" +
+		";example.com.\t\t\tIN\tA\n" +
+		"\n" +
+		";; ANSWER SECTION:\n" +
+		"example.com.\t\t222\tIN\tA\t93.184.216.34\n" +
+		"\n" +
+		";; AUTHORITY SECTION:\n" +
+		"example.com.\t\t86400\tIN\tNS\ta.iana-servers.net.\n" +
+		"\n" +
+		";; Query time: 1 msec\n" +
+		";; SERVER: 127.0.0.1#53(127.0.0.1) (UDP)\n" +
+		";; WHEN: Tue Dec 17 00:04:06 UTC 2024\n" +
+		";; MSG SIZE  rcvd: 100\n" +
+		"\n; <<>> zns " + znsversion.Version + " <<>> example.com A\n" +
+		";; Got answer:\n" +
+		";; ->>HEADER<<- opcode: QUERY, status: NOERROR, id: 4243\n" +
+		";; flags: qr rd; QUERY: 1, ANSWER: 0, AUTHORITY: 0, ADDITIONAL: 0\n" +
+		"\n" +
+		";; QUESTION SECTION:\n" +
+		";example.com.\t\t\tIN\tA\n" +
+		"\n" +
+		";; Query time: 1 msec\n" +
+		";; SERVER: 127.0.0.1#53(127.0.0.1) (UDP)\n" +
+		";; WHEN: Tue Dec 17 00:04:06 UTC 2024\n" +
+		";; MSG SIZE  rcvd: 45\n"
+
+	assert.Equal(t, want, b.String())
+}
+
+func TestDigColumn(t *testing.T) {
+	assert.Equal(t, "example.com.\t\t", digColumn("example.com.", 24))
+	assert.Equal(t, "a-very-long-name.example.com. ", digColumn("a-very-long-name.example.com.", 24))
+	assert.Equal(t, "example.com.\t\t60\t", digColumn("example.com.\t\t60", 32))
+}
@@ -0,0 +1,216 @@
+package view
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+	znsversion "github.com/znscli/zns/version"
+)
+
+// DocumentSchemaVersion is the version of the JSON document schema.
+// New fields may be added at any time, but the version is bumped whenever
+// a field is removed, renamed or changes its meaning.
+const DocumentSchemaVersion = 1
+
+// Document is the structured representation of a single zns run.
+type Document struct {
+	SchemaVersion int             `json:"schema_version"`
+	Version       string          `json:"version"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Domain        string          `json:"domain"`
+	Queries       []DocumentQuery `json:"queries"`
+	CNAMEChain    *DocumentChain  `json:"cname_chain,omitempty"`
+}
+
+// DocumentQuery holds a single query of a zns run, along with the response it received.
+type DocumentQuery struct {
+	Name      string           `json:"name"`
+	Type      string           `json:"type"`
+	Server    string           `json:"server"`
+	Transport string           `json:"transport"`
+	RTT       float64          `json:"rtt_ms"`
+	Size      int              `json:"size"`
+	Rcode     string           `json:"rcode"`
+	Flags     []string         `json:"flags"`
+	Answers   []DocumentRecord `json:"answers"`
+}
+
+// DocumentRecord holds a single resource record.
+// Value is the record data in presentation format, as found in zone files.
+// Data holds the same record data as typed fields, and is omitted for record types zns does not know about.
+type DocumentRecord struct {
+	Name  string         `json:"name" yaml:"name"`
+	Type  string         `json:"type" yaml:"type"`
+	Class string         `json:"class" yaml:"class"`
+	TTL   uint32         `json:"ttl" yaml:"ttl"`
+	Value string         `json:"value" yaml:"value"`
+	Data  map[string]any `json:"rdata,omitempty" yaml:"rdata,omitempty"`
+
+	// Wildcard is set for records synthesized from a wildcard, if wildcard detection was enabled.
+	Wildcard bool `json:"wildcard,omitempty" yaml:"wildcard,omitempty"`
+}
+
+// DocumentChain holds the CNAME chain the queried domain resolved through.
+type DocumentChain struct {
+	Names     []string `json:"names" yaml:"names"`
+	Loop      bool     `json:"loop" yaml:"loop"`
+	Truncated bool     `json:"truncated" yaml:"truncated"`
+	Apex      []string `json:"apex" yaml:"apex"`
+}
+
+// newDocumentQuery converts a DNS response into its document representation.
+func newDocumentQuery(resp *query.Response) DocumentQuery {
+	q := DocumentQuery{
+		Name:      resp.Question[0].Name,
+		Type:      dns.TypeToString[resp.Question[0].Qtype],
+		Server:    resp.Server,
+		Transport: resp.Transport,
+		RTT:       float64(resp.RTT) / float64(time.Millisecond),
+		Size:      resp.Size,
+		Rcode:     dns.RcodeToString[resp.Rcode],
+		Flags:     headerFlags(resp.Msg),
+		Answers:   make([]DocumentRecord, 0, len(resp.Answer)),
+	}
+	for _, record := range resp.Answer {
+		q.Answers = append(q.Answers, newDocumentRecord(resp, record))
+	}
+	return q
+}
+
+// newDocumentRecord converts a resource record of a DNS response into its document representation.
+func newDocumentRecord(resp *query.Response, record dns.RR) DocumentRecord {
+	return DocumentRecord{
+		Name:     record.Header().Name,
+		Type:     dns.TypeToString[record.Header().Rrtype],
+		Class:    dns.ClassToString[record.Header().Class],
+		TTL:      record.Header().Ttl,
+		Value:    query.Rdata(record),
+		Data:     formatRdata(record),
+		Wildcard: resp.IsWildcard(record),
+	}
+}
+
+// newDocumentChain converts a CNAME chain into its document representation.
+func newDocumentChain(chain *query.Chain) DocumentChain {
+	return DocumentChain{
+		Names:     chain.Names,
+		Loop:      chain.Loop,
+		Truncated: chain.Truncated,
+		Apex:      append([]string{}, chain.Apex...),
+	}
+}
+
+// formatRdata generates a map of typed record data fields, named after the fields of the record type's RFC.
+func formatRdata(record dns.RR) map[string]any {
+	switch rec := record.(type) {
+	case *dns.A:
+		return map[string]any{"address": rec.A.String()}
+	case *dns.AAAA:
+		return map[string]any{"address": rec.AAAA.String()}
+	case *dns.CNAME:
+		return map[string]any{"target": rec.Target}
+	case *dns.MX:
+		return map[string]any{"preference": rec.Preference, "exchange": rec.Mx}
+	case *dns.TXT:
+		return map[string]any{"strings": rec.Txt}
+	case *dns.NS:
+		return map[string]any{"nameserver": rec.Ns}
+	case *dns.SOA:
+		return map[string]any{
+			"mname":   rec.Ns,
+			"rname":   rec.Mbox,
+			"serial":  rec.Serial,
+			"refresh": rec.Refresh,
+			"retry":   rec.Retry,
+			"expire":  rec.Expire,
+			"minimum": rec.Minttl,
+		}
+	case *dns.PTR:
+		return map[string]any{"target": rec.Ptr}
+	case *dns.CAA:
+		return map[string]any{"flags": rec.Flag, "tag": rec.Tag, "value": rec.Value}
+	case *dns.HTTPS:
+		params := make(map[string]string, len(rec.Value))
+		for _, kv := range rec.Value {
+			params[kv.Key().String()] = kv.String()
+		}
+		return map[string]any{"priority": rec.Priority, "target": rec.Target, "params": params}
+	case *dns.NSEC:
+		return map[string]any{"next_domain": rec.NextDomain, "types": query.TypeNames(rec.TypeBitMap)}
+	case *dns.NSEC3:
+		return map[string]any{
+			"hash_algorithm":    rec.Hash,
+			"flags":             rec.Flags,
+			"iterations":        rec.Iterations,
+			"salt":              rec.Salt,
+			"next_hashed_owner": rec.NextDomain,
+			"types":             query.TypeNames(rec.TypeBitMap),
+		}
+	default:
+		return nil
+	}
+}
+
+// JSONDocumentRenderer for rendering a single, versioned JSON document per run.
+// Unlike the JSONRenderer, it buffers all responses and writes the document when flushed.
+type JSONDocumentRenderer struct {
+	view     *View
+	document *Document
+}
+
+// Validate that JSONDocumentRenderer implements the Renderer, Flusher, ChainRenderer and DomainRenderer interfaces.
+var _ Renderer = (*JSONDocumentRenderer)(nil)
+var _ Flusher = (*JSONDocumentRenderer)(nil)
+var _ ChainRenderer = (*JSONDocumentRenderer)(nil)
+var _ DomainRenderer = (*JSONDocumentRenderer)(nil)
+
+// NewJSONDocumentRenderer creates a JSONDocumentRenderer bound to an output stream.
+func NewJSONDocumentRenderer(view *View) *JSONDocumentRenderer {
+	return &JSONDocumentRenderer{
+		view: view,
+		document: &Document{
+			SchemaVersion: DocumentSchemaVersion,
+			Version:       znsversion.Version,
+			Timestamp:     time.Now().UTC(),
+			Queries:       []DocumentQuery{},
+		},
+	}
+}
+
+// Render adds a DNS response to the document.
+// When the answers of a query are rendered in several parts, as happens when they are sorted or grouped,
+// the parts are merged back into a single query.
+func (v *JSONDocumentRenderer) Render(domain string, resp *query.Response) {
+	if v.document.Domain == "" {
+		v.document.Domain = domain
+	}
+
+	q := newDocumentQuery(resp)
+	for i := range v.document.Queries {
+		if v.document.Queries[i].Name == q.Name && v.document.Queries[i].Type == q.Type {
+			v.document.Queries[i].Answers = append(v.document.Queries[i].Answers, q.Answers...)
+			return
+		}
+	}
+	v.document.Queries = append(v.document.Queries, q)
+}
+
+// SetDomain sets the domain of the document.
+func (v *JSONDocumentRenderer) SetDomain(domain string) {
+	v.document.Domain = domain
+}
+
+// RenderChain adds the CNAME chain of a domain to the document.
+func (v *JSONDocumentRenderer) RenderChain(domain string, chain *query.Chain) {
+	documentChain := newDocumentChain(chain)
+	v.document.CNAMEChain = &documentChain
+}
+
+// Flush writes the document to the output stream.
+func (v *JSONDocumentRenderer) Flush() error {
+	enc := json.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v.document)
+}
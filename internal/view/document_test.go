@@ -0,0 +1,221 @@
+package view
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/query"
+	znsversion "github.com/znscli/zns/version"
+)
+
+// TestNewRenderer_JSONDocument tests the NewRenderer function, which should return a JSONDocumentRenderer
+// and bind provided io.Writer to the view's stream writer.
+func TestNewRenderer_JSONDocument(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewRenderer(arguments.ViewJSONDocument, NewView(&b))
+
+	// Check that the view is a JSONDocumentRenderer
+	documentRenderer, ok := r.(*JSONDocumentRenderer)
+	assert.True(t, ok, "Expected r to be of type *JSONDocumentRenderer")
+
+	// Check that the view's stream writer is the same as the buffer
+	assert.Equal(t, &b, documentRenderer.view.Stream.Writer)
+}
+
+func TestFormatRdata(t *testing.T) {
+	t.Run("MX record", func(t *testing.T) {
+		record := &dns.MX{
+			Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeMX, Class: dns.ClassINET, Ttl: 500},
+			Preference: 10,
+			Mx:         "mail.example.com.",
+		}
+
+		assert.Equal(t, map[string]any{"preference": uint16(10), "exchange": "mail.example.com."}, formatRdata(record))
+	})
+
+	t.Run("TXT record", func(t *testing.T) {
+		record := &dns.TXT{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 500},
+			Txt: []string{"v=spf1", "-all"},
+		}
+
+		assert.Equal(t, map[string]any{"strings": []string{"v=spf1", "-all"}}, formatRdata(record))
+	})
+
+	t.Run("CAA record", func(t *testing.T) {
+		record := &dns.CAA{
+			Hdr:   dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeCAA, Class: dns.ClassINET, Ttl: 500},
+			Tag:   "issue",
+			Value: "letsencrypt.org",
+		}
+
+		assert.Equal(t, map[string]any{"flags": uint8(0), "tag": "issue", "value": "letsencrypt.org"}, formatRdata(record))
+	})
+
+	t.Run("NSEC record", func(t *testing.T) {
+		record := &dns.NSEC{
+			Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: 300},
+			NextDomain: "www.example.com.",
+			TypeBitMap: []uint16{dns.TypeA, dns.TypeNSEC},
+		}
+
+		assert.Equal(t, map[string]any{"next_domain": "www.example.com.", "types": []string{"A", "NSEC"}}, formatRdata(record))
+	})
+
+	t.Run("NSEC3 record", func(t *testing.T) {
+		record := &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: "9ofpb6v0m1q3htqf5gv1q7ntjhkq4lrp.example.com.", Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 300},
+			Hash:       dns.SHA1,
+			Iterations: 10,
+			Salt:       "aabb",
+			NextDomain: "k4aqjg4gmba2k8dpv0j4apvjcojj2gcp",
+			TypeBitMap: []uint16{dns.TypeA},
+		}
+
+		assert.Equal(t, map[string]any{
+			"hash_algorithm":    uint8(1),
+			"flags":             uint8(0),
+			"iterations":        uint16(10),
+			"salt":              "aabb",
+			"next_hashed_owner": "k4aqjg4gmba2k8dpv0j4apvjcojj2gcp",
+			"types":             []string{"A"},
+		}, formatRdata(record))
+	})
+
+	t.Run("Unknown record type", func(t *testing.T) {
+		record := &dns.SVCB{
+			Hdr:      dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSVCB, Class: dns.ClassINET, Ttl: 500},
+			Priority: 10,
+		}
+
+		assert.Nil(t, formatRdata(record))
+	})
+}
+
+// TestJSONDocumentRenderer_Render tests that the renderer writes nothing until flushed,
+// and then writes a single JSON document holding all responses.
+func TestJSONDocumentRenderer_Render(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewJSONDocumentRenderer(NewView(&b))
+
+	domain := "example.com"
+	record := &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   "example.com.",
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    222,
+		},
+		A: net.IPv4(127, 0, 0, 1),
+	}
+
+	r.Render(domain, newResponse(record))
+	r.RenderChain(domain, &query.Chain{Names: []string{"example.com."}})
+
+	assert.Empty(t, b.String())
+	assert.NoError(t, r.Flush())
+
+	var got map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, got, "timestamp")
+	delete(got, "timestamp")
+
+	want := map[string]any{
+		"schema_version": float64(DocumentSchemaVersion),
+		"version":        znsversion.Version,
+		"domain":         "example.com",
+		"queries": []any{
+			map[string]any{
+				"name":      "example.com.",
+				"type":      "A",
+				"server":    "127.0.0.1:53",
+				"transport": "UDP",
+				"rtt_ms":    1.5,
+				"size":      float64(45),
+				"rcode":     "NOERROR",
+				"flags":     []any{"qr", "rd"},
+				"answers": []any{
+					map[string]any{
+						"name":  "example.com.",
+						"type":  "A",
+						"class": "IN",
+						"ttl":   float64(222),
+						"value": "127.0.0.1",
+						"rdata": map[string]any{"address": "127.0.0.1"},
+					},
+				},
+			},
+		},
+		"cname_chain": map[string]any{
+			"names":     []any{"example.com."},
+			"loop":      false,
+			"truncated": false,
+			"apex":      []any{},
+		},
+	}
+
+	assert.Equal(t, want, got)
+}
+
+// TestJSONDocumentRenderer_Render_Parts tests that the answers of a query that are rendered in several parts,
+// as happens when they are sorted or grouped, are merged into a single query.
+func TestJSONDocumentRenderer_Render_Parts(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewJSONDocumentRenderer(NewView(&b))
+
+	domain := "example.com"
+	first := &dns.A{
+		Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 222},
+		A:   net.IPv4(127, 0, 0, 1),
+	}
+	second := &dns.A{
+		Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 222},
+		A:   net.IPv4(127, 0, 0, 2),
+	}
+
+	r.Render(domain, newResponse(first))
+	r.Render(domain, newResponse(second))
+
+	assert.Len(t, r.document.Queries, 1)
+	assert.Equal(t, []DocumentRecord{newDocumentRecord(newResponse(first), first), newDocumentRecord(newResponse(second), second)}, r.document.Queries[0].Answers)
+}
+
+func TestJSONDocumentRenderer_Render_Wildcard(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewJSONDocumentRenderer(NewView(&b))
+
+	record := &dns.A{
+		Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 222},
+		A:   net.IPv4(127, 0, 0, 1),
+	}
+	resp := newResponse(record)
+	resp.Wildcards = map[string]bool{record.String(): true}
+
+	r.Render("example.com", resp)
+
+	assert.True(t, r.document.Queries[0].Answers[0].Wildcard)
+	assert.NoError(t, r.Flush())
+	assert.Contains(t, b.String(), `"wildcard": true`)
+}
+
+func TestJSONDocumentRenderer_SetDomain(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewJSONDocumentRenderer(NewView(&b))
+
+	record := &dns.A{
+		Hdr: dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 222},
+		A:   net.IPv4(127, 0, 0, 1),
+	}
+
+	r.SetDomain("example.com")
+	r.Render("www.example.com.", newResponse(record))
+
+	assert.Equal(t, "example.com", r.document.Domain)
+}
@@ -10,6 +10,9 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/query"
+	"github.com/znscli/zns/internal/watch"
 )
 
 // NewTabWriter initializes and returns a new tabwriter.Writer.
@@ -30,14 +33,17 @@ func NewTabWriter(w io.Writer, debug bool) *tabwriter.Writer {
 	)
 }
 
-// formatTTL converts TTL to a more readable format (hours, minutes, seconds).
+// formatTTL converts TTL to a more readable format (days, hours, minutes, seconds).
 func formatTTL(ttl uint32) string {
 	duration := time.Duration(ttl) * time.Second
-	hours := int(duration.Hours())
+	days := int(duration.Hours()) / 24
+	hours := int(duration.Hours()) % 24
 	minutes := int(duration.Minutes()) % 60
 	seconds := int(duration.Seconds()) % 60
 
-	if hours > 0 {
+	if days > 0 {
+		return fmt.Sprintf("%dd%02dh%02dm%02ds", days, hours, minutes, seconds)
+	} else if hours > 0 {
 		return fmt.Sprintf("%02dh%02dm%02ds", hours, minutes, seconds)
 	} else if minutes > 0 {
 		return fmt.Sprintf("%02dm%02ds", minutes, seconds)
@@ -45,12 +51,61 @@ func formatTTL(ttl uint32) string {
 	return fmt.Sprintf("%02ds", seconds)
 }
 
+// formatTTLAs formats a TTL as selected by the --ttl flag. Expiry times are the wall-clock time
+// at which a record received at now expires from caches.
+func formatTTLAs(tf arguments.TTLFormat, ttl uint32, now time.Time) string {
+	switch tf {
+	case arguments.TTLRaw:
+		return strconv.FormatUint(uint64(ttl), 10)
+	case arguments.TTLExpiry:
+		return now.Add(time.Duration(ttl) * time.Second).Format(time.RFC3339)
+	default:
+		return formatTTL(ttl)
+	}
+}
+
+// formatStats formats the metadata of the response a record was returned in, for the human view: the server,
+// transport, round-trip time, size, response code and header flags.
+func formatStats(resp *query.Response) string {
+	stats := fmt.Sprintf("%s/%s %s %dB %s %s",
+		resp.Server,
+		resp.Transport,
+		formatMilliseconds(float64(resp.RTT)/float64(time.Millisecond)),
+		resp.Size,
+		dns.RcodeToString[resp.Rcode],
+		strings.Join(headerFlags(resp.Msg), " "),
+	)
+	return color.HiBlackString(strings.TrimSpace(stats))
+}
+
+// formatStatsAsJSON generates a map of the metadata of the response a record was returned in, for JSON rendering.
+func formatStatsAsJSON(resp *query.Response) map[string]interface{} {
+	return map[string]interface{}{
+		"@server":    resp.Server,
+		"@transport": resp.Transport,
+		"@rtt_ms":    float64(resp.RTT) / float64(time.Millisecond),
+		"@size":      resp.Size,
+		"@rcode":     dns.RcodeToString[resp.Rcode],
+		"@flags":     headerFlags(resp.Msg),
+	}
+}
+
+// formatMilliseconds formats a duration in milliseconds.
+func formatMilliseconds(ms float64) string {
+	return fmt.Sprintf("%.1fms", ms)
+}
+
 // formatRecordAsJSON generates a map of DNS record fields for JSON rendering.
-func formatRecordAsJSON(domain string, answer dns.RR) map[string]interface{} {
+// Raw TTLs are numbers, while human-readable TTLs and expiry times are strings.
+func formatRecordAsJSON(domain string, answer dns.RR, tf arguments.TTLFormat, now time.Time) map[string]interface{} {
 	m := make(map[string]interface{})
 	m["@domain"] = domain
 	m["@type"] = dns.TypeToString[answer.Header().Rrtype]
-	m["@ttl"] = formatTTL(answer.Header().Ttl)
+	if tf == arguments.TTLRaw {
+		m["@ttl"] = answer.Header().Ttl
+	} else {
+		m["@ttl"] = formatTTLAs(tf, answer.Header().Ttl, now)
+	}
 
 	// Add specific fields depending on the record type
 	switch rec := answer.(type) {
@@ -65,6 +120,7 @@ func formatRecordAsJSON(domain string, answer dns.RR) map[string]interface{} {
 		m["@record"] = rec.Mx
 	case *dns.TXT:
 		m["@record"] = strings.Join(rec.Txt, " ")
+		m["@strings"] = rec.Txt
 	case *dns.NS:
 		m["@record"] = rec.Ns
 	case *dns.SOA:
@@ -72,6 +128,23 @@ func formatRecordAsJSON(domain string, answer dns.RR) map[string]interface{} {
 		m["@mbox"] = rec.Mbox
 	case *dns.PTR:
 		m["@record"] = rec.Ptr
+	case *dns.CAA:
+		m["@flag"] = rec.Flag
+		m["@tag"] = rec.Tag
+		m["@record"] = rec.Value
+	case *dns.HTTPS:
+		m["@priority"] = rec.Priority
+		m["@record"] = rec.Target
+	case *dns.NSEC:
+		m["@record"] = rec.NextDomain
+		m["@types"] = query.TypeNames(rec.TypeBitMap)
+	case *dns.NSEC3:
+		m["@hashAlgorithm"] = rec.Hash
+		m["@flags"] = rec.Flags
+		m["@iterations"] = rec.Iterations
+		m["@salt"] = rec.Salt
+		m["@record"] = rec.NextDomain
+		m["@types"] = query.TypeNames(rec.TypeBitMap)
 	default:
 		m["@record"] = fmt.Sprintf("Unknown record type: %s", dns.TypeToString[answer.Header().Rrtype])
 	}
@@ -79,10 +152,29 @@ func formatRecordAsJSON(domain string, answer dns.RR) map[string]interface{} {
 	return m
 }
 
+// formatSalt returns the salt of an NSEC3 record in presentation format, where an empty salt is "-".
+func formatSalt(salt string) string {
+	if salt == "" {
+		return "-"
+	}
+	return salt
+}
+
+// ownerName returns the name that owns the record, without the trailing dot.
+// Records that are reached through a CNAME chain are owned by the CNAME's target instead of the queried domain.
+func ownerName(domainName string, answer dns.RR) string {
+	if answer.Header().Name == "" {
+		return domainName
+	}
+	return strings.TrimSuffix(answer.Header().Name, ".")
+}
+
 // formatRecord generates a human-readable string representing a DNS record with colors.
-func formatRecord(domainName string, answer dns.RR) string {
+// The TTL is displayed as selected by the --ttl flag, relative to now for expiry times.
+func formatRecord(domainName string, answer dns.RR, tf arguments.TTLFormat, now time.Time) string {
+	domainName = ownerName(domainName, answer)
 	recordType := color.HiYellowString(dns.TypeToString[answer.Header().Rrtype])
-	formattedTTL := color.HiMagentaString(formatTTL(answer.Header().Ttl))
+	formattedTTL := color.HiMagentaString(formatTTLAs(tf, answer.Header().Ttl, now))
 
 	switch rec := answer.(type) {
 	case *dns.A:
@@ -104,6 +196,23 @@ func formatRecord(domainName string, answer dns.RR) string {
 		return fmt.Sprintf("%s\t%s.\t%s\t%s %s", recordType, color.HiBlueString(domainName), formattedTTL, primaryNameServer, color.HiWhiteString(rec.Mbox))
 	case *dns.PTR:
 		return fmt.Sprintf("%s\t%s.\t%s\t%s", recordType, color.HiBlueString(domainName), formattedTTL, color.HiWhiteString(rec.Ptr))
+	case *dns.CAA:
+		tag := color.HiRedString(fmt.Sprintf("%d %s", rec.Flag, rec.Tag))
+		return fmt.Sprintf("%s\t%s.\t%s\t%s %s", recordType, color.HiBlueString(domainName), formattedTTL, tag, color.HiWhiteString(strconv.Quote(rec.Value)))
+	case *dns.HTTPS:
+		priority := color.HiRedString(strconv.FormatUint(uint64(rec.Priority), 10))
+		value := rec.Target
+		for _, kv := range rec.Value {
+			value += " " + kv.Key().String() + "=" + kv.String()
+		}
+		return fmt.Sprintf("%s\t%s.\t%s\t%s %s", recordType, color.HiBlueString(domainName), formattedTTL, priority, color.HiWhiteString(value))
+	case *dns.NSEC:
+		types := color.HiRedString(strings.Join(query.TypeNames(rec.TypeBitMap), " "))
+		return fmt.Sprintf("%s\t%s.\t%s\t%s %s", recordType, color.HiBlueString(domainName), formattedTTL, color.HiWhiteString(rec.NextDomain), types)
+	case *dns.NSEC3:
+		params := color.HiRedString(fmt.Sprintf("%d %d %d %s", rec.Hash, rec.Flags, rec.Iterations, formatSalt(rec.Salt)))
+		types := color.HiRedString(strings.Join(query.TypeNames(rec.TypeBitMap), " "))
+		return fmt.Sprintf("%s\t%s.\t%s\t%s %s %s", recordType, color.HiBlueString(domainName), formattedTTL, params, color.HiWhiteString(rec.NextDomain), types)
 	default:
 		return fmt.Sprintf(`
 Unknown record type: %s
@@ -117,3 +226,55 @@ Thank you for your contribution!
 `, recordType, recordType)
 	}
 }
+
+// formatChange generates a human-readable, timestamped string representing a change detected in watch mode.
+// Added records are prefixed with a green "+", removed records with a red "-" and changed records with a yellow "~".
+func formatChange(domainName string, change watch.Change, at time.Time, tf arguments.TTLFormat) string {
+	timestamp := color.HiBlackString(at.Format(time.RFC3339))
+
+	switch change.Type {
+	case watch.Added:
+		return fmt.Sprintf("%s\t%s\t%s", timestamp, color.HiGreenString("+"), formatRecord(domainName, change.New, tf, at))
+	case watch.Removed:
+		return fmt.Sprintf("%s\t%s\t%s", timestamp, color.HiRedString("-"), formatRecord(domainName, change.Old, tf, at))
+	default:
+		previous := color.HiBlackString(fmt.Sprintf("(was %s)", query.Rdata(change.Old)))
+		return fmt.Sprintf("%s\t%s\t%s %s", timestamp, color.HiYellowString("~"), formatRecord(domainName, change.New, tf, at), previous)
+	}
+}
+
+// formatChain generates a human-readable string representing the CNAME chain a domain resolved through,
+// followed by a warning for each problem found in the chain.
+func formatChain(chain *query.Chain) string {
+	names := make([]string, len(chain.Names))
+	for i, name := range chain.Names {
+		names[i] = color.HiBlueString(name)
+	}
+
+	lines := []string{fmt.Sprintf("%s %s", color.HiYellowString("CNAME chain:"), strings.Join(names, " → "))}
+	for _, warning := range chainWarnings(chain) {
+		lines = append(lines, color.HiRedString("Warning: %s", warning))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatGroup generates a human-readable heading for a group of records sharing a type or owner name.
+func formatGroup(key string) string {
+	return color.New(color.Bold, color.FgHiCyan).Sprint(key)
+}
+
+// chainWarnings returns a description of each problem found in a CNAME chain.
+func chainWarnings(chain *query.Chain) []string {
+	var warnings []string
+	if chain.Loop {
+		warnings = append(warnings, fmt.Sprintf("CNAME loop detected at %s", chain.Target()))
+	}
+	if chain.Truncated {
+		warnings = append(warnings, fmt.Sprintf("CNAME chain is longer than %d records, stopped following it", query.MaxCNAMEDepth))
+	}
+	for _, name := range chain.Apex {
+		warnings = append(warnings, fmt.Sprintf("%s has a CNAME next to SOA or NS records, CNAMEs are not allowed at a zone apex", name))
+	}
+	return warnings
+}
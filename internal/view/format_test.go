@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/query"
+	"github.com/znscli/zns/internal/watch"
 )
 
 func TestFormatTTL(t *testing.T) {
@@ -34,6 +38,27 @@ func TestFormatTTL(t *testing.T) {
 		expected := "00s"
 		assert.Equal(t, expected, ttl)
 	})
+
+	t.Run("2 days", func(t *testing.T) {
+		ttl := formatTTL(172800)
+		expected := "2d00h00m00s"
+		assert.Equal(t, expected, ttl)
+	})
+
+	t.Run("1 week 1 hour 1 second", func(t *testing.T) {
+		ttl := formatTTL(608401)
+		expected := "7d01h00m01s"
+		assert.Equal(t, expected, ttl)
+	})
+}
+
+func TestFormatTTLAs(t *testing.T) {
+	now := time.Date(2024, 12, 17, 0, 4, 6, 0, time.UTC)
+
+	assert.Equal(t, "01h00m00s", formatTTLAs(arguments.TTLHuman, 3600, now))
+	assert.Equal(t, "01h00m00s", formatTTLAs(arguments.TTLNone, 3600, now))
+	assert.Equal(t, "172800", formatTTLAs(arguments.TTLRaw, 172800, now))
+	assert.Equal(t, "2024-12-17T01:04:06Z", formatTTLAs(arguments.TTLExpiry, 3600, now))
 }
 
 func TestFormatRecordAsJSON(t *testing.T) {
@@ -49,7 +74,7 @@ func TestFormatRecordAsJSON(t *testing.T) {
 			A: net.IPv4(127, 0, 0, 1),
 		}
 
-		json := formatRecordAsJSON(domain, record)
+		json := formatRecordAsJSON(domain, record, arguments.TTLHuman, time.Now())
 
 		assert.Contains(t, json, "@domain")
 		assert.Contains(t, json, "@type")
@@ -62,6 +87,22 @@ func TestFormatRecordAsJSON(t *testing.T) {
 		assert.Equal(t, "127.0.0.1", json["@record"])
 	})
 
+	t.Run("raw TTL", func(t *testing.T) {
+		record := &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		}
+
+		json := formatRecordAsJSON("example.com", record, arguments.TTLRaw, time.Now())
+
+		assert.Equal(t, uint32(222), json["@ttl"])
+	})
+
 	t.Run("CNAME record", func(t *testing.T) {
 		domain := "example.com"
 		record := &dns.CNAME{
@@ -74,7 +115,7 @@ func TestFormatRecordAsJSON(t *testing.T) {
 			Target: fmt.Sprintf("%s.", domain),
 		}
 
-		json := formatRecordAsJSON(domain, record)
+		json := formatRecordAsJSON(domain, record, arguments.TTLHuman, time.Now())
 
 		assert.Contains(t, json, "@domain")
 		assert.Contains(t, json, "@type")
@@ -100,7 +141,7 @@ func TestFormatRecordAsJSON(t *testing.T) {
 			Mx:         fmt.Sprintf("%s.", domain),
 		}
 
-		json := formatRecordAsJSON(domain, record)
+		json := formatRecordAsJSON(domain, record, arguments.TTLHuman, time.Now())
 
 		assert.Contains(t, json, "@domain")
 		assert.Contains(t, json, "@type")
@@ -115,6 +156,19 @@ func TestFormatRecordAsJSON(t *testing.T) {
 		assert.Equal(t, uint16(10), json["@preference"])
 	})
 
+	t.Run("TXT record", func(t *testing.T) {
+		record := &dns.TXT{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 500},
+			Txt: []string{"v=DKIM1; k=rsa; ", "p=MIGf"},
+		}
+
+		json := formatRecordAsJSON("example.com", record, arguments.TTLHuman, time.Now())
+
+		assert.Equal(t, "v=DKIM1; k=rsa;  p=MIGf", json["@record"])
+		// The strings are kept apart, as joining them loses where one ends and the next begins.
+		assert.Equal(t, []string{"v=DKIM1; k=rsa; ", "p=MIGf"}, json["@strings"])
+	})
+
 	t.Run("SOA record", func(t *testing.T) {
 		domain := "example.com"
 		record := &dns.SOA{
@@ -128,7 +182,7 @@ func TestFormatRecordAsJSON(t *testing.T) {
 			Mbox: "hostmaster.example.com.",
 		}
 
-		json := formatRecordAsJSON(domain, record)
+		json := formatRecordAsJSON(domain, record, arguments.TTLHuman, time.Now())
 
 		assert.Contains(t, json, "@domain")
 		assert.Contains(t, json, "@type")
@@ -143,6 +197,28 @@ func TestFormatRecordAsJSON(t *testing.T) {
 		assert.Equal(t, "hostmaster.example.com.", json["@mbox"])
 	})
 
+	t.Run("NSEC3 record", func(t *testing.T) {
+		domain := "example.com"
+		record := &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: "9ofpb6v0m1q3htqf5gv1q7ntjhkq4lrp.example.com.", Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 300},
+			Hash:       dns.SHA1,
+			Flags:      1,
+			Iterations: 10,
+			Salt:       "aabb",
+			NextDomain: "k4aqjg4gmba2k8dpv0j4apvjcojj2gcp",
+			TypeBitMap: []uint16{dns.TypeA, dns.TypeRRSIG},
+		}
+
+		json := formatRecordAsJSON(domain, record, arguments.TTLHuman, time.Now())
+
+		assert.Equal(t, "NSEC3", json["@type"])
+		assert.Equal(t, uint8(1), json["@flags"])
+		assert.Equal(t, uint16(10), json["@iterations"])
+		assert.Equal(t, "aabb", json["@salt"])
+		assert.Equal(t, "k4aqjg4gmba2k8dpv0j4apvjcojj2gcp", json["@record"])
+		assert.Equal(t, []string{"A", "RRSIG"}, json["@types"])
+	})
+
 	t.Run("Unknown record type", func(t *testing.T) {
 		domain := "example.com"
 		record := &dns.SVCB{
@@ -155,7 +231,7 @@ func TestFormatRecordAsJSON(t *testing.T) {
 			Priority: 10,
 		}
 
-		json := formatRecordAsJSON(domain, record)
+		json := formatRecordAsJSON(domain, record, arguments.TTLHuman, time.Now())
 
 		assert.Contains(t, json, "@domain")
 		assert.Contains(t, json, "@type")
@@ -181,7 +257,7 @@ func TestFormatRecord(t *testing.T) {
 
 		t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
 
-		r := formatRecord(domain, record)
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
 		assert.Equal(t, "A\texample.com.\t03m42s\t127.0.0.1", r)
 	})
 
@@ -197,7 +273,7 @@ func TestFormatRecord(t *testing.T) {
 			Target: fmt.Sprintf("%s.", domain),
 		}
 
-		r := formatRecord(domain, record)
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
 		assert.Equal(t, "CNAME\texample.com.\t08m20s\texample.com.", r)
 	})
 
@@ -214,7 +290,7 @@ func TestFormatRecord(t *testing.T) {
 			Mx:         fmt.Sprintf("%s.", domain),
 		}
 
-		r := formatRecord(domain, record)
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
 		assert.Equal(t, "MX\texample.com.\t08m20s\t10 example.com.", r)
 	})
 
@@ -231,10 +307,86 @@ func TestFormatRecord(t *testing.T) {
 			Mbox: "hostmaster.example.com.",
 		}
 
-		r := formatRecord(domain, record)
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
 		assert.Equal(t, "SOA\texample.com.\t08m20s\texample.com. hostmaster.example.com.", r)
 	})
 
+	t.Run("CAA record", func(t *testing.T) {
+		domain := "example.com"
+		record := &dns.CAA{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeCAA,
+				Class:  dns.ClassINET,
+				Ttl:    500,
+			},
+			Tag:   "issue",
+			Value: "letsencrypt.org",
+		}
+
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
+		assert.Equal(t, "CAA\texample.com.\t08m20s\t0 issue \"letsencrypt.org\"", r)
+	})
+
+	t.Run("HTTPS record", func(t *testing.T) {
+		domain := "example.com"
+		record := &dns.HTTPS{SVCB: dns.SVCB{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeHTTPS,
+				Class:  dns.ClassINET,
+				Ttl:    500,
+			},
+			Priority: 1,
+			Target:   ".",
+			Value:    []dns.SVCBKeyValue{&dns.SVCBAlpn{Alpn: []string{"h2", "h3"}}},
+		}}
+
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
+		assert.Equal(t, "HTTPS\texample.com.\t08m20s\t1 . alpn=h2,h3", r)
+	})
+
+	t.Run("CNAME target record", func(t *testing.T) {
+		domain := "www.example.com"
+		record := &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "cdn.example.net.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		}
+
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
+		assert.Equal(t, "A\tcdn.example.net.\t03m42s\t127.0.0.1", r)
+	})
+
+	t.Run("NSEC record", func(t *testing.T) {
+		domain := "example.com"
+		record := &dns.NSEC{
+			Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: 300},
+			NextDomain: "www.example.com.",
+			TypeBitMap: []uint16{dns.TypeA, dns.TypeNS, dns.TypeSOA, dns.TypeRRSIG, dns.TypeNSEC},
+		}
+
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
+		assert.Equal(t, "NSEC\texample.com.\t05m00s\twww.example.com. A NS SOA RRSIG NSEC", r)
+	})
+
+	t.Run("NSEC3 record", func(t *testing.T) {
+		domain := "example.com"
+		record := &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: "9ofpb6v0m1q3htqf5gv1q7ntjhkq4lrp.example.com.", Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 300},
+			Hash:       dns.SHA1,
+			NextDomain: "k4aqjg4gmba2k8dpv0j4apvjcojj2gcp",
+			TypeBitMap: []uint16{dns.TypeA, dns.TypeRRSIG},
+		}
+
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
+		assert.Equal(t, "NSEC3\t9ofpb6v0m1q3htqf5gv1q7ntjhkq4lrp.example.com.\t05m00s\t1 0 0 - k4aqjg4gmba2k8dpv0j4apvjcojj2gcp A RRSIG", r)
+	})
+
 	t.Run("Unknown record type", func(t *testing.T) {
 		domain := "example.com"
 		record := &dns.SVCB{
@@ -247,7 +399,66 @@ func TestFormatRecord(t *testing.T) {
 			Priority: 10,
 		}
 
-		r := formatRecord(domain, record)
+		r := formatRecord(domain, record, arguments.TTLHuman, time.Now())
 		assert.Contains(t, r, "Unknown record type")
 	})
 }
+
+func TestFormatChange(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	domain := "example.com"
+	at := time.Date(2024, 12, 17, 1, 4, 6, 0, time.UTC)
+	newA := func(ip string) *dns.A {
+		return &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.ParseIP(ip),
+		}
+	}
+
+	t.Run("added record", func(t *testing.T) {
+		r := formatChange(domain, watch.Change{Type: watch.Added, New: newA("192.0.2.1")}, at, arguments.TTLHuman)
+		assert.Equal(t, "2024-12-17T01:04:06Z\t+\tA\texample.com.\t03m42s\t192.0.2.1", r)
+	})
+
+	t.Run("removed record", func(t *testing.T) {
+		r := formatChange(domain, watch.Change{Type: watch.Removed, Old: newA("192.0.2.1")}, at, arguments.TTLHuman)
+		assert.Equal(t, "2024-12-17T01:04:06Z\t-\tA\texample.com.\t03m42s\t192.0.2.1", r)
+	})
+
+	t.Run("changed record", func(t *testing.T) {
+		r := formatChange(domain, watch.Change{Type: watch.Changed, Old: newA("192.0.2.1"), New: newA("192.0.2.2")}, at, arguments.TTLHuman)
+		assert.Equal(t, "2024-12-17T01:04:06Z\t~\tA\texample.com.\t03m42s\t192.0.2.2 (was 192.0.2.1)", r)
+	})
+}
+
+func TestFormatChain(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	t.Run("chain", func(t *testing.T) {
+		chain := &query.Chain{Names: []string{"www.example.com.", "cdn.example.net.", "edge.example.org."}}
+
+		r := formatChain(chain)
+		assert.Equal(t, "CNAME chain: www.example.com. → cdn.example.net. → edge.example.org.", r)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		chain := &query.Chain{Names: []string{"a.example.com.", "b.example.com.", "a.example.com."}, Loop: true}
+
+		r := formatChain(chain)
+		assert.Equal(t, "CNAME chain: a.example.com. → b.example.com. → a.example.com.\n"+
+			"Warning: CNAME loop detected at a.example.com.", r)
+	})
+
+	t.Run("apex", func(t *testing.T) {
+		chain := &query.Chain{Names: []string{"example.com.", "example.net."}, Apex: []string{"example.com."}}
+
+		r := formatChain(chain)
+		assert.Contains(t, r, "Warning: example.com. has a CNAME next to SOA or NS records")
+	})
+}
@@ -0,0 +1,82 @@
+package view
+
+import (
+	"html/template"
+	"strings"
+	"time"
+)
+
+// htmlTemplate is the standalone document written by the HTMLRenderer.
+// html/template escapes all values, so record data cannot inject markup into the report.
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"time": func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>DNS report for {{.Domain}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td { font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>DNS report for {{.Domain}}</h1>
+<ul>
+<li><strong>Server:</strong> {{join .Servers ", "}}</li>
+<li><strong>Time:</strong> {{time .Timestamp}}</li>
+{{- range .Rcodes}}
+<li><strong>{{.Rcode}}:</strong> {{join .Types ", "}}</li>
+{{- end}}
+{{- if .Chain}}
+<li><strong>CNAME chain:</strong> {{join .Chain " → "}}</li>
+{{- end}}
+{{- range .Warnings}}
+<li><strong>Warning:</strong> {{.}}</li>
+{{- end}}
+</ul>
+{{- range .Groups}}
+<h2>{{.Name}}</h2>
+<table>
+<thead>
+<tr><th>Type</th><th>TTL</th><th>Value</th></tr>
+</thead>
+<tbody>
+{{- range .Records}}
+<tr><td>{{.Type}}</td><td>{{.TTL}}</td><td>{{.Value}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTMLRenderer for rendering a report as a standalone HTML document, to be attached to incident reports and tickets.
+// Like the MarkdownRenderer, it buffers all responses and writes the report when flushed.
+type HTMLRenderer struct {
+	*reportCollector
+	view *View
+}
+
+// Validate that HTMLRenderer implements the Renderer, Flusher, ChainRenderer and DomainRenderer interfaces.
+var _ Renderer = (*HTMLRenderer)(nil)
+var _ Flusher = (*HTMLRenderer)(nil)
+var _ ChainRenderer = (*HTMLRenderer)(nil)
+var _ DomainRenderer = (*HTMLRenderer)(nil)
+
+// NewHTMLRenderer creates an HTMLRenderer bound to an output stream.
+func NewHTMLRenderer(view *View) *HTMLRenderer {
+	return &HTMLRenderer{
+		reportCollector: newReportCollector(view.TTL),
+		view:            view,
+	}
+}
+
+// Flush writes the report to the output stream.
+func (v *HTMLRenderer) Flush() error {
+	return htmlTemplate.Execute(v.view.Stream.Writer, v.build())
+}
@@ -0,0 +1,57 @@
+package view
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+)
+
+// TestNewRenderer_HTML tests the NewRenderer function, which should return an HTMLRenderer
+// and bind provided io.Writer to the view's stream writer.
+func TestNewRenderer_HTML(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewRenderer(arguments.ViewHTML, NewView(&b))
+
+	// Check that the view is an HTMLRenderer
+	htmlRenderer, ok := r.(*HTMLRenderer)
+	assert.True(t, ok, "Expected r to be of type *HTMLRenderer")
+
+	// Check that the view's stream writer is the same as the buffer
+	assert.Equal(t, &b, htmlRenderer.view.Stream.Writer)
+}
+
+// TestHTMLRenderer_Render tests that the renderer writes a standalone document when flushed,
+// and escapes record data.
+func TestHTMLRenderer_Render(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewHTMLRenderer(NewView(&b))
+
+	domain := "example.com"
+	r.Render(domain, newResponse(
+		&dns.A{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 222},
+			A:   net.IPv4(127, 0, 0, 1),
+		},
+		&dns.TXT{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 500},
+			Txt: []string{"<script>alert(1)</script>"},
+		},
+	))
+
+	assert.Empty(t, b.String())
+	assert.NoError(t, r.Flush())
+
+	got := b.String()
+	assert.Contains(t, got, "<!DOCTYPE html>")
+	assert.Contains(t, got, "<title>DNS report for example.com</title>")
+	assert.Contains(t, got, "<li><strong>Server:</strong> 127.0.0.1:53</li>")
+	assert.Contains(t, got, "<li><strong>NOERROR:</strong> A</li>")
+	assert.Contains(t, got, "<h2>example.com.</h2>")
+	assert.Contains(t, got, "<tr><td>A</td><td>03m42s</td><td>127.0.0.1</td></tr>")
+	assert.Contains(t, got, "<tr><td>TXT</td><td>08m20s</td><td>&lt;script&gt;alert(1)&lt;/script&gt;</td></tr>")
+	assert.NotContains(t, got, "<script>")
+}
@@ -0,0 +1,103 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/mail"
+)
+
+// MailRenderer renders the report of an email audit.
+type MailRenderer interface {
+	RenderMail(report *mail.Report) error
+}
+
+// NewMailRenderer creates a MailRenderer for a view type. Only the human and JSON document views are supported.
+func NewMailRenderer(vt arguments.ViewType, view *View) (MailRenderer, error) {
+	switch vt {
+	case arguments.ViewHuman:
+		return &HumanMailRenderer{view: view}, nil
+	case arguments.ViewJSONDocument:
+		return &JSONMailRenderer{view: view}, nil
+	default:
+		return nil, fmt.Errorf("error: the %s output is not supported by the mail command", vt)
+	}
+}
+
+// HumanMailRenderer for rendering an email audit in human-readable format, one line per record
+// followed by the problems found in them.
+type HumanMailRenderer struct {
+	view *View
+}
+
+// Validate that HumanMailRenderer implements the MailRenderer interface.
+var _ MailRenderer = (*HumanMailRenderer)(nil)
+
+// RenderMail renders the report of an email audit in human-readable format to the output stream.
+func (v *HumanMailRenderer) RenderMail(report *mail.Report) error {
+	var lines []string
+	for _, c := range report.Checks {
+		name := color.HiYellowString(c.Name)
+		owner := color.HiBlueString(c.Owner)
+		if len(c.Records) == 0 {
+			lines = append(lines, fmt.Sprintf("%s\t%s\t%s", name, owner, color.HiBlackString("-")))
+		}
+		for _, record := range c.Records {
+			lines = append(lines, fmt.Sprintf("%s\t%s\t%s", name, owner, color.HiWhiteString(record)))
+		}
+		for _, p := range c.Problems {
+			lines = append(lines, fmt.Sprintf("\t\t%s", formatProblem(p)))
+		}
+	}
+
+	errors, warnings := report.Count(mail.SeverityError), report.Count(mail.SeverityWarning)
+	summary := fmt.Sprintf("%d %s, %d %s", errors, plural(errors, "error"), warnings, plural(warnings, "warning"))
+	if errors > 0 {
+		summary = color.HiRedString(summary)
+	} else if warnings > 0 {
+		summary = color.YellowString(summary)
+	} else {
+		summary = color.HiGreenString(summary)
+	}
+	lines = append(lines, "", summary)
+
+	_, err := v.view.Stream.Writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	return err
+}
+
+// formatProblem generates a human-readable string for a problem found by an audit, colored by severity.
+func formatProblem(p mail.Problem) string {
+	switch p.Severity {
+	case mail.SeverityError:
+		return color.HiRedString("error: %s", p.Message)
+	case mail.SeverityWarning:
+		return color.YellowString("warning: %s", p.Message)
+	default:
+		return color.HiBlackString("%s: %s", p.Severity, p.Message)
+	}
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// JSONMailRenderer for rendering an email audit as a single JSON document.
+type JSONMailRenderer struct {
+	view *View
+}
+
+// Validate that JSONMailRenderer implements the MailRenderer interface.
+var _ MailRenderer = (*JSONMailRenderer)(nil)
+
+// RenderMail renders the report of an email audit as an indented JSON document to the output stream.
+func (v *JSONMailRenderer) RenderMail(report *mail.Report) error {
+	enc := json.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,71 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/mail"
+)
+
+// testMailReport is a report with a problem of each severity.
+var testMailReport = &mail.Report{
+	Domain: "example.com.",
+	Checks: []mail.Check{
+		{Name: "SPF", Owner: "example.com.", Records: []string{"v=spf1 +all"}, Problems: []mail.Problem{
+			{Severity: mail.SeverityError, Message: "+all allows any server to send email for the domain"},
+		}},
+		{Name: "DMARC", Owner: "_dmarc.example.com.", Records: []string{"v=DMARC1; p=none"}, Problems: []mail.Problem{
+			{Severity: mail.SeverityWarning, Message: "p=none only monitors, email failing authentication is still delivered"},
+		}},
+		{Name: "BIMI", Owner: "default._bimi.example.com.", Records: []string{}, Problems: []mail.Problem{
+			{Severity: mail.SeverityInfo, Message: "no BIMI record"},
+		}},
+	},
+}
+
+// TestNewMailRenderer tests that NewMailRenderer returns a renderer for the human and JSON views only.
+func TestNewMailRenderer(t *testing.T) {
+	b := bytes.Buffer{}
+
+	r, err := NewMailRenderer(arguments.ViewHuman, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &HumanMailRenderer{}, r)
+
+	r, err = NewMailRenderer(arguments.ViewJSONDocument, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &JSONMailRenderer{}, r)
+
+	_, err = NewMailRenderer(arguments.ViewZone, NewView(&b))
+	assert.EqualError(t, err, "error: the zone output is not supported by the mail command")
+}
+
+// TestHumanMailRenderer_RenderMail tests that each record is followed by its problems, and the report by a summary.
+func TestHumanMailRenderer_RenderMail(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanMailRenderer{view: NewView(&b)}
+
+	assert.NoError(t, r.RenderMail(testMailReport))
+
+	want := "SPF\texample.com.\tv=spf1 +all\n" +
+		"\t\terror: +all allows any server to send email for the domain\n" +
+		"DMARC\t_dmarc.example.com.\tv=DMARC1; p=none\n" +
+		"\t\twarning: p=none only monitors, email failing authentication is still delivered\n" +
+		"BIMI\tdefault._bimi.example.com.\t-\n" +
+		"\t\tinfo: no BIMI record\n" +
+		"\n" +
+		"1 error, 1 warning\n"
+	assert.Equal(t, want, b.String())
+}
+
+// TestJSONMailRenderer_RenderMail tests that the report is written as an indented JSON document.
+func TestJSONMailRenderer_RenderMail(t *testing.T) {
+	b := bytes.Buffer{}
+	r := &JSONMailRenderer{view: NewView(&b)}
+
+	assert.NoError(t, r.RenderMail(&mail.Report{Domain: "example.com.", Checks: []mail.Check{}}))
+	assert.Equal(t, "{\n  \"domain\": \"example.com.\",\n  \"checks\": []\n}\n", b.String())
+}
@@ -0,0 +1,74 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MarkdownRenderer for rendering a report in GitHub-flavored Markdown, to be pasted into incident reports and tickets.
+// It buffers all responses and writes the report when flushed: a summary of the run, followed by a table per owner name.
+type MarkdownRenderer struct {
+	*reportCollector
+	view *View
+}
+
+// Validate that MarkdownRenderer implements the Renderer, Flusher, ChainRenderer and DomainRenderer interfaces.
+var _ Renderer = (*MarkdownRenderer)(nil)
+var _ Flusher = (*MarkdownRenderer)(nil)
+var _ ChainRenderer = (*MarkdownRenderer)(nil)
+var _ DomainRenderer = (*MarkdownRenderer)(nil)
+
+// NewMarkdownRenderer creates a MarkdownRenderer bound to an output stream.
+func NewMarkdownRenderer(view *View) *MarkdownRenderer {
+	return &MarkdownRenderer{
+		reportCollector: newReportCollector(view.TTL),
+		view:            view,
+	}
+}
+
+// Flush writes the report to the output stream.
+func (v *MarkdownRenderer) Flush() error {
+	r := v.build()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# DNS report for %s\n\n", markdownEscape(r.Domain))
+	fmt.Fprintf(&b, "- **Server:** %s\n", markdownEscape(strings.Join(r.Servers, ", ")))
+	fmt.Fprintf(&b, "- **Time:** %s\n", r.Timestamp.Format(time.RFC3339))
+	for _, rcode := range r.Rcodes {
+		fmt.Fprintf(&b, "- **%s:** %s\n", rcode.Rcode, strings.Join(rcode.Types, ", "))
+	}
+	if len(r.Chain) > 0 {
+		fmt.Fprintf(&b, "- **CNAME chain:** %s\n", markdownEscape(strings.Join(r.Chain, " → ")))
+	}
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(&b, "- **Warning:** %s\n", markdownEscape(warning))
+	}
+
+	for _, g := range r.Groups {
+		fmt.Fprintf(&b, "\n## %s\n\n", markdownEscape(g.Name))
+		b.WriteString("| Type | TTL | Value |\n")
+		b.WriteString("|------|-----|-------|\n")
+		for _, record := range g.Records {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", record.Type, record.TTL, markdownEscape(record.Value))
+		}
+	}
+
+	_, err := v.view.Stream.Writer.Write([]byte(b.String()))
+	return err
+}
+
+// markdownEscaper escapes the characters that would break out of a table cell or start inline formatting.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,88 @@
+package view
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/query"
+)
+
+// TestNewRenderer_Markdown tests the NewRenderer function, which should return a MarkdownRenderer
+// and bind provided io.Writer to the view's stream writer.
+func TestNewRenderer_Markdown(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewRenderer(arguments.ViewMarkdown, NewView(&b))
+
+	// Check that the view is a MarkdownRenderer
+	markdownRenderer, ok := r.(*MarkdownRenderer)
+	assert.True(t, ok, "Expected r to be of type *MarkdownRenderer")
+
+	// Check that the view's stream writer is the same as the buffer
+	assert.Equal(t, &b, markdownRenderer.view.Stream.Writer)
+}
+
+// TestMarkdownRenderer_Render tests that the renderer writes nothing until flushed, and then writes
+// a summary followed by a table per owner name, with the records sorted by type.
+func TestMarkdownRenderer_Render(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewMarkdownRenderer(NewView(&b))
+	r.report.Timestamp = time.Date(2024, 12, 17, 0, 4, 6, 0, time.UTC)
+
+	domain := "www.example.com"
+	cname := &dns.CNAME{
+		Hdr:    dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 300},
+		Target: "cdn.example.net.",
+	}
+
+	txt := newResponse(cname, &dns.TXT{
+		Hdr: dns.RR_Header{Name: "cdn.example.net.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 300},
+		Txt: []string{"a|b"},
+	})
+	txt.Question[0].Qtype = dns.TypeTXT
+	r.Render(domain, txt)
+	r.Render(domain, newResponse(cname, &dns.A{
+		Hdr: dns.RR_Header{Name: "cdn.example.net.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   net.IPv4(192, 0, 2, 1),
+	}))
+	nx := newResponse()
+	nx.Question[0].Qtype = dns.TypeMX
+	nx.Rcode = dns.RcodeNameError
+	r.Render(domain, nx)
+	r.RenderChain(domain, &query.Chain{Names: []string{"www.example.com.", "cdn.example.net."}})
+
+	assert.Empty(t, b.String())
+	assert.NoError(t, r.Flush())
+
+	want := "# DNS report for www.example.com\n" +
+		"\n" +
+		"- **Server:** 127.0.0.1:53\n" +
+		"- **Time:** 2024-12-17T00:04:06Z\n" +
+		"- **NOERROR:** TXT, A\n" +
+		"- **NXDOMAIN:** MX\n" +
+		"- **CNAME chain:** www.example.com. → cdn.example.net.\n" +
+		"\n" +
+		"## www.example.com.\n" +
+		"\n" +
+		"| Type | TTL | Value |\n" +
+		"|------|-----|-------|\n" +
+		"| CNAME | 05m00s | cdn.example.net. |\n" +
+		"\n" +
+		"## cdn.example.net.\n" +
+		"\n" +
+		"| Type | TTL | Value |\n" +
+		"|------|-----|-------|\n" +
+		"| A | 01m00s | 192.0.2.1 |\n" +
+		"| TXT | 05m00s | a\\|b |\n"
+
+	assert.Equal(t, want, b.String())
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	assert.Equal(t, `v=DKIM1; p=a\_b\*c\|d`, markdownEscape("v=DKIM1; p=a_b*c|d"))
+	assert.Equal(t, "&lt;script&gt;", markdownEscape("<script>"))
+}
@@ -0,0 +1,120 @@
+package view
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// The kinds of objects written by the NDJSONRenderer.
+const (
+	NDJSONKindRecord = "record"
+	NDJSONKindError  = "error"
+	NDJSONKindChain  = "cname_chain"
+)
+
+// NDJSONRecord is a single record, written as one line of NDJSON output.
+// It uses the field names of the JSON document, flattened so that every line is self-contained.
+type NDJSONRecord struct {
+	SchemaVersion int      `json:"schema_version"`
+	Kind          string   `json:"kind"`
+	Domain        string   `json:"domain"`
+	QueryName     string   `json:"qname"`
+	QueryType     string   `json:"qtype"`
+	Server        string   `json:"server"`
+	Transport     string   `json:"transport"`
+	RTT           float64  `json:"rtt_ms"`
+	Size          int      `json:"size"`
+	Rcode         string   `json:"rcode"`
+	Flags         []string `json:"flags"`
+	DocumentRecord
+}
+
+// NDJSONError is a failed query, written as one line of NDJSON output.
+type NDJSONError struct {
+	SchemaVersion int    `json:"schema_version"`
+	Kind          string `json:"kind"`
+	Domain        string `json:"domain"`
+	QueryName     string `json:"qname"`
+	QueryType     string `json:"qtype"`
+	Error         string `json:"error"`
+}
+
+// NDJSONChain is the CNAME chain of the queried domain, written as one line of NDJSON output.
+type NDJSONChain struct {
+	SchemaVersion int    `json:"schema_version"`
+	Kind          string `json:"kind"`
+	Domain        string `json:"domain"`
+	DocumentChain
+}
+
+// NDJSONRenderer for streaming newline-delimited JSON, one object per record and one per failed query.
+// Objects are written as soon as they are rendered, so the output can be consumed while zns is still querying.
+type NDJSONRenderer struct {
+	view *View
+	enc  *json.Encoder
+}
+
+// Validate that NDJSONRenderer implements the Renderer, ErrorRenderer and ChainRenderer interfaces.
+var _ Renderer = (*NDJSONRenderer)(nil)
+var _ ErrorRenderer = (*NDJSONRenderer)(nil)
+var _ ChainRenderer = (*NDJSONRenderer)(nil)
+
+// NewNDJSONRenderer creates an NDJSONRenderer bound to an output stream.
+func NewNDJSONRenderer(view *View) *NDJSONRenderer {
+	return &NDJSONRenderer{
+		view: view,
+		enc:  json.NewEncoder(view.Stream.Writer),
+	}
+}
+
+// Render writes one object per answer of a DNS response to the output stream.
+func (v *NDJSONRenderer) Render(domain string, resp *query.Response) {
+	for _, record := range resp.Answer {
+		v.write(NDJSONRecord{
+			SchemaVersion:  DocumentSchemaVersion,
+			Kind:           NDJSONKindRecord,
+			Domain:         domain,
+			QueryName:      resp.Question[0].Name,
+			QueryType:      dns.TypeToString[resp.Question[0].Qtype],
+			Server:         resp.Server,
+			Transport:      resp.Transport,
+			RTT:            float64(resp.RTT) / float64(time.Millisecond),
+			Size:           resp.Size,
+			Rcode:          dns.RcodeToString[resp.Rcode],
+			Flags:          headerFlags(resp.Msg),
+			DocumentRecord: newDocumentRecord(resp, record),
+		})
+	}
+}
+
+// RenderError writes an object describing a failed query to the output stream.
+func (v *NDJSONRenderer) RenderError(domain string, qtype uint16, err error) {
+	v.write(NDJSONError{
+		SchemaVersion: DocumentSchemaVersion,
+		Kind:          NDJSONKindError,
+		Domain:        domain,
+		QueryName:     dns.Fqdn(domain),
+		QueryType:     dns.TypeToString[qtype],
+		Error:         err.Error(),
+	})
+}
+
+// RenderChain writes an object describing the CNAME chain of a domain to the output stream.
+func (v *NDJSONRenderer) RenderChain(domain string, chain *query.Chain) {
+	v.write(NDJSONChain{
+		SchemaVersion: DocumentSchemaVersion,
+		Kind:          NDJSONKindChain,
+		Domain:        domain,
+		DocumentChain: newDocumentChain(chain),
+	})
+}
+
+// write encodes a single object on its own line.
+func (v *NDJSONRenderer) write(obj any) {
+	if err := v.enc.Encode(obj); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,102 @@
+package view
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+)
+
+// TestNewRenderer_NDJSON tests the NewRenderer function, which should return an NDJSONRenderer
+// and bind provided io.Writer to the view's stream writer.
+func TestNewRenderer_NDJSON(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewRenderer(arguments.ViewNDJSON, NewView(&b))
+
+	// Check that the view is an NDJSONRenderer
+	ndjsonRenderer, ok := r.(*NDJSONRenderer)
+	assert.True(t, ok, "Expected r to be of type *NDJSONRenderer")
+
+	// Check that the view's stream writer is the same as the buffer
+	assert.Equal(t, &b, ndjsonRenderer.view.Stream.Writer)
+}
+
+// TestNDJSONRenderer_Render tests that every record and every error is written
+// as soon as it is rendered, one JSON object per line.
+func TestNDJSONRenderer_Render(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewNDJSONRenderer(NewView(&b))
+
+	domain := "example.com"
+	records := []dns.RR{
+		&dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		},
+		&dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 2),
+		},
+	}
+
+	r.Render(domain, newResponse(records...))
+	assert.Equal(t, 2, strings.Count(b.String(), "\n"))
+
+	r.RenderError(domain, dns.TypeMX, fmt.Errorf("it's always DNS"))
+	assert.Equal(t, 3, strings.Count(b.String(), "\n"))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]any{
+		"schema_version": float64(DocumentSchemaVersion),
+		"kind":           "record",
+		"domain":         "example.com",
+		"qname":          "example.com.",
+		"qtype":          "A",
+		"server":         "127.0.0.1:53",
+		"transport":      "UDP",
+		"rtt_ms":         1.5,
+		"size":           float64(45),
+		"rcode":          "NOERROR",
+		"flags":          []any{"qr", "rd"},
+		"name":           "example.com.",
+		"type":           "A",
+		"class":          "IN",
+		"ttl":            float64(222),
+		"value":          "127.0.0.2",
+		"rdata":          map[string]any{"address": "127.0.0.2"},
+	}, record)
+
+	var failure map[string]any
+	if err := json.Unmarshal([]byte(lines[2]), &failure); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]any{
+		"schema_version": float64(DocumentSchemaVersion),
+		"kind":           "error",
+		"domain":         "example.com",
+		"qname":          "example.com.",
+		"qtype":          "MX",
+		"error":          "it's always DNS",
+	}, failure)
+}
@@ -1,74 +1,238 @@
 package view
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
 	"github.com/miekg/dns"
 	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/query"
+	"github.com/znscli/zns/internal/watch"
 )
 
 // Renderer interface with a unified Render method.
 type Renderer interface {
-	Render(domain string, record dns.RR)
+	// Render renders the answers of a DNS response for the given domain.
+	Render(domain string, resp *query.Response)
+}
+
+// Flusher is implemented by renderers that buffer their output until all responses are rendered.
+type Flusher interface {
+	Flush() error
+}
+
+// ErrorRenderer is implemented by renderers that stream their output and report failed queries
+// inline, rather than leaving it to the caller to abort the run.
+type ErrorRenderer interface {
+	RenderError(domain string, qtype uint16, err error)
+}
+
+// ChangeRenderer is implemented by renderers that can display the changes detected in watch mode.
+type ChangeRenderer interface {
+	RenderChange(domain string, change watch.Change, at time.Time)
+}
+
+// ChainRenderer is implemented by renderers that can display the CNAME chain a domain resolved through.
+type ChainRenderer interface {
+	RenderChain(domain string, chain *query.Chain)
+}
+
+// GroupRenderer is implemented by renderers that can display a heading before each group of records,
+// when records are grouped by type or by domain.
+type GroupRenderer interface {
+	RenderGroup(domain string, key string)
+}
+
+// DomainRenderer is implemented by renderers that describe a whole run under a single domain. SetDomain sets
+// that domain up front, for runs whose responses are rendered for other names, such as the names found in a zone.
+// Otherwise, the domain of the first rendered response is used.
+type DomainRenderer interface {
+	SetDomain(domain string)
 }
 
 func NewRenderer(vt arguments.ViewType, view *View) Renderer {
 	switch vt {
 	case arguments.ViewHuman:
-		return &HumanRenderer{view}
+		return NewHumanRenderer(view)
 	case arguments.ViewJSON:
-		return &JSONRenderer{NewJSONView(view)}
+		return NewJSONRenderer(NewJSONView(view))
+	case arguments.ViewJSONDocument:
+		return NewJSONDocumentRenderer(view)
+	case arguments.ViewNDJSON:
+		return NewNDJSONRenderer(view)
+	case arguments.ViewZone:
+		return NewZoneRenderer(view)
+	case arguments.ViewCSV:
+		return NewCSVRenderer(view, ',')
+	case arguments.ViewTSV:
+		return NewCSVRenderer(view, '\t')
+	case arguments.ViewYAML:
+		return NewYAMLRenderer(view)
+	case arguments.ViewDig:
+		return NewDigRenderer(view)
+	case arguments.ViewMarkdown:
+		return NewMarkdownRenderer(view)
+	case arguments.ViewHTML:
+		return NewHTMLRenderer(view)
 	default:
 		panic("unknown view type")
 	}
 }
 
+// recordSet tracks which records were rendered already.
+// An alias returns the same CNAME records for every query type, and they should only be rendered once.
+type recordSet map[string]bool
+
+// filter returns the records that are not in the set yet, and adds them to it.
+func (s recordSet) filter(records []dns.RR) []dns.RR {
+	var out []dns.RR
+	for _, record := range records {
+		if !s[record.String()] {
+			s[record.String()] = true
+			out = append(out, record)
+		}
+	}
+	return out
+}
+
 // HumanRenderer for writing human-readable output.
 type HumanRenderer struct {
-	view *View
+	view     *View
+	rendered recordSet
+	grouped  bool
 }
 
-// Validate that HumanRenderer implements the Renderer interface.
+// Validate that HumanRenderer implements the Renderer, ChangeRenderer, ChainRenderer and GroupRenderer interfaces.
 var _ Renderer = (*HumanRenderer)(nil)
+var _ ChangeRenderer = (*HumanRenderer)(nil)
+var _ ChainRenderer = (*HumanRenderer)(nil)
+var _ GroupRenderer = (*HumanRenderer)(nil)
 
 // NewHumanRenderer creates a HumanRenderer with a "human" view bound to an output stream.
 func NewHumanRenderer(view *View) *HumanRenderer {
 	return &HumanRenderer{
-		view: view,
+		view:     view,
+		rendered: make(recordSet),
 	}
 }
 
-// Render renders a DNS record in human-readable format to the output stream.
-func (v *HumanRenderer) Render(domain string, record dns.RR) {
-	humanReadable := formatRecord(domain, record)
+// Render renders the answers of a DNS response in human-readable format to the output stream, one record per line.
+func (v *HumanRenderer) Render(domain string, resp *query.Response) {
+	now := time.Now()
+	for _, record := range v.rendered.filter(resp.Answer) {
+		humanReadable := formatRecord(domain, record, v.view.TTL, now)
+		if resp.IsWildcard(record) {
+			humanReadable += " " + color.HiRedString("*")
+		}
+		if v.view.Stats {
+			humanReadable += "\t" + formatStats(resp)
+		}
+		_, err := v.view.Stream.Writer.Write([]byte(humanReadable + "\n"))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RenderChange renders a change detected in watch mode in human-readable format to the output stream.
+func (v *HumanRenderer) RenderChange(domain string, change watch.Change, at time.Time) {
+	humanReadable := formatChange(domain, change, at, v.view.TTL)
 	_, err := v.view.Stream.Writer.Write([]byte(humanReadable + "\n"))
 	if err != nil {
 		panic(err)
 	}
 }
 
+// RenderChain renders the CNAME chain of a domain in human-readable format to the output stream.
+func (v *HumanRenderer) RenderChain(domain string, chain *query.Chain) {
+	humanReadable := formatChain(chain)
+	_, err := v.view.Stream.Writer.Write([]byte(humanReadable + "\n"))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RenderGroup renders a heading for a group of records to the output stream.
+// Groups after the first are separated by an empty line.
+func (v *HumanRenderer) RenderGroup(domain string, key string) {
+	heading := formatGroup(key)
+	if v.grouped {
+		heading = "\n" + heading
+	}
+	v.grouped = true
+
+	_, err := v.view.Stream.Writer.Write([]byte(heading + "\n"))
+	if err != nil {
+		panic(err)
+	}
+}
+
 // JSONRenderer for rendering JSON output.
 type JSONRenderer struct {
-	view *JSONView
+	view     *JSONView
+	rendered recordSet
 }
 
-// Validate that JSONRenderer implements the Renderer interface.
+// Validate that JSONRenderer implements the Renderer, ChangeRenderer and ChainRenderer interfaces.
 var _ Renderer = (*JSONRenderer)(nil)
+var _ ChangeRenderer = (*JSONRenderer)(nil)
+var _ ChainRenderer = (*JSONRenderer)(nil)
 
 // NewJSONRenderer creates a JSONRenderer with a JSONView bound to an output stream.
 func NewJSONRenderer(view *JSONView) *JSONRenderer {
 	return &JSONRenderer{
-		view: view,
+		view:     view,
+		rendered: make(recordSet),
+	}
+}
+
+// Render renders the answers of a DNS response in JSON format to the output stream, one log message per record.
+func (v *JSONRenderer) Render(domain string, resp *query.Response) {
+	now := time.Now()
+	for _, record := range v.rendered.filter(resp.Answer) {
+		jsonMap := formatRecordAsJSON(domain, record, v.view.TTL, now)
+		if resp.IsWildcard(record) {
+			jsonMap["@wildcard"] = true
+		}
+		for key, value := range formatStatsAsJSON(resp) {
+			jsonMap[key] = value
+		}
+
+		var params []any
+		for key, value := range jsonMap {
+			// Append each key-value pair as separate parameters.
+			params = append(params, key, value)
+		}
+
+		v.view.Output("Successful query", params...)
 	}
 }
 
-// Render renders a DNS record in JSON format to the output stream.
-func (v *JSONRenderer) Render(domain string, record dns.RR) {
-	jsonMap := formatRecordAsJSON(domain, record)
+// RenderChange renders a change detected in watch mode in JSON format to the output stream.
+// The log message's timestamp records when the change was detected.
+func (v *JSONRenderer) RenderChange(domain string, change watch.Change, at time.Time) {
+	jsonMap := formatRecordAsJSON(domain, change.Record(), v.view.TTL, at)
+	jsonMap["@change"] = change.Type.String()
+	if change.Type == watch.Changed {
+		jsonMap["@previous"] = formatRecordAsJSON(domain, change.Old, v.view.TTL, at)["@record"]
+	}
 
 	var params []any
 	for key, value := range jsonMap {
-		// Append each key-value pair as separate parameters.
 		params = append(params, key, value)
 	}
 
-	v.view.Output("Successful query", params...)
+	v.view.Output(fmt.Sprintf("Record %s", change.Type), params...)
+}
+
+// RenderChain renders the CNAME chain of a domain in JSON format to the output stream.
+func (v *JSONRenderer) RenderChain(domain string, chain *query.Chain) {
+	v.view.Output("CNAME chain",
+		"@domain", domain,
+		"@chain", chain.Names,
+		"@loop", chain.Loop,
+		"@truncated", chain.Truncated,
+		"@apex", chain.Apex,
+	)
 }
@@ -4,13 +4,31 @@ import (
 	"bytes"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/query"
 	znsversion "github.com/znscli/zns/version"
 )
 
+// newResponse wraps records in a DNS response for the A record of example.com, as returned by the query client.
+func newResponse(records ...dns.RR) *query.Response {
+	msg := new(dns.Msg)
+	msg.SetQuestion("example.com.", dns.TypeA)
+	msg.Response = true
+	msg.Answer = records
+
+	return &query.Response{
+		Msg:       msg,
+		Server:    "127.0.0.1:53",
+		RTT:       1500 * time.Microsecond,
+		Size:      45,
+		Transport: "UDP",
+	}
+}
+
 // TestNewRenderer_human tests the NewRenderer function, which should return a HumanRenderer
 // and bind provided io.Writer to the view's stream writer.
 func TestNewRenderer_human(t *testing.T) {
@@ -59,7 +77,7 @@ func TestNewHumanRenderer_Render(t *testing.T) {
 			A: net.IPv4(127, 0, 0, 1),
 		}
 
-		hr.Render(domain, record)
+		hr.Render(domain, newResponse(record))
 
 		want := "A\texample.com.\t03m42s\t127.0.0.1\n"
 
@@ -96,7 +114,7 @@ func TestNewHumanRenderer_Render(t *testing.T) {
 		}
 
 		for _, record := range records {
-			hr.Render(domain, record)
+			hr.Render(domain, newResponse(record))
 		}
 
 		want := "A\texample.com.\t03m42s\t127.0.0.1\n" +
@@ -104,6 +122,135 @@ func TestNewHumanRenderer_Render(t *testing.T) {
 
 		assert.Equal(t, want, b.String())
 	})
+
+	t.Run("duplicate records", func(t *testing.T) {
+		b := bytes.Buffer{}
+		v := NewView(&b)
+		hr := NewHumanRenderer(v)
+
+		t.Setenv("NO_COLOR", "1")
+
+		domain := "www.example.com"
+		cname := &dns.CNAME{
+			Hdr: dns.RR_Header{
+				Name:   "www.example.com.",
+				Rrtype: dns.TypeCNAME,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			Target: "example.com.",
+		}
+		a := &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		}
+
+		// Both the A and the CNAME query return the CNAME record of the alias.
+		hr.Render(domain, newResponse(cname, a))
+		hr.Render(domain, newResponse(cname))
+
+		want := "CNAME\twww.example.com.\t03m42s\texample.com.\n" +
+			"A\texample.com.\t03m42s\t127.0.0.1\n"
+
+		assert.Equal(t, want, b.String())
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		b := bytes.Buffer{}
+		v := NewView(&b)
+		hr := NewHumanRenderer(v)
+
+		t.Setenv("NO_COLOR", "1")
+
+		domain := "example.com"
+		a := &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		}
+		aaaa := &dns.AAAA{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeAAAA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			AAAA: net.ParseIP("2001:db8::1"),
+		}
+
+		hr.RenderGroup(domain, "A")
+		hr.Render(domain, newResponse(a))
+		hr.RenderGroup(domain, "AAAA")
+		hr.Render(domain, newResponse(aaaa))
+
+		want := "A\n" +
+			"A\texample.com.\t03m42s\t127.0.0.1\n" +
+			"\n" +
+			"AAAA\n" +
+			"AAAA\texample.com.\t03m42s\t2001:db8::1\n"
+
+		assert.Equal(t, want, b.String())
+	})
+	t.Run("wildcard record", func(t *testing.T) {
+		b := bytes.Buffer{}
+		v := NewView(&b)
+		hr := NewHumanRenderer(v)
+
+		t.Setenv("NO_COLOR", "1")
+
+		domain := "www.example.com"
+		record := &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "www.example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		}
+		resp := newResponse(record)
+		resp.Wildcards = map[string]bool{record.String(): true}
+
+		hr.Render(domain, resp)
+
+		want := "A\twww.example.com.\t03m42s\t127.0.0.1 *\n"
+
+		assert.Equal(t, want, b.String())
+	})
+	t.Run("stats", func(t *testing.T) {
+		b := bytes.Buffer{}
+		v := NewView(&b)
+		v.Stats = true
+		hr := NewHumanRenderer(v)
+
+		t.Setenv("NO_COLOR", "1")
+
+		domain := "example.com"
+		record := &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		}
+
+		hr.Render(domain, newResponse(record))
+
+		want := "A\texample.com.\t03m42s\t127.0.0.1\t127.0.0.1:53/UDP 1.5ms 45B NOERROR qr rd\n"
+
+		assert.Equal(t, want, b.String())
+	})
 }
 
 // TestNewRenderer_JSON tests the NewRenderer function, which should return a JSONRenderer
@@ -153,18 +300,24 @@ func TestNewJSONRenderer_Render(t *testing.T) {
 			A: net.IPv4(127, 0, 0, 1),
 		}
 
-		jr.Render(domain, record)
+		jr.Render(domain, newResponse(record))
 
 		want := []map[string]interface{}{
 			{
-				"@domain":  domain,
-				"@level":   "info",
-				"@message": "Successful query",
-				"@record":  "127.0.0.1",
-				"@type":    "A",
-				"@ttl":     "03m42s",
-				"@version": znsversion.Version,
-				"@view":    "json",
+				"@domain":    domain,
+				"@level":     "info",
+				"@message":   "Successful query",
+				"@record":    "127.0.0.1",
+				"@type":      "A",
+				"@ttl":       "03m42s",
+				"@version":   znsversion.Version,
+				"@view":      "json",
+				"@server":    "127.0.0.1:53",
+				"@transport": "UDP",
+				"@rtt_ms":    1.5,
+				"@size":      float64(45),
+				"@rcode":     "NOERROR",
+				"@flags":     []any{"qr", "rd"},
 			},
 		}
 
@@ -200,29 +353,85 @@ func TestNewJSONRenderer_Render(t *testing.T) {
 		}
 
 		for _, record := range records {
-			jr.Render(domain, record)
+			jr.Render(domain, newResponse(record))
 		}
 
 		want := []map[string]interface{}{
 			{
-				"@domain":  domain,
-				"@level":   "info",
-				"@message": "Successful query",
-				"@record":  "127.0.0.1",
-				"@type":    "A",
-				"@ttl":     "03m42s",
-				"@version": znsversion.Version,
-				"@view":    "json",
+				"@domain":    domain,
+				"@level":     "info",
+				"@message":   "Successful query",
+				"@record":    "127.0.0.1",
+				"@type":      "A",
+				"@ttl":       "03m42s",
+				"@version":   znsversion.Version,
+				"@view":      "json",
+				"@server":    "127.0.0.1:53",
+				"@transport": "UDP",
+				"@rtt_ms":    1.5,
+				"@size":      float64(45),
+				"@rcode":     "NOERROR",
+				"@flags":     []any{"qr", "rd"},
+			},
+			{
+				"@domain":    domain,
+				"@level":     "info",
+				"@message":   "Successful query",
+				"@record":    "2001:db8::1",
+				"@type":      "AAAA",
+				"@ttl":       "03m42s",
+				"@version":   znsversion.Version,
+				"@view":      "json",
+				"@server":    "127.0.0.1:53",
+				"@transport": "UDP",
+				"@rtt_ms":    1.5,
+				"@size":      float64(45),
+				"@rcode":     "NOERROR",
+				"@flags":     []any{"qr", "rd"},
 			},
+		}
+
+		testJSONViewOutputEqualsFull(t, b.String(), want)
+	})
+
+	t.Run("wildcard record", func(t *testing.T) {
+
+		b := bytes.Buffer{}
+		jv := NewJSONView(NewView(&b))
+		jr := NewJSONRenderer(jv)
+
+		domain := "www.example.com"
+		record := &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "www.example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		}
+		resp := newResponse(record)
+		resp.Wildcards = map[string]bool{record.String(): true}
+
+		jr.Render(domain, resp)
+
+		want := []map[string]interface{}{
 			{
-				"@domain":  domain,
-				"@level":   "info",
-				"@message": "Successful query",
-				"@record":  "2001:db8::1",
-				"@type":    "AAAA",
-				"@ttl":     "03m42s",
-				"@version": znsversion.Version,
-				"@view":    "json",
+				"@domain":    domain,
+				"@level":     "info",
+				"@message":   "Successful query",
+				"@record":    "127.0.0.1",
+				"@type":      "A",
+				"@ttl":       "03m42s",
+				"@version":   znsversion.Version,
+				"@view":      "json",
+				"@wildcard":  true,
+				"@server":    "127.0.0.1:53",
+				"@transport": "UDP",
+				"@rtt_ms":    1.5,
+				"@size":      float64(45),
+				"@rcode":     "NOERROR",
+				"@flags":     []any{"qr", "rd"},
 			},
 		}
 
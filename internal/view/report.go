@@ -0,0 +1,125 @@
+package view
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/query"
+)
+
+// report is the model shared by the Markdown and HTML renderers: a summary of the run,
+// followed by the records grouped by owner name and sorted by type.
+type report struct {
+	Domain    string
+	Servers   []string
+	Timestamp time.Time
+	Rcodes    []reportRcode
+	Groups    []reportGroup
+	Chain     []string
+	Warnings  []string
+}
+
+// reportRcode lists the query types that were answered with a response code.
+type reportRcode struct {
+	Rcode string
+	Types []string
+}
+
+// reportGroup holds the records of a single owner name.
+type reportGroup struct {
+	Name    string
+	Records []reportRecord
+}
+
+// reportRecord holds a single row of a report table.
+type reportRecord struct {
+	Type  string
+	TTL   string
+	Value string
+}
+
+// reportCollector buffers the responses of a run into a report.
+type reportCollector struct {
+	report   report
+	ttl      arguments.TTLFormat
+	rendered recordSet
+}
+
+func newReportCollector(ttl arguments.TTLFormat) *reportCollector {
+	return &reportCollector{
+		report:   report{Timestamp: time.Now().UTC()},
+		ttl:      ttl,
+		rendered: make(recordSet),
+	}
+}
+
+// Render adds the answers of a DNS response to the report.
+func (c *reportCollector) Render(domain string, resp *query.Response) {
+	if c.report.Domain == "" {
+		c.report.Domain = domain
+	}
+
+	if !slices.Contains(c.report.Servers, resp.Server) {
+		c.report.Servers = append(c.report.Servers, resp.Server)
+	}
+	c.addRcode(dns.RcodeToString[resp.Rcode], dns.TypeToString[resp.Question[0].Qtype])
+
+	now := time.Now()
+	for _, record := range c.rendered.filter(resp.Answer) {
+		g := c.group(record.Header().Name)
+		g.Records = append(g.Records, reportRecord{
+			Type:  dns.TypeToString[record.Header().Rrtype],
+			TTL:   formatTTLAs(c.ttl, record.Header().Ttl, now),
+			Value: query.Value(record),
+		})
+	}
+}
+
+// SetDomain sets the domain of the report.
+func (c *reportCollector) SetDomain(domain string) {
+	c.report.Domain = domain
+}
+
+// RenderChain adds the CNAME chain of a domain to the report summary.
+func (c *reportCollector) RenderChain(domain string, chain *query.Chain) {
+	c.report.Chain = chain.Names
+	c.report.Warnings = chainWarnings(chain)
+}
+
+// build returns the report, with the records of each group sorted by type.
+// Groups keep the order in which their names were first answered, so the queried domain comes first.
+func (c *reportCollector) build() report {
+	for _, g := range c.report.Groups {
+		sort.SliceStable(g.Records, func(i, j int) bool {
+			return g.Records[i].Type < g.Records[j].Type
+		})
+	}
+	return c.report
+}
+
+// group returns the group of an owner name, adding it to the report if necessary.
+func (c *reportCollector) group(name string) *reportGroup {
+	for i := range c.report.Groups {
+		if c.report.Groups[i].Name == name {
+			return &c.report.Groups[i]
+		}
+	}
+	c.report.Groups = append(c.report.Groups, reportGroup{Name: name})
+	return &c.report.Groups[len(c.report.Groups)-1]
+}
+
+// addRcode records that a query type was answered with a response code.
+func (c *reportCollector) addRcode(rcode, qtype string) {
+	for i := range c.report.Rcodes {
+		if c.report.Rcodes[i].Rcode == rcode {
+			if !slices.Contains(c.report.Rcodes[i].Types, qtype) {
+				c.report.Rcodes[i].Types = append(c.report.Rcodes[i].Types, qtype)
+			}
+			return
+		}
+	}
+	c.report.Rcodes = append(c.report.Rcodes, reportRcode{Rcode: rcode, Types: []string{qtype}})
+}
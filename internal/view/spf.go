@@ -0,0 +1,134 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/mail"
+)
+
+// SPFRenderer renders an expanded SPF record.
+type SPFRenderer interface {
+	RenderSPF(report *mail.SPFReport) error
+}
+
+// NewSPFRenderer creates an SPFRenderer for a view type. Only the human and JSON document views are supported.
+func NewSPFRenderer(vt arguments.ViewType, view *View) (SPFRenderer, error) {
+	switch vt {
+	case arguments.ViewHuman:
+		return &HumanSPFRenderer{view: view}, nil
+	case arguments.ViewJSONDocument:
+		return &JSONSPFRenderer{view: view}, nil
+	default:
+		return nil, fmt.Errorf("error: the %s output is not supported by the spf command", vt)
+	}
+}
+
+// HumanSPFRenderer for rendering an expanded SPF record as a tree, followed by the number of DNS lookups it needs.
+type HumanSPFRenderer struct {
+	view *View
+}
+
+// Validate that HumanSPFRenderer implements the SPFRenderer interface.
+var _ SPFRenderer = (*HumanSPFRenderer)(nil)
+
+// RenderSPF renders an expanded SPF record in human-readable format to the output stream.
+func (v *HumanSPFRenderer) RenderSPF(report *mail.SPFReport) error {
+	lines := formatSPFRecord(report.Record, "")
+
+	lookups := fmt.Sprintf("%d DNS lookups, the limit is %d", report.Lookups, report.LookupLimit)
+	if report.Lookups > report.LookupLimit {
+		lookups = color.HiRedString("%d DNS lookups, more than the limit of %d", report.Lookups, report.LookupLimit)
+	}
+	lines = append(lines, "", lookups)
+
+	if e := report.Evaluation; e != nil {
+		result := fmt.Sprintf("%s: %s", e.IP, formatSPFResult(e.Result))
+		switch {
+		case e.Term != "":
+			result += fmt.Sprintf(" (%s in %s)", e.Term, color.HiBlueString(e.Domain))
+		case e.Domain != "":
+			result += fmt.Sprintf(" (%s)", color.HiBlueString(e.Domain))
+		}
+		lines = append(lines, result)
+	}
+
+	_, err := v.view.Stream.Writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	return err
+}
+
+// formatSPFRecord generates the lines of the tree of an SPF record. The record itself is on the first line,
+// and each of its terms on a line of its own, prefixed by indent and the branches of the tree.
+func formatSPFRecord(record *mail.SPFRecord, indent string) []string {
+	if record.Error != "" {
+		return []string{fmt.Sprintf("%s %s", color.HiBlueString(record.Domain), color.HiRedString("error: %s", record.Error))}
+	}
+
+	lines := []string{fmt.Sprintf("%s %s", color.HiBlueString(record.Domain), color.HiWhiteString(record.Record))}
+	for i, term := range record.Terms {
+		branch, child := "├── ", "│   "
+		if i == len(record.Terms)-1 {
+			branch, child = "└── ", "    "
+		}
+
+		line := indent + branch + formatSPFTerm(term)
+		switch {
+		case term.Error != "":
+			line += " " + color.HiRedString("error: %s", term.Error)
+		case term.Name == "a" || term.Name == "mx":
+			addresses := strings.Join(term.Addresses, ", ")
+			if addresses == "" {
+				addresses = "no addresses"
+			}
+			line += " " + color.HiBlackString("→ %s", addresses)
+		case term.Name == "exists":
+			line += " " + color.HiBlackString("→ %t", term.Exists)
+		}
+		lines = append(lines, line)
+
+		if term.Include != nil {
+			included := formatSPFRecord(term.Include, indent+child+"    ")
+			lines = append(lines, indent+child+"└── "+included[0])
+			lines = append(lines, included[1:]...)
+		}
+	}
+	return lines
+}
+
+// formatSPFTerm generates a human-readable string for a term of an SPF record. Terms that cause DNS lookups are yellow.
+func formatSPFTerm(term mail.SPFExpansion) string {
+	if term.Lookup() {
+		return color.HiYellowString(term.String())
+	}
+	return term.String()
+}
+
+// formatSPFResult generates a human-readable string for the result of an SPF evaluation, colored by outcome.
+func formatSPFResult(result mail.SPFResult) string {
+	switch result {
+	case mail.SPFPass:
+		return color.HiGreenString(string(result))
+	case mail.SPFFail, mail.SPFPermError, mail.SPFTempError:
+		return color.HiRedString(string(result))
+	default:
+		return color.YellowString(string(result))
+	}
+}
+
+// JSONSPFRenderer for rendering an expanded SPF record as a single JSON document.
+type JSONSPFRenderer struct {
+	view *View
+}
+
+// Validate that JSONSPFRenderer implements the SPFRenderer interface.
+var _ SPFRenderer = (*JSONSPFRenderer)(nil)
+
+// RenderSPF renders an expanded SPF record as an indented JSON document to the output stream.
+func (v *JSONSPFRenderer) RenderSPF(report *mail.SPFReport) error {
+	enc := json.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,120 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/mail"
+)
+
+// testSPFReport is an SPF record that includes another one, evaluated for an address the include matches.
+var testSPFReport = &mail.SPFReport{
+	Domain: "example.com.",
+	Record: &mail.SPFRecord{
+		Domain: "example.com.",
+		Record: "v=spf1 include:_spf.example.net mx -all",
+		Terms: []mail.SPFExpansion{
+			{SPFTerm: mail.SPFTerm{Name: "include", Value: "_spf.example.net"}, Include: &mail.SPFRecord{
+				Domain: "_spf.example.net.",
+				Record: "v=spf1 ip4:203.0.113.0/24 exists:missing.example.net",
+				Terms: []mail.SPFExpansion{
+					{SPFTerm: mail.SPFTerm{Name: "ip4", Value: "203.0.113.0/24"}, Addresses: []string{"203.0.113.0/24"}},
+					{SPFTerm: mail.SPFTerm{Name: "exists", Value: "missing.example.net"}},
+				},
+			}},
+			{SPFTerm: mail.SPFTerm{Name: "mx"}, Addresses: []string{"198.51.100.25", "2001:db8::25"}},
+			{SPFTerm: mail.SPFTerm{Qualifier: "-", Name: "all"}},
+		},
+	},
+	Lookups:     3,
+	LookupLimit: 10,
+	Evaluation:  &mail.SPFEvaluation{IP: "203.0.113.7", Result: mail.SPFPass, Domain: "_spf.example.net.", Term: "ip4:203.0.113.0/24"},
+}
+
+// TestNewSPFRenderer tests that NewSPFRenderer returns a renderer for the human and JSON views only.
+func TestNewSPFRenderer(t *testing.T) {
+	b := bytes.Buffer{}
+
+	r, err := NewSPFRenderer(arguments.ViewHuman, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &HumanSPFRenderer{}, r)
+
+	r, err = NewSPFRenderer(arguments.ViewJSONDocument, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &JSONSPFRenderer{}, r)
+
+	_, err = NewSPFRenderer(arguments.ViewCSV, NewView(&b))
+	assert.EqualError(t, err, "error: the csv output is not supported by the spf command")
+}
+
+// TestHumanSPFRenderer_RenderSPF tests that the record is rendered as a tree, followed by the lookups and the evaluation.
+func TestHumanSPFRenderer_RenderSPF(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanSPFRenderer{view: NewView(&b)}
+
+	assert.NoError(t, r.RenderSPF(testSPFReport))
+
+	want := "example.com. v=spf1 include:_spf.example.net mx -all\n" +
+		"├── include:_spf.example.net\n" +
+		"│   └── _spf.example.net. v=spf1 ip4:203.0.113.0/24 exists:missing.example.net\n" +
+		"│       ├── ip4:203.0.113.0/24\n" +
+		"│       └── exists:missing.example.net → false\n" +
+		"├── mx → 198.51.100.25, 2001:db8::25\n" +
+		"└── -all\n" +
+		"\n" +
+		"3 DNS lookups, the limit is 10\n" +
+		"203.0.113.7: pass (ip4:203.0.113.0/24 in _spf.example.net.)\n"
+	assert.Equal(t, want, b.String())
+}
+
+// TestHumanSPFRenderer_RenderSPF_Error tests that records that could not be looked up show the error.
+func TestHumanSPFRenderer_RenderSPF_Error(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanSPFRenderer{view: NewView(&b)}
+
+	report := &mail.SPFReport{
+		Domain:      "example.com.",
+		Record:      &mail.SPFRecord{Domain: "example.com.", Terms: []mail.SPFExpansion{}, Error: "no SPF record"},
+		LookupLimit: 10,
+	}
+	assert.NoError(t, r.RenderSPF(report))
+	assert.Equal(t, "example.com. error: no SPF record\n\n0 DNS lookups, the limit is 10\n", b.String())
+}
+
+// TestJSONSPFRenderer_RenderSPF tests that the report is written as an indented JSON document.
+func TestJSONSPFRenderer_RenderSPF(t *testing.T) {
+	b := bytes.Buffer{}
+	r := &JSONSPFRenderer{view: NewView(&b)}
+
+	report := &mail.SPFReport{
+		Domain:      "example.com.",
+		Record:      &mail.SPFRecord{Domain: "example.com.", Record: "v=spf1 -all", Terms: []mail.SPFExpansion{{SPFTerm: mail.SPFTerm{Qualifier: "-", Name: "all"}}}},
+		Lookups:     0,
+		LookupLimit: 10,
+	}
+	assert.NoError(t, r.RenderSPF(report))
+
+	want := `{
+  "domain": "example.com.",
+  "record": {
+    "domain": "example.com.",
+    "record": "v=spf1 -all",
+    "terms": [
+      {
+        "qualifier": "-",
+        "name": "all"
+      }
+    ]
+  },
+  "lookups": 0,
+  "lookup_limit": 10
+}
+`
+	assert.Equal(t, want, b.String())
+}
@@ -0,0 +1,144 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// TemplateRecord is the data model passed to user-defined templates, once per record.
+type TemplateRecord struct {
+	// Domain is the domain that was queried.
+	Domain string
+
+	// Name is the fully-qualified name that owns the record. It differs from
+	// Domain for records that were reached through a CNAME chain.
+	Name string
+
+	// Type, Class and TTL are taken from the record's header. TTL is in seconds.
+	Type  string
+	Class string
+	TTL   uint32
+
+	// Value is the record data in presentation format, as found in zone files.
+	Value string
+
+	// Rdata holds the typed record data fields, keyed like the "rdata" object of the
+	// JSON document, e.g. {{.Rdata.preference}} and {{.Rdata.exchange}} for MX records.
+	// It is nil for record types zns does not know about.
+	Rdata map[string]any
+
+	// QueryType is the type of the query that returned the record.
+	QueryType string
+
+	// Server is the address of the DNS server that answered the query.
+	Server string
+
+	// Transport is the protocol the query was sent over: UDP, TCP or TLS.
+	Transport string
+
+	// RTT is the round-trip time of the query, e.g. {{.RTT.Milliseconds}}.
+	RTT time.Duration
+
+	// Size is the size of the response in bytes.
+	Size int
+
+	// Rcode is the response code of the query, e.g. NOERROR.
+	Rcode string
+
+	// Flags holds the header flags set in the response, e.g. {{join .Flags " "}}.
+	Flags []string
+}
+
+// templateFuncs holds the functions available to user-defined templates, in addition to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// ErrTemplateExecution is wrapped by the error Flush returns when the template failed to execute,
+// so that it can be told apart from a failure to write the output.
+var ErrTemplateExecution = errors.New("failed to execute format template")
+
+// TemplateRenderer for rendering each record through a user-defined text/template.
+// A newline is appended to the output of each record, unless the template already ends with one.
+type TemplateRenderer struct {
+	view     *View
+	tmpl     *template.Template
+	err      error
+	rendered recordSet
+}
+
+// Validate that TemplateRenderer implements the Renderer and Flusher interfaces.
+var _ Renderer = (*TemplateRenderer)(nil)
+var _ Flusher = (*TemplateRenderer)(nil)
+
+// NewTemplateRenderer creates a TemplateRenderer bound to an output stream.
+// The template is parsed up front, so that syntax errors are reported before any query is made.
+func NewTemplateRenderer(view *View, text string) (*TemplateRenderer, error) {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid format template: %v", err)
+	}
+
+	return &TemplateRenderer{
+		view:     view,
+		tmpl:     tmpl,
+		rendered: make(recordSet),
+	}, nil
+}
+
+// Render renders the answers of a DNS response through the template to the output stream.
+// Once the template fails to execute, all further records are skipped and the error is returned by Flush.
+func (v *TemplateRenderer) Render(domain string, resp *query.Response) {
+	for _, record := range v.rendered.filter(resp.Answer) {
+		if v.err != nil {
+			return
+		}
+
+		var b strings.Builder
+		if err := v.tmpl.Execute(&b, newTemplateRecord(domain, resp, record)); err != nil {
+			v.err = fmt.Errorf("%w: %v", ErrTemplateExecution, err)
+			return
+		}
+		if !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+
+		_, err := v.view.Stream.Writer.Write([]byte(b.String()))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Flush returns the error the template failed with, if any.
+func (v *TemplateRenderer) Flush() error {
+	return v.err
+}
+
+// newTemplateRecord builds the template data for a record of a DNS response.
+func newTemplateRecord(domain string, resp *query.Response, record dns.RR) TemplateRecord {
+	return TemplateRecord{
+		Domain:    domain,
+		Name:      record.Header().Name,
+		Type:      dns.TypeToString[record.Header().Rrtype],
+		Class:     dns.ClassToString[record.Header().Class],
+		TTL:       record.Header().Ttl,
+		Value:     query.Rdata(record),
+		Rdata:     formatRdata(record),
+		QueryType: dns.TypeToString[resp.Question[0].Qtype],
+		Server:    resp.Server,
+		Transport: resp.Transport,
+		RTT:       resp.RTT,
+		Size:      resp.Size,
+		Rcode:     dns.RcodeToString[resp.Rcode],
+		Flags:     headerFlags(resp.Msg),
+	}
+}
@@ -0,0 +1,92 @@
+package view
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTemplateRenderer(t *testing.T) {
+	t.Run("valid template", func(t *testing.T) {
+		b := bytes.Buffer{}
+		tr, err := NewTemplateRenderer(NewView(&b), "{{.Type}} {{.Value}}")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &b, tr.view.Stream.Writer)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := NewTemplateRenderer(NewView(&bytes.Buffer{}), "{{.Type")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error: invalid format template")
+	})
+}
+
+func TestTemplateRenderer_Render(t *testing.T) {
+	domain := "example.com"
+	records := []dns.RR{
+		&dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		},
+		&dns.TXT{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeTXT,
+				Class:  dns.ClassINET,
+				Ttl:    500,
+			},
+			Txt: []string{"v=spf1 ", "-all"},
+		},
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		b := bytes.Buffer{}
+		tr, err := NewTemplateRenderer(NewView(&b), "{{.Type}} {{.Name}} {{.TTL}} {{.Value}} {{.Server}} {{.Transport}} {{.RTT.Microseconds}} {{.Size}} {{.Rcode}} {{join .Flags \",\"}}")
+		assert.NoError(t, err)
+
+		tr.Render(domain, newResponse(records...))
+		assert.NoError(t, tr.Flush())
+
+		want := "A example.com. 222 127.0.0.1 127.0.0.1:53 UDP 1500 45 NOERROR qr,rd\n" +
+			"TXT example.com. 500 \"v=spf1 \" \"-all\" 127.0.0.1:53 UDP 1500 45 NOERROR qr,rd\n"
+		assert.Equal(t, want, b.String())
+	})
+
+	t.Run("rdata and functions", func(t *testing.T) {
+		b := bytes.Buffer{}
+		tr, err := NewTemplateRenderer(NewView(&b), "{{if .Rdata.strings}}{{join .Rdata.strings \"\" | upper}}\n{{end}}")
+		assert.NoError(t, err)
+
+		tr.Render(domain, newResponse(records...))
+		assert.NoError(t, tr.Flush())
+
+		// The A record renders to an empty string, which still ends up on its own line.
+		assert.Equal(t, "\nV=SPF1 -ALL\n", b.String())
+	})
+
+	t.Run("execution error", func(t *testing.T) {
+		b := bytes.Buffer{}
+		tr, err := NewTemplateRenderer(NewView(&b), "{{.Preference}}")
+		assert.NoError(t, err)
+
+		tr.Render(domain, newResponse(records...))
+
+		err = tr.Flush()
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, ErrTemplateExecution)
+		assert.Contains(t, err.Error(), "can't evaluate field Preference")
+		// The command adds its own prefix.
+		assert.NotContains(t, err.Error(), "error:")
+		assert.Empty(t, b.String())
+	})
+}
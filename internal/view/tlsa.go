@@ -0,0 +1,95 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/dane"
+)
+
+// maxTLSAData is the number of hex digits of the data of a TLSA record shown in the human view.
+// Longer data, such as full certificates, is shortened. It is enough for SHA2-256 digests.
+const maxTLSAData = 64
+
+// TLSARenderer renders TLSA records checked against a certificate chain.
+type TLSARenderer interface {
+	RenderTLSA(report *dane.Report) error
+}
+
+// NewTLSARenderer creates a TLSARenderer for a view type. Only the human and JSON document views are supported.
+func NewTLSARenderer(vt arguments.ViewType, view *View) (TLSARenderer, error) {
+	switch vt {
+	case arguments.ViewHuman:
+		return &HumanTLSARenderer{view: view}, nil
+	case arguments.ViewJSONDocument:
+		return &JSONTLSARenderer{view: view}, nil
+	default:
+		return nil, fmt.Errorf("error: the %s output is not supported by the tlsa command", vt)
+	}
+}
+
+// HumanTLSARenderer for rendering checked TLSA records in human-readable format, one line per record
+// with the certificate it matches, followed by the number of matching records.
+type HumanTLSARenderer struct {
+	view *View
+}
+
+// Validate that HumanTLSARenderer implements the TLSARenderer interface.
+var _ TLSARenderer = (*HumanTLSARenderer)(nil)
+
+// RenderTLSA renders checked TLSA records in human-readable format to the output stream.
+func (v *HumanTLSARenderer) RenderTLSA(report *dane.Report) error {
+	var lines []string
+	name := color.HiBlueString(report.Name)
+	for _, r := range report.Records {
+		data := r.Data
+		if len(data) > maxTLSAData {
+			data = data[:maxTLSAData/2] + "…"
+		}
+		record := fmt.Sprintf("%s %s", color.HiRedString("%d %d %d", r.Usage, r.Selector, r.MatchingType), color.HiWhiteString(data))
+
+		var match string
+		switch {
+		case r.Error != "":
+			match = color.HiRedString("error: %s", r.Error)
+		case r.Certificate != nil:
+			match = color.HiGreenString("matches certificate %d", *r.Certificate) + fmt.Sprintf(" (%s)", report.Certificates[*r.Certificate].Subject)
+		default:
+			match = color.YellowString("no match")
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%s\t%s\t%s", name, record, color.HiYellowString(r.Description()), match))
+	}
+
+	summary := fmt.Sprintf("%d of %d %s match the certificate chain", report.Matches, len(report.Records), plural(len(report.Records), "record"))
+	switch {
+	case len(report.Records) == 0:
+		lines = append(lines, fmt.Sprintf("%s\t%s", name, color.HiBlackString("-")))
+		summary = color.HiRedString("no TLSA records")
+	case report.Matches == 0:
+		summary = color.HiRedString(summary)
+	default:
+		summary = color.HiGreenString(summary)
+	}
+	lines = append(lines, "", summary)
+
+	_, err := v.view.Stream.Writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	return err
+}
+
+// JSONTLSARenderer for rendering checked TLSA records as a single JSON document.
+type JSONTLSARenderer struct {
+	view *View
+}
+
+// Validate that JSONTLSARenderer implements the TLSARenderer interface.
+var _ TLSARenderer = (*JSONTLSARenderer)(nil)
+
+// RenderTLSA renders checked TLSA records as an indented JSON document to the output stream.
+func (v *JSONTLSARenderer) RenderTLSA(report *dane.Report) error {
+	enc := json.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
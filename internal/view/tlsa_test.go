@@ -0,0 +1,105 @@
+package view
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/dane"
+)
+
+// TestNewTLSARenderer tests that NewTLSARenderer returns a renderer for the human and JSON views only.
+func TestNewTLSARenderer(t *testing.T) {
+	b := bytes.Buffer{}
+
+	r, err := NewTLSARenderer(arguments.ViewHuman, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &HumanTLSARenderer{}, r)
+
+	r, err = NewTLSARenderer(arguments.ViewJSONDocument, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &JSONTLSARenderer{}, r)
+
+	_, err = NewTLSARenderer(arguments.ViewYAML, NewView(&b))
+	assert.EqualError(t, err, "error: the yaml output is not supported by the tlsa command")
+}
+
+// TestHumanTLSARenderer_RenderTLSA tests that each record is followed by the certificate it matches, and the records by a summary.
+func TestHumanTLSARenderer_RenderTLSA(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanTLSARenderer{view: NewView(&b)}
+
+	leaf := 0
+	report := &dane.Report{
+		Name:         "_25._tcp.mail.example.com.",
+		Certificates: []dane.Certificate{{Subject: "CN=mail.example.com", Issuer: "CN=Example CA"}},
+		Records: []dane.Result{
+			{Usage: 3, Selector: 1, MatchingType: 1, Data: "8cb0fc6c", Certificate: &leaf},
+			{Usage: 2, Selector: 0, MatchingType: 0, Data: strings.Repeat("ab", 40)},
+			{Usage: 4, Selector: 1, MatchingType: 1, Data: "8cb0fc6c", Error: "unknown usage 4"},
+		},
+		Matches: 1,
+	}
+	assert.NoError(t, r.RenderTLSA(report))
+
+	want := "_25._tcp.mail.example.com.\t3 1 1 8cb0fc6c\tDANE-EE SPKI SHA2-256\tmatches certificate 0 (CN=mail.example.com)\n" +
+		"_25._tcp.mail.example.com.\t2 0 0 " + strings.Repeat("ab", 16) + "…\tDANE-TA Cert Full\tno match\n" +
+		"_25._tcp.mail.example.com.\t4 1 1 8cb0fc6c\t4 SPKI SHA2-256\terror: unknown usage 4\n" +
+		"\n" +
+		"1 of 3 records match the certificate chain\n"
+	assert.Equal(t, want, b.String())
+}
+
+// TestHumanTLSARenderer_RenderTLSA_NoRecords tests that a name without TLSA records is reported as such.
+func TestHumanTLSARenderer_RenderTLSA_NoRecords(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanTLSARenderer{view: NewView(&b)}
+
+	assert.NoError(t, r.RenderTLSA(&dane.Report{Name: "_443._tcp.example.com.", Records: []dane.Result{}}))
+	assert.Equal(t, "_443._tcp.example.com.\t-\n\nno TLSA records\n", b.String())
+}
+
+// TestJSONTLSARenderer_RenderTLSA tests that the report is written as an indented JSON document.
+func TestJSONTLSARenderer_RenderTLSA(t *testing.T) {
+	b := bytes.Buffer{}
+	r := &JSONTLSARenderer{view: NewView(&b)}
+
+	leaf := 0
+	report := &dane.Report{
+		Name:         "_443._tcp.example.com.",
+		Certificates: []dane.Certificate{{Subject: "CN=example.com", Issuer: "CN=Example CA", NotAfter: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		Records:      []dane.Result{{Usage: 3, Selector: 1, MatchingType: 1, Data: "8cb0fc6c", Certificate: &leaf}},
+		Matches:      1,
+	}
+	assert.NoError(t, r.RenderTLSA(report))
+
+	want := `{
+  "name": "_443._tcp.example.com.",
+  "certificates": [
+    {
+      "subject": "CN=example.com",
+      "issuer": "CN=Example CA",
+      "not_after": "2030-01-01T00:00:00Z"
+    }
+  ],
+  "records": [
+    {
+      "usage": 3,
+      "selector": 1,
+      "matching_type": 1,
+      "data": "8cb0fc6c",
+      "certificate": 0
+    }
+  ],
+  "matches": 1
+}
+`
+	assert.Equal(t, want, b.String())
+}
@@ -1,9 +1,20 @@
 package view
 
-import "io"
+import (
+	"io"
+
+	"github.com/znscli/zns/internal/arguments"
+)
 
 type View struct {
 	Stream *Stream
+
+	// TTL selects how renderers that format TTLs display them. The zero value displays them in human-readable form.
+	TTL arguments.TTLFormat
+
+	// Stats selects whether the human renderer displays the server, transport, round-trip time, size,
+	// response code and flags of the response each record was returned in.
+	Stats bool
 }
 
 type Stream struct {
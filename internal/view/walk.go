@@ -0,0 +1,100 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/walk"
+)
+
+// WalkRenderer renders the names and hashes found by walking a zone.
+type WalkRenderer interface {
+	RenderWalk(report *walk.Report) error
+}
+
+// NewWalkRenderer creates a WalkRenderer for a view type. Only the human and JSON document views are supported.
+func NewWalkRenderer(vt arguments.ViewType, view *View) (WalkRenderer, error) {
+	switch vt {
+	case arguments.ViewHuman:
+		return &HumanWalkRenderer{view: view}, nil
+	case arguments.ViewJSONDocument:
+		return &JSONWalkRenderer{view: view}, nil
+	default:
+		return nil, fmt.Errorf("error: the %s output is not supported by the walk command", vt)
+	}
+}
+
+// HumanWalkRenderer for rendering a walked zone in human-readable format. Names are listed with the types
+// they own, and hashes with their types and the name they were cracked to, followed by a summary.
+type HumanWalkRenderer struct {
+	view *View
+}
+
+// Validate that HumanWalkRenderer implements the WalkRenderer interface.
+var _ WalkRenderer = (*HumanWalkRenderer)(nil)
+
+// RenderWalk renders a walked zone in human-readable format to the output stream.
+func (v *HumanWalkRenderer) RenderWalk(report *walk.Report) error {
+	var lines []string
+	var summary string
+	switch report.Method {
+	case walk.MethodNSEC3:
+		for _, h := range report.Hashes {
+			name := color.HiBlackString("-")
+			if h.Name != "" {
+				name = color.HiBlueString(h.Name)
+			}
+			lines = append(lines, fmt.Sprintf("%s\t%s\t%s", color.HiWhiteString(h.Hash), color.HiYellowString(strings.Join(h.Types, " ")), name))
+		}
+
+		p := report.Params
+		algorithm := fmt.Sprintf("algorithm %d", p.Algorithm)
+		if p.Algorithm == dns.SHA1 {
+			algorithm = "SHA-1"
+		}
+		hashes := "hashes"
+		if len(report.Hashes) == 1 {
+			hashes = "hash"
+		}
+		summary = fmt.Sprintf("%d %s (%s, %d %s, salt %s), %d cracked", len(report.Hashes), hashes,
+			algorithm, p.Iterations, plural(int(p.Iterations), "iteration"), formatSalt(p.Salt), report.Cracked())
+	default:
+		for _, n := range report.Names {
+			lines = append(lines, fmt.Sprintf("%s\t%s", color.HiBlueString(n.Name), color.HiYellowString(strings.Join(n.Types, " "))))
+		}
+		summary = fmt.Sprintf("%d %s found by walking the NSEC chain", len(report.Names), plural(len(report.Names), "name"))
+	}
+
+	if report.Complete {
+		summary = color.HiGreenString(summary)
+	} else {
+		queries := "queries"
+		if report.Queries == 1 {
+			queries = "query"
+		}
+		summary = color.HiRedString("%s, the %s chain was not walked to its end after %d %s", summary, report.Method, report.Queries, queries)
+	}
+	lines = append(lines, "", summary)
+
+	_, err := v.view.Stream.Writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	return err
+}
+
+// JSONWalkRenderer for rendering a walked zone as a single JSON document.
+type JSONWalkRenderer struct {
+	view *View
+}
+
+// Validate that JSONWalkRenderer implements the WalkRenderer interface.
+var _ WalkRenderer = (*JSONWalkRenderer)(nil)
+
+// RenderWalk renders a walked zone as an indented JSON document to the output stream.
+func (v *JSONWalkRenderer) RenderWalk(report *walk.Report) error {
+	enc := json.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,111 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/walk"
+)
+
+// TestNewWalkRenderer tests that NewWalkRenderer returns a renderer for the human and JSON views only.
+func TestNewWalkRenderer(t *testing.T) {
+	b := bytes.Buffer{}
+
+	r, err := NewWalkRenderer(arguments.ViewHuman, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &HumanWalkRenderer{}, r)
+
+	r, err = NewWalkRenderer(arguments.ViewJSONDocument, NewView(&b))
+	assert.NoError(t, err)
+	assert.IsType(t, &JSONWalkRenderer{}, r)
+
+	_, err = NewWalkRenderer(arguments.ViewCSV, NewView(&b))
+	assert.EqualError(t, err, "error: the csv output is not supported by the walk command")
+}
+
+// TestHumanWalkRenderer_RenderWalk_NSEC tests that the names of an NSEC chain are rendered with their types.
+func TestHumanWalkRenderer_RenderWalk_NSEC(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanWalkRenderer{view: NewView(&b)}
+
+	report := &walk.Report{
+		Zone:   "example.com.",
+		Method: walk.MethodNSEC,
+		Names: []walk.Name{
+			{Name: "example.com.", Types: []string{"A", "NS", "SOA", "RRSIG", "NSEC", "DNSKEY"}},
+			{Name: "www.example.com.", Types: []string{"A", "RRSIG", "NSEC"}},
+		},
+		Complete: true,
+		Queries:  3,
+	}
+	assert.NoError(t, r.RenderWalk(report))
+
+	want := "example.com.\tA NS SOA RRSIG NSEC DNSKEY\n" +
+		"www.example.com.\tA RRSIG NSEC\n" +
+		"\n" +
+		"2 names found by walking the NSEC chain\n"
+	assert.Equal(t, want, b.String())
+}
+
+// TestHumanWalkRenderer_RenderWalk_NSEC3 tests that hashes are rendered with the names they were cracked to,
+// and that an incomplete chain is reported.
+func TestHumanWalkRenderer_RenderWalk_NSEC3(t *testing.T) {
+	t.Setenv("NO_COLOR", "true") // Disable colors for easier testing
+
+	b := bytes.Buffer{}
+	r := &HumanWalkRenderer{view: NewView(&b)}
+
+	report := &walk.Report{
+		Zone:   "example.com.",
+		Method: walk.MethodNSEC3,
+		Params: &walk.Params{Algorithm: 1, Iterations: 1},
+		Hashes: []walk.Hash{
+			{Hash: "A50KESEUHEPIC9VG21SRV1GOQB230H36", Types: []string{"A", "RRSIG"}, Name: "www.example.com."},
+			{Hash: "BJIUAMQ9Q0CRNS1ULQ9APT6BCNF976MU", Types: []string{"MX", "RRSIG"}},
+		},
+		Queries: 1,
+	}
+	assert.NoError(t, r.RenderWalk(report))
+
+	want := "A50KESEUHEPIC9VG21SRV1GOQB230H36\tA RRSIG\twww.example.com.\n" +
+		"BJIUAMQ9Q0CRNS1ULQ9APT6BCNF976MU\tMX RRSIG\t-\n" +
+		"\n" +
+		"2 hashes (SHA-1, 1 iteration, salt -), 1 cracked, the NSEC3 chain was not walked to its end after 1 query\n"
+	assert.Equal(t, want, b.String())
+}
+
+// TestJSONWalkRenderer_RenderWalk tests that the report is written as an indented JSON document.
+func TestJSONWalkRenderer_RenderWalk(t *testing.T) {
+	b := bytes.Buffer{}
+	r := &JSONWalkRenderer{view: NewView(&b)}
+
+	report := &walk.Report{
+		Zone:     "example.com.",
+		Method:   walk.MethodNSEC,
+		Names:    []walk.Name{{Name: "example.com.", Types: []string{"NSEC"}}},
+		Complete: true,
+		Queries:  2,
+	}
+	assert.NoError(t, r.RenderWalk(report))
+
+	want := `{
+  "zone": "example.com.",
+  "method": "NSEC",
+  "names": [
+    {
+      "name": "example.com.",
+      "types": [
+        "NSEC"
+      ]
+    }
+  ],
+  "complete": true,
+  "queries": 2
+}
+`
+	assert.Equal(t, want, b.String())
+}
@@ -0,0 +1,88 @@
+package view
+
+import (
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+	znsversion "github.com/znscli/zns/version"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLDocument is the YAML representation of a single zns run. It uses the field names of the
+// JSON document, but groups the records by owner name and type, so that it can be compared
+// against DNS state kept in YAML.
+type YAMLDocument struct {
+	SchemaVersion int                                    `yaml:"schema_version"`
+	Version       string                                 `yaml:"version"`
+	Timestamp     time.Time                              `yaml:"timestamp"`
+	Domain        string                                 `yaml:"domain"`
+	Records       map[string]map[string][]DocumentRecord `yaml:"records"`
+	CNAMEChain    *DocumentChain                         `yaml:"cname_chain,omitempty"`
+}
+
+// YAMLRenderer for rendering a single YAML document per run.
+// Like the JSONDocumentRenderer, it buffers all responses and writes the document when flushed.
+type YAMLRenderer struct {
+	view     *View
+	document *YAMLDocument
+	rendered recordSet
+}
+
+// Validate that YAMLRenderer implements the Renderer, Flusher, ChainRenderer and DomainRenderer interfaces.
+var _ Renderer = (*YAMLRenderer)(nil)
+var _ Flusher = (*YAMLRenderer)(nil)
+var _ ChainRenderer = (*YAMLRenderer)(nil)
+var _ DomainRenderer = (*YAMLRenderer)(nil)
+
+// NewYAMLRenderer creates a YAMLRenderer bound to an output stream.
+func NewYAMLRenderer(view *View) *YAMLRenderer {
+	return &YAMLRenderer{
+		view: view,
+		document: &YAMLDocument{
+			SchemaVersion: DocumentSchemaVersion,
+			Version:       znsversion.Version,
+			Timestamp:     time.Now().UTC(),
+			Records:       make(map[string]map[string][]DocumentRecord),
+		},
+		rendered: make(recordSet),
+	}
+}
+
+// Render adds the answers of a DNS response to the document, grouped by owner name and type.
+func (v *YAMLRenderer) Render(domain string, resp *query.Response) {
+	if v.document.Domain == "" {
+		v.document.Domain = domain
+	}
+
+	for _, record := range v.rendered.filter(resp.Answer) {
+		name := record.Header().Name
+		rtype := dns.TypeToString[record.Header().Rrtype]
+
+		if v.document.Records[name] == nil {
+			v.document.Records[name] = make(map[string][]DocumentRecord)
+		}
+		v.document.Records[name][rtype] = append(v.document.Records[name][rtype], newDocumentRecord(resp, record))
+	}
+}
+
+// SetDomain sets the domain of the document.
+func (v *YAMLRenderer) SetDomain(domain string) {
+	v.document.Domain = domain
+}
+
+// RenderChain adds the CNAME chain of a domain to the document.
+func (v *YAMLRenderer) RenderChain(domain string, chain *query.Chain) {
+	documentChain := newDocumentChain(chain)
+	v.document.CNAMEChain = &documentChain
+}
+
+// Flush writes the document to the output stream.
+func (v *YAMLRenderer) Flush() error {
+	enc := yaml.NewEncoder(v.view.Stream.Writer)
+	enc.SetIndent(2)
+	if err := enc.Encode(v.document); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,112 @@
+package view
+
+import (
+	"bytes"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	znsversion "github.com/znscli/zns/version"
+)
+
+// TestNewRenderer_YAML tests the NewRenderer function, which should return a YAMLRenderer
+// and bind provided io.Writer to the view's stream writer.
+func TestNewRenderer_YAML(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewRenderer(arguments.ViewYAML, NewView(&b))
+
+	// Check that the view is a YAMLRenderer
+	yamlRenderer, ok := r.(*YAMLRenderer)
+	assert.True(t, ok, "Expected r to be of type *YAMLRenderer")
+
+	// Check that the view's stream writer is the same as the buffer
+	assert.Equal(t, &b, yamlRenderer.view.Stream.Writer)
+}
+
+// TestYAMLRenderer_Render tests that records are grouped by owner name and type,
+// and that nothing is written until the renderer is flushed.
+func TestYAMLRenderer_Render(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewYAMLRenderer(NewView(&b))
+
+	domain := "example.com"
+	records := []dns.RR{
+		&dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    222,
+			},
+			A: net.IPv4(127, 0, 0, 1),
+		},
+		&dns.MX{
+			Hdr: dns.RR_Header{
+				Name:   "example.com.",
+				Rrtype: dns.TypeMX,
+				Class:  dns.ClassINET,
+				Ttl:    500,
+			},
+			Preference: 10,
+			Mx:         "mail.example.com.",
+		},
+	}
+
+	r.Render(domain, newResponse(records...))
+
+	assert.Empty(t, b.String())
+	assert.NoError(t, r.Flush())
+
+	// The timestamp changes on every run.
+	got := regexp.MustCompile(`(?m)^timestamp: .*$`).ReplaceAllString(b.String(), "timestamp: <timestamp>")
+
+	want := `schema_version: 1
+version: ` + znsversion.Version + `
+timestamp: <timestamp>
+domain: example.com
+records:
+  example.com.:
+    A:
+      - name: example.com.
+        type: A
+        class: IN
+        ttl: 222
+        value: 127.0.0.1
+        rdata:
+          address: 127.0.0.1
+    MX:
+      - name: example.com.
+        type: MX
+        class: IN
+        ttl: 500
+        value: 10 mail.example.com.
+        rdata:
+          exchange: mail.example.com.
+          preference: 10
+`
+	assert.Equal(t, want, got)
+}
+
+// TestYAMLRenderer_Render_TXT tests that the strings of a TXT record stay apart,
+// both in the quoted value and in the rdata.
+func TestYAMLRenderer_Render_TXT(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewYAMLRenderer(NewView(&b))
+
+	r.Render("example.com", newResponse(&dns.TXT{
+		Hdr: dns.RR_Header{
+			Name:   "example.com.",
+			Rrtype: dns.TypeTXT,
+			Class:  dns.ClassINET,
+			Ttl:    500,
+		},
+		Txt: []string{"v=DKIM1; k=rsa; ", "p=MIGf"},
+	}))
+
+	assert.NoError(t, r.Flush())
+	assert.Contains(t, b.String(), `value: '"v=DKIM1; k=rsa; " "p=MIGf"'`)
+	assert.Contains(t, b.String(), "strings:\n            - 'v=DKIM1; k=rsa; '\n            - p=MIGf\n")
+}
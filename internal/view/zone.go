@@ -0,0 +1,57 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// ZoneRenderer for rendering records in RFC 1035 presentation format, as found in master (zone) files.
+// Names are always fully qualified and TTLs are numeric, so the output can be pasted into a zone file as is.
+type ZoneRenderer struct {
+	view     *View
+	origin   bool
+	rendered recordSet
+}
+
+// Validate that ZoneRenderer implements the Renderer and ChainRenderer interfaces.
+var _ Renderer = (*ZoneRenderer)(nil)
+var _ ChainRenderer = (*ZoneRenderer)(nil)
+
+// NewZoneRenderer creates a ZoneRenderer bound to an output stream.
+func NewZoneRenderer(view *View) *ZoneRenderer {
+	return &ZoneRenderer{
+		view:     view,
+		rendered: make(recordSet),
+	}
+}
+
+// Render renders the answers of a DNS response in presentation format to the output stream, one record per line.
+// The $ORIGIN directive is written before the first record.
+func (v *ZoneRenderer) Render(domain string, resp *query.Response) {
+	if !v.origin {
+		v.write(fmt.Sprintf("$ORIGIN %s", dns.Fqdn(domain)))
+		v.origin = true
+	}
+
+	for _, record := range v.rendered.filter(resp.Answer) {
+		v.write(record.String())
+	}
+}
+
+// RenderChain renders the CNAME chain of a domain as zone file comments.
+func (v *ZoneRenderer) RenderChain(domain string, chain *query.Chain) {
+	v.write(fmt.Sprintf("; CNAME chain: %s", strings.Join(chain.Names, " → ")))
+	for _, warning := range chainWarnings(chain) {
+		v.write(fmt.Sprintf("; Warning: %s", warning))
+	}
+}
+
+func (v *ZoneRenderer) write(line string) {
+	_, err := v.view.Stream.Writer.Write([]byte(line + "\n"))
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,72 @@
+package view
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/arguments"
+	"github.com/znscli/zns/internal/query"
+)
+
+// TestNewRenderer_Zone tests the NewRenderer function, which should return a ZoneRenderer
+// and bind provided io.Writer to the view's stream writer.
+func TestNewRenderer_Zone(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewRenderer(arguments.ViewZone, NewView(&b))
+
+	// Check that the view is a ZoneRenderer
+	zoneRenderer, ok := r.(*ZoneRenderer)
+	assert.True(t, ok, "Expected r to be of type *ZoneRenderer")
+
+	// Check that the view's stream writer is the same as the buffer
+	assert.Equal(t, &b, zoneRenderer.view.Stream.Writer)
+}
+
+func TestZoneRenderer_Render(t *testing.T) {
+	b := bytes.Buffer{}
+	r := NewZoneRenderer(NewView(&b))
+
+	domain := "www.example.com"
+	cname := &dns.CNAME{
+		Hdr: dns.RR_Header{
+			Name:   "www.example.com.",
+			Rrtype: dns.TypeCNAME,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		Target: "example.com.",
+	}
+	a := &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   "example.com.",
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    222,
+		},
+		A: net.IPv4(127, 0, 0, 1),
+	}
+	txt := &dns.TXT{
+		Hdr: dns.RR_Header{
+			Name:   "example.com.",
+			Rrtype: dns.TypeTXT,
+			Class:  dns.ClassINET,
+			Ttl:    222,
+		},
+		Txt: []string{"v=spf1 -all"},
+	}
+
+	r.Render(domain, newResponse(cname, a))
+	r.Render(domain, newResponse(cname, txt))
+	r.RenderChain(domain, &query.Chain{Names: []string{"www.example.com.", "example.com."}})
+
+	want := "$ORIGIN www.example.com.\n" +
+		"www.example.com.\t3600\tIN\tCNAME\texample.com.\n" +
+		"example.com.\t222\tIN\tA\t127.0.0.1\n" +
+		"example.com.\t222\tIN\tTXT\t\"v=spf1 -all\"\n" +
+		"; CNAME chain: www.example.com. → example.com.\n"
+
+	assert.Equal(t, want, b.String())
+}
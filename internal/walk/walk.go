@@ -0,0 +1,304 @@
+// Package walk enumerates the names of a DNSSEC-signed zone from the records that deny the existence of names.
+// In a zone signed with NSEC, each name points at the next one, so following the chain lists every name, see
+// RFC 4034. In a zone signed with NSEC3, the chain links hashes of the names instead, see RFC 5155. The hashes are
+// collected by querying names that do not exist, and can be cracked offline against a wordlist.
+package walk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+// Resolver queries names with the DO bit set, so that responses include the NSEC and NSEC3 records.
+type Resolver interface {
+	QueryDNSSEC(name string, qtype uint16) (*query.Response, error)
+}
+
+// The methods of authenticated denial of existence a zone can be signed with.
+const (
+	MethodNSEC  = "NSEC"
+	MethodNSEC3 = "NSEC3"
+)
+
+// maxCandidates bounds the number of names hashed while looking for one in a gap of the NSEC3 chain.
+const maxCandidates = 1 << 20
+
+// Options configures a walk.
+type Options struct {
+	// MaxQueries bounds the number of queries sent, or is 0 for no limit.
+	MaxQueries int
+}
+
+// Name is a name of a zone signed with NSEC, and the types of the records it owns.
+type Name struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+}
+
+// Hash is an NSEC3 record of a zone: the hash of a name, the next hash of the chain and the types of the records
+// the name owns.
+type Hash struct {
+	Hash  string   `json:"hash"`
+	Next  string   `json:"next"`
+	Types []string `json:"types"`
+
+	// OptOut is set if the span up to the next hash may contain insecure delegations, which are not in the chain.
+	OptOut bool `json:"opt_out"`
+
+	// Name is the name the hash was cracked to, if it was.
+	Name string `json:"name,omitempty"`
+}
+
+// Params are the NSEC3 parameters a zone hashes its names with.
+type Params struct {
+	Algorithm  uint8  `json:"algorithm"`
+	Iterations uint16 `json:"iterations"`
+	Salt       string `json:"salt"`
+}
+
+// Report is the result of walking a zone.
+type Report struct {
+	Zone   string `json:"zone"`
+	Method string `json:"method"`
+
+	// Names holds the names of a zone signed with NSEC, in the order of the chain.
+	Names []Name `json:"names,omitempty"`
+
+	// Params and Hashes hold the parameters and the hashes of a zone signed with NSEC3, sorted by hash.
+	Params *Params `json:"params,omitempty"`
+	Hashes []Hash  `json:"hashes,omitempty"`
+
+	// Complete is set if the whole chain was walked, back to its start.
+	Complete bool `json:"complete"`
+
+	// Queries is the number of queries sent.
+	Queries int `json:"queries"`
+}
+
+// Cracked returns the number of hashes that were cracked.
+func (r *Report) Cracked() int {
+	n := 0
+	for _, h := range r.Hashes {
+		if h.Name != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Crack hashes the apex of the zone and <word>.<zone> for every word, offline, and sets the name of the hashes
+// that match. It returns the number of hashes that were cracked.
+func (r *Report) Crack(words []string) int {
+	if r.Params == nil {
+		return 0
+	}
+
+	index := make(map[string]int, len(r.Hashes))
+	for i, h := range r.Hashes {
+		index[h.Hash] = i
+	}
+
+	n := 0
+	for _, name := range append([]string{r.Zone}, words...) {
+		if name != r.Zone {
+			name = dns.Fqdn(name + "." + r.Zone)
+		}
+		i, ok := index[dns.HashName(name, r.Params.Algorithm, r.Params.Iterations, r.Params.Salt)]
+		if ok && r.Hashes[i].Name == "" {
+			r.Hashes[i].Name = name
+			n++
+		}
+	}
+	return n
+}
+
+// Walk enumerates the names of a zone. A zone with an NSEC3PARAM record is walked as an NSEC3 zone, otherwise
+// the NSEC chain is followed from the apex of the zone. The walk stops early if the chain leads out of the zone
+// or loops, if the server does not return the records needed to continue, or after MaxQueries queries.
+func Walk(r Resolver, zone string, opts Options) (*Report, error) {
+	report := &Report{Zone: dns.Fqdn(zone)}
+
+	resp, err := report.query(r, report.Zone, dns.TypeNSEC3PARAM)
+	if err != nil {
+		return nil, err
+	}
+	for _, rr := range resp.Answer {
+		if param, ok := rr.(*dns.NSEC3PARAM); ok && strings.EqualFold(param.Hdr.Name, report.Zone) {
+			report.Method = MethodNSEC3
+			report.Params = &Params{Algorithm: param.Hash, Iterations: param.Iterations, Salt: param.Salt}
+			if err := report.walkNSEC3(r, opts); err != nil {
+				return nil, err
+			}
+			return report, nil
+		}
+	}
+
+	report.Method = MethodNSEC
+	if err := report.walkNSEC(r, opts); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// query sends a query and counts it. Responses with an error response code are an error, but names that do
+// not exist are not.
+func (r *Report) query(resolver Resolver, name string, qtype uint16) (*query.Response, error) {
+	r.Queries++
+	resp, err := resolver.QueryDNSSEC(name, qtype)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// exhausted reports whether the walk sent as many queries as it may.
+func (r *Report) exhausted(opts Options) bool {
+	return opts.MaxQueries > 0 && r.Queries >= opts.MaxQueries
+}
+
+// walkNSEC follows the NSEC chain from the apex of the zone until it leads back to the apex.
+func (r *Report) walkNSEC(resolver Resolver, opts Options) error {
+	seen := make(map[string]bool)
+	name := r.Zone
+	for !r.exhausted(opts) {
+		resp, err := r.query(resolver, name, dns.TypeNSEC)
+		if err != nil {
+			return err
+		}
+
+		var nsec *dns.NSEC
+		for _, rr := range append(resp.Answer, resp.Ns...) {
+			if rec, ok := rr.(*dns.NSEC); ok && strings.EqualFold(rec.Hdr.Name, name) {
+				nsec = rec
+				break
+			}
+		}
+		if nsec == nil {
+			if name == r.Zone {
+				return fmt.Errorf("%s is not signed with NSEC or NSEC3", r.Zone)
+			}
+			return nil
+		}
+
+		seen[strings.ToLower(name)] = true
+		r.Names = append(r.Names, Name{Name: name, Types: query.TypeNames(nsec.TypeBitMap)})
+
+		next := dns.Fqdn(nsec.NextDomain)
+		if strings.EqualFold(next, r.Zone) {
+			r.Complete = true
+			return nil
+		}
+		if seen[strings.ToLower(next)] || !dns.IsSubDomain(r.Zone, next) {
+			return nil
+		}
+		name = next
+	}
+	return nil
+}
+
+// walkNSEC3 collects the NSEC3 records of the zone. Names whose hash falls in a gap of the chain collected so far
+// are queried, as the server answers them with the NSEC3 record that covers the gap, until the chain is closed.
+func (r *Report) walkNSEC3(resolver Resolver, opts Options) error {
+	p := r.Params
+	if dns.HashName(r.Zone, p.Algorithm, p.Iterations, p.Salt) == "" {
+		return fmt.Errorf("unsupported NSEC3 hash algorithm %d", p.Algorithm)
+	}
+
+	candidate := 0
+	for !r.exhausted(opts) && !r.closed() {
+		name := ""
+		for ; candidate < maxCandidates; candidate++ {
+			n := fmt.Sprintf("zns-%d.%s", candidate, r.Zone)
+			if !r.covers(dns.HashName(n, p.Algorithm, p.Iterations, p.Salt)) {
+				name = n
+				candidate++
+				break
+			}
+		}
+		if name == "" {
+			return nil
+		}
+
+		resp, err := r.query(resolver, name, dns.TypeA)
+		if err != nil {
+			return err
+		}
+		if !r.addHashes(append(resp.Answer, resp.Ns...)) {
+			// The server did not return the record covering the name, so the gap cannot be closed.
+			return nil
+		}
+	}
+	r.Complete = r.closed()
+	return nil
+}
+
+// addHashes adds the NSEC3 records of the zone that were not collected yet, and reports whether there were any.
+func (r *Report) addHashes(records []dns.RR) bool {
+	added := false
+	for _, rr := range records {
+		nsec3, ok := rr.(*dns.NSEC3)
+		if !ok {
+			continue
+		}
+		labels := dns.SplitDomainName(nsec3.Hdr.Name)
+		if len(labels) < 2 || !strings.EqualFold(dns.Fqdn(strings.Join(labels[1:], ".")), r.Zone) {
+			continue
+		}
+
+		hash := strings.ToUpper(labels[0])
+		i := sort.Search(len(r.Hashes), func(i int) bool { return r.Hashes[i].Hash >= hash })
+		if i < len(r.Hashes) && r.Hashes[i].Hash == hash {
+			continue
+		}
+		r.Hashes = append(r.Hashes, Hash{})
+		copy(r.Hashes[i+1:], r.Hashes[i:])
+		r.Hashes[i] = Hash{
+			Hash:   hash,
+			Next:   strings.ToUpper(nsec3.NextDomain),
+			Types:  query.TypeNames(nsec3.TypeBitMap),
+			OptOut: nsec3.Flags&1 == 1,
+		}
+		added = true
+	}
+	return added
+}
+
+// covers reports whether a hash is one of the collected hashes, or falls in the gap between one and its next hash.
+// Hashes are in base32hex, whose alphabet sorts like the values it encodes, so they can be compared as strings.
+func (r *Report) covers(hash string) bool {
+	if len(r.Hashes) == 0 {
+		return false
+	}
+	// The gap that may contain the hash starts at the last hash before it, or wraps around from the last hash.
+	i := sort.Search(len(r.Hashes), func(i int) bool { return r.Hashes[i].Hash > hash }) - 1
+	if i < 0 {
+		i = len(r.Hashes) - 1
+	}
+	h := r.Hashes[i]
+	switch {
+	case h.Hash == hash:
+		return true
+	case h.Hash < h.Next:
+		return h.Hash < hash && hash < h.Next
+	default:
+		// The last gap of the chain wraps around from the highest hash to the lowest.
+		return hash > h.Hash || hash < h.Next
+	}
+}
+
+// closed reports whether the collected hashes form a closed chain, where every hash links to the next.
+func (r *Report) closed() bool {
+	for i, h := range r.Hashes {
+		if h.Next != r.Hashes[(i+1)%len(r.Hashes)].Hash {
+			return false
+		}
+	}
+	return len(r.Hashes) > 0
+}
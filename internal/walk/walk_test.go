@@ -0,0 +1,186 @@
+package walk
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/dnstest"
+	"github.com/znscli/zns/internal/query"
+)
+
+// fakeResolver answers from the records of a signed zone. Names that do not exist are answered with the NSEC3
+// record covering their hash, if the zone has NSEC3 records.
+type fakeResolver struct {
+	records []dns.RR
+	err     error
+}
+
+func (r *fakeResolver) QueryDNSSEC(name string, qtype uint16) (*query.Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.Response = true
+
+	zone := &dnstest.Zone{Records: r.records}
+	answer, exists := zone.Find(name, qtype)
+	msg.Answer = answer
+	if !exists {
+		msg.Rcode = dns.RcodeNameError
+		for _, rr := range r.records {
+			if nsec3, ok := rr.(*dns.NSEC3); ok && nsec3.Cover(msg.Question[0].Name) {
+				msg.Ns = append(msg.Ns, rr)
+			}
+		}
+	}
+	return &query.Response{Msg: msg}, nil
+}
+
+// newNSECZone returns the records of example.com signed with NSEC, with the names in the order of the chain.
+func newNSECZone(names ...string) []dns.RR {
+	var records []dns.RR
+	for i, name := range names {
+		records = append(records, &dns.NSEC{
+			Hdr:        dns.RR_Header{Name: name, Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: 300},
+			NextDomain: names[(i+1)%len(names)],
+			TypeBitMap: []uint16{dns.TypeA, dns.TypeRRSIG, dns.TypeNSEC},
+		})
+	}
+	return records
+}
+
+// newNSEC3Zone returns the records of example.com signed with NSEC3, with 5 iterations and a salt.
+func newNSEC3Zone(names ...string) []dns.RR {
+	records := []dns.RR{&dns.NSEC3PARAM{
+		Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeNSEC3PARAM, Class: dns.ClassINET},
+		Hash:       dns.SHA1,
+		Iterations: 5,
+		SaltLength: 2,
+		Salt:       "aabb",
+	}}
+
+	var hashes []string
+	for _, name := range names {
+		hashes = append(hashes, dns.HashName(name, dns.SHA1, 5, "aabb"))
+	}
+	sort.Strings(hashes)
+	for i, hash := range hashes {
+		records = append(records, &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: strings.ToLower(hash) + ".example.com.", Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 300},
+			Hash:       dns.SHA1,
+			Iterations: 5,
+			SaltLength: 2,
+			Salt:       "aabb",
+			HashLength: 20,
+			NextDomain: hashes[(i+1)%len(hashes)],
+			TypeBitMap: []uint16{dns.TypeA, dns.TypeRRSIG},
+		})
+	}
+	return records
+}
+
+func TestWalk_NSEC(t *testing.T) {
+	r := &fakeResolver{records: newNSECZone("example.com.", "mail.example.com.", "www.example.com.")}
+
+	report, err := Walk(r, "example.com", Options{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, MethodNSEC, report.Method)
+	assert.True(t, report.Complete)
+	assert.Equal(t, []Name{
+		{Name: "example.com.", Types: []string{"A", "RRSIG", "NSEC"}},
+		{Name: "mail.example.com.", Types: []string{"A", "RRSIG", "NSEC"}},
+		{Name: "www.example.com.", Types: []string{"A", "RRSIG", "NSEC"}},
+	}, report.Names)
+	// The NSEC3PARAM query and one query per name.
+	assert.Equal(t, 4, report.Queries)
+}
+
+func TestWalk_NSEC_Incomplete(t *testing.T) {
+	// The chain leads out of the zone.
+	r := &fakeResolver{records: newNSECZone("example.com.", "mail.example.com.", "www.example.org.")}
+
+	report, err := Walk(r, "example.com", Options{})
+
+	assert.NoError(t, err)
+	assert.False(t, report.Complete)
+	assert.Len(t, report.Names, 2)
+
+	r = &fakeResolver{records: newNSECZone("example.com.", "a.example.com.", "b.example.com.", "c.example.com.")}
+
+	report, err = Walk(r, "example.com", Options{MaxQueries: 3})
+
+	assert.NoError(t, err)
+	assert.False(t, report.Complete)
+	assert.Len(t, report.Names, 2)
+	assert.Equal(t, 3, report.Queries)
+}
+
+func TestWalk_Unsigned(t *testing.T) {
+	_, err := Walk(&fakeResolver{}, "example.com", Options{})
+
+	assert.EqualError(t, err, "example.com. is not signed with NSEC or NSEC3")
+
+	_, err = Walk(&fakeResolver{err: errors.New("i/o timeout")}, "example.com", Options{})
+
+	assert.EqualError(t, err, "i/o timeout")
+}
+
+func TestWalk_NSEC3(t *testing.T) {
+	r := &fakeResolver{records: newNSEC3Zone("example.com.", "www.example.com.", "mail.example.com.", "secret.example.com.")}
+
+	report, err := Walk(r, "example.com", Options{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, MethodNSEC3, report.Method)
+	assert.Equal(t, &Params{Algorithm: dns.SHA1, Iterations: 5, Salt: "aabb"}, report.Params)
+	assert.True(t, report.Complete)
+	assert.Len(t, report.Hashes, 4)
+	assert.Equal(t, dns.HashName("www.example.com.", dns.SHA1, 5, "aabb"), report.Hashes[0].Hash)
+	assert.Equal(t, []string{"A", "RRSIG"}, report.Hashes[0].Types)
+
+	assert.Equal(t, 3, report.Crack([]string{"www", "mail", "ftp"}))
+	assert.Equal(t, 3, report.Cracked())
+	// Hashes are only cracked once.
+	assert.Equal(t, 0, report.Crack([]string{"www"}))
+
+	names := make(map[string]bool)
+	for _, h := range report.Hashes {
+		names[h.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"example.com.": true, "www.example.com.": true, "mail.example.com.": true, "": true}, names)
+}
+
+func TestWalk_NSEC3_Incomplete(t *testing.T) {
+	r := &fakeResolver{records: newNSEC3Zone("example.com.", "www.example.com.", "mail.example.com.", "secret.example.com.")}
+
+	report, err := Walk(r, "example.com", Options{MaxQueries: 2})
+
+	assert.NoError(t, err)
+	assert.False(t, report.Complete)
+	assert.Len(t, report.Hashes, 1)
+	assert.Equal(t, 2, report.Queries)
+}
+
+func TestReport_covers(t *testing.T) {
+	r := &Report{Hashes: []Hash{{Hash: "B", Next: "D"}, {Hash: "D", Next: "F"}, {Hash: "F", Next: "B"}}}
+
+	assert.True(t, r.covers("B"))
+	assert.True(t, r.covers("C"))
+	assert.True(t, r.covers("E"))
+	assert.True(t, r.covers("A"))
+	assert.True(t, r.covers("G"))
+	assert.True(t, r.closed())
+
+	r = &Report{Hashes: []Hash{{Hash: "B", Next: "D"}, {Hash: "F", Next: "B"}}}
+
+	assert.False(t, r.covers("E"))
+	assert.True(t, r.covers("A"))
+	assert.False(t, r.closed())
+}
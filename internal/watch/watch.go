@@ -0,0 +1,246 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/znscli/zns/internal/query"
+)
+
+const (
+	// MinInterval is the lowest interval between two iterations.
+	// It prevents zns from hammering the DNS server when records have a TTL of zero.
+	MinInterval = time.Second
+
+	// DefaultInterval is used when no interval was given and no records were found to derive one from.
+	DefaultInterval = 30 * time.Second
+)
+
+// ChangeType describes how a record changed between two iterations.
+type ChangeType int
+
+const (
+	Added ChangeType = iota
+	Removed
+	Changed
+)
+
+func (ct ChangeType) String() string {
+	switch ct {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change represents a single difference between two iterations.
+// Old is nil for added records, New is nil for removed records.
+type Change struct {
+	Type ChangeType
+	Old  dns.RR
+	New  dns.RR
+}
+
+// Record returns the most recent version of the record affected by the change.
+func (c Change) Record() dns.RR {
+	if c.New != nil {
+		return c.New
+	}
+	return c.Old
+}
+
+type rrsetKey struct {
+	name  string
+	rtype uint16
+}
+
+// Diff compares two sets of records and returns the differences between them.
+// Records are grouped into RRsets by name and type. If a single record of an RRset
+// was replaced by another one, the difference is reported as a change rather than
+// as a removal followed by an addition.
+func Diff(old, new []dns.RR) []Change {
+	oldSets := groupRecords(old)
+	newSets := groupRecords(new)
+
+	keys := make([]rrsetKey, 0, len(oldSets)+len(newSets))
+	for k := range oldSets {
+		keys = append(keys, k)
+	}
+	for k := range newSets {
+		if _, ok := oldSets[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	// Sort the RRsets by type and name, so the changes are reported in a consistent order.
+	sort.Slice(keys, func(i, j int) bool {
+		ti, tj := dns.TypeToString[keys[i].rtype], dns.TypeToString[keys[j].rtype]
+		if ti != tj {
+			return ti < tj
+		}
+		return keys[i].name < keys[j].name
+	})
+
+	var changes []Change
+	for _, k := range keys {
+		removed := subtract(oldSets[k], newSets[k])
+		added := subtract(newSets[k], oldSets[k])
+
+		if len(removed) == 1 && len(added) == 1 {
+			changes = append(changes, Change{Type: Changed, Old: removed[0], New: added[0]})
+			continue
+		}
+		for _, rr := range removed {
+			changes = append(changes, Change{Type: Removed, Old: rr})
+		}
+		for _, rr := range added {
+			changes = append(changes, Change{Type: Added, New: rr})
+		}
+	}
+
+	return changes
+}
+
+// groupRecords groups records into RRsets by lowercased name and type.
+func groupRecords(records []dns.RR) map[rrsetKey][]dns.RR {
+	sets := make(map[rrsetKey][]dns.RR)
+	for _, rr := range records {
+		k := rrsetKey{name: strings.ToLower(rr.Header().Name), rtype: rr.Header().Rrtype}
+		sets[k] = append(sets[k], rr)
+	}
+	return sets
+}
+
+// subtract returns the records of a whose record data is not present in b.
+// TTLs are deliberately not compared, as caching resolvers count them down between iterations.
+func subtract(a, b []dns.RR) []dns.RR {
+	seen := make(map[string]bool, len(b))
+	for _, rr := range b {
+		seen[query.Rdata(rr)] = true
+	}
+
+	var out []dns.RR
+	for _, rr := range a {
+		if !seen[query.Rdata(rr)] {
+			out = append(out, rr)
+		}
+	}
+	return out
+}
+
+// Interval derives the interval between two iterations from the lowest TTL of the given records.
+func Interval(records []dns.RR) time.Duration {
+	if len(records) == 0 {
+		return DefaultInterval
+	}
+
+	minTTL := records[0].Header().Ttl
+	for _, rr := range records[1:] {
+		if rr.Header().Ttl < minTTL {
+			minTTL = rr.Header().Ttl
+		}
+	}
+
+	interval := time.Duration(minTTL) * time.Second
+	if interval < MinInterval {
+		return MinInterval
+	}
+	return interval
+}
+
+// Run re-runs fetch periodically until ctx is done, and calls onChange whenever
+// the records differ from the previous iteration. The initial records are the
+// result of the first iteration, which the caller has already rendered.
+//
+// If interval is zero, the interval is derived from the lowest TTL seen in the
+// previous iteration. Errors returned by fetch are passed to onError and the
+// previous records are kept, so a transient failure does not show up as every
+// record being removed.
+func Run(ctx context.Context, interval time.Duration, initial []dns.RR, fetch func() ([]dns.RR, error), onChange func([]Change), onError func(error)) error {
+	current := initial
+
+	for {
+		wait := interval
+		if wait == 0 {
+			wait = Interval(current)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		records, err := fetch()
+		if err != nil {
+			onError(err)
+			continue
+		}
+
+		if changes := Diff(current, records); len(changes) > 0 {
+			onChange(changes)
+		}
+		current = records
+	}
+}
+
+// Exec runs the given command through the system shell after changes were detected.
+// The changes are written to the command's standard input, one per line, and summarized
+// in the ZNS_DOMAIN, ZNS_ADDED, ZNS_REMOVED and ZNS_CHANGED environment variables.
+func Exec(ctx context.Context, command string, domain string, changes []Change, stdout, stderr io.Writer) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	default:
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+
+	counts := make(map[ChangeType]int)
+	var stdin strings.Builder
+	for _, c := range changes {
+		counts[c.Type]++
+		fmt.Fprintln(&stdin, formatChange(c))
+	}
+
+	cmd.Env = append(os.Environ(),
+		"ZNS_DOMAIN="+domain,
+		"ZNS_ADDED="+strconv.Itoa(counts[Added]),
+		"ZNS_REMOVED="+strconv.Itoa(counts[Removed]),
+		"ZNS_CHANGED="+strconv.Itoa(counts[Changed]),
+	)
+	cmd.Stdin = strings.NewReader(stdin.String())
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error: watch hook failed: %v", err)
+	}
+	return nil
+}
+
+// formatChange formats a change as a single plain-text line, e.g. "changed A example.com. 192.0.2.1 -> 192.0.2.2".
+func formatChange(c Change) string {
+	rr := c.Record()
+	line := fmt.Sprintf("%s %s %s", c.Type, dns.TypeToString[rr.Header().Rrtype], rr.Header().Name)
+	if c.Type == Changed {
+		return fmt.Sprintf("%s %s -> %s", line, query.Rdata(c.Old), query.Rdata(c.New))
+	}
+	return fmt.Sprintf("%s %s", line, query.Rdata(rr))
+}
@@ -0,0 +1,156 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/znscli/zns/internal/query"
+)
+
+func newA(name string, ip string, ttl uint32) *dns.A {
+	return &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    ttl,
+		},
+		A: net.ParseIP(ip),
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		old := []dns.RR{newA("example.com.", "192.0.2.1", 60)}
+		new := []dns.RR{newA("example.com.", "192.0.2.1", 42)} // TTL changes are ignored
+
+		assert.Empty(t, Diff(old, new))
+	})
+
+	t.Run("added record", func(t *testing.T) {
+		old := []dns.RR{newA("example.com.", "192.0.2.1", 60)}
+		new := []dns.RR{newA("example.com.", "192.0.2.1", 60), newA("example.com.", "192.0.2.2", 60)}
+
+		changes := Diff(old, new)
+
+		assert.Len(t, changes, 1)
+		assert.Equal(t, Added, changes[0].Type)
+		assert.Nil(t, changes[0].Old)
+		assert.Equal(t, "192.0.2.2", query.Rdata(changes[0].New))
+	})
+
+	t.Run("removed record", func(t *testing.T) {
+		old := []dns.RR{newA("example.com.", "192.0.2.1", 60), newA("example.com.", "192.0.2.2", 60)}
+		new := []dns.RR{newA("example.com.", "192.0.2.1", 60)}
+
+		changes := Diff(old, new)
+
+		assert.Len(t, changes, 1)
+		assert.Equal(t, Removed, changes[0].Type)
+		assert.Nil(t, changes[0].New)
+		assert.Equal(t, "192.0.2.2", query.Rdata(changes[0].Old))
+	})
+
+	t.Run("changed record", func(t *testing.T) {
+		old := []dns.RR{newA("example.com.", "192.0.2.1", 60)}
+		new := []dns.RR{newA("example.com.", "192.0.2.2", 60)}
+
+		changes := Diff(old, new)
+
+		assert.Len(t, changes, 1)
+		assert.Equal(t, Changed, changes[0].Type)
+		assert.Equal(t, "192.0.2.1", query.Rdata(changes[0].Old))
+		assert.Equal(t, "192.0.2.2", query.Rdata(changes[0].New))
+	})
+
+	t.Run("replaced RRset", func(t *testing.T) {
+		old := []dns.RR{newA("example.com.", "192.0.2.1", 60), newA("example.com.", "192.0.2.2", 60)}
+		new := []dns.RR{newA("example.com.", "192.0.2.3", 60), newA("example.com.", "192.0.2.4", 60)}
+
+		changes := Diff(old, new)
+
+		assert.Len(t, changes, 4)
+		assert.Equal(t, Removed, changes[0].Type)
+		assert.Equal(t, Removed, changes[1].Type)
+		assert.Equal(t, Added, changes[2].Type)
+		assert.Equal(t, Added, changes[3].Type)
+	})
+}
+
+func TestInterval(t *testing.T) {
+	t.Run("lowest TTL", func(t *testing.T) {
+		records := []dns.RR{newA("example.com.", "192.0.2.1", 300), newA("example.com.", "192.0.2.2", 60)}
+		assert.Equal(t, 60*time.Second, Interval(records))
+	})
+
+	t.Run("zero TTL", func(t *testing.T) {
+		records := []dns.RR{newA("example.com.", "192.0.2.1", 0)}
+		assert.Equal(t, MinInterval, Interval(records))
+	})
+
+	t.Run("no records", func(t *testing.T) {
+		assert.Equal(t, DefaultInterval, Interval(nil))
+	})
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	iterations := [][]dns.RR{
+		{newA("example.com.", "192.0.2.1", 60)}, // unchanged
+		{newA("example.com.", "192.0.2.2", 60)}, // changed
+	}
+
+	var calls int
+	fetch := func() ([]dns.RR, error) {
+		if calls == len(iterations) {
+			cancel()
+			return nil, fmt.Errorf("it's always DNS")
+		}
+		records := iterations[calls]
+		calls++
+		return records, nil
+	}
+
+	var changes [][]Change
+	onChange := func(c []Change) {
+		changes = append(changes, c)
+	}
+
+	var errors []error
+	onError := func(err error) {
+		errors = append(errors, err)
+	}
+
+	initial := []dns.RR{newA("example.com.", "192.0.2.1", 60)}
+	err := Run(ctx, time.Millisecond, initial, fetch, onChange, onError)
+
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, Changed, changes[0][0].Type)
+	assert.Len(t, errors, 1)
+}
+
+func TestExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on a POSIX shell")
+	}
+
+	changes := []Change{
+		{Type: Changed, Old: newA("example.com.", "192.0.2.1", 60), New: newA("example.com.", "192.0.2.2", 60)},
+	}
+
+	var stdout bytes.Buffer
+	err := Exec(context.Background(), `echo "$ZNS_DOMAIN $ZNS_CHANGED"; cat`, "example.com", changes, &stdout, &stdout)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com 1\nchanged A example.com. 192.0.2.1 -> 192.0.2.2\n", stdout.String())
+}
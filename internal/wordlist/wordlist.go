@@ -0,0 +1,27 @@
+// Package wordlist reads the wordlists zns tries labels from, to discover the names of a zone or to crack the
+// NSEC3 hashes of its names.
+package wordlist
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Read reads the labels of a wordlist, one per line. Blank lines and lines starting with # are skipped,
+// and labels are only returned once, lower case, in the order they first appear.
+func Read(r io.Reader) ([]string, error) {
+	var words []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word == "" || strings.HasPrefix(word, "#") || seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
@@ -0,0 +1,15 @@
+package wordlist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	words, err := Read(strings.NewReader("www\n\n# comment\n  mail \nWWW\napi\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"www", "mail", "api"}, words)
+}